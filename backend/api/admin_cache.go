@@ -0,0 +1,122 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// cacheListSortFields are the ?sort= values of GetCacheListHandler and how they compare entries
+var cacheListSortFields = map[string]func(a, b models.CacheEntryInfo) int{
+	"createdAt": func(a, b models.CacheEntryInfo) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"title": func(a, b models.CacheEntryInfo) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	},
+	"videoId":          func(a, b models.CacheEntryInfo) int { return strings.Compare(a.VideoID, b.VideoID) },
+	"transcriptLength": func(a, b models.CacheEntryInfo) int { return cmp.Compare(a.TranscriptLength, b.TranscriptLength) },
+	"summaryLength":    func(a, b models.CacheEntryInfo) int { return cmp.Compare(a.SummaryLength, b.SummaryLength) },
+}
+
+// GetCacheListHandler lists the metadata of the cached summaries for admins, without their bodies.
+// Right after a start with lazy loading, files not indexed yet are left out and counted in unindexed.
+// ?sort= selects the field (createdAt by default) and ?order=asc|desc the direction (desc by default);
+// the list is paginated with ?offset= and ?limit=. Admin only.
+func GetCacheListHandler(c *gin.Context) {
+	sortField := c.DefaultQuery("sort", "createdAt")
+	compare, ok := cacheListSortFields[sortField]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgMustBeOneOf, "sort", "createdAt, title, videoId, transcriptLength, summaryLength")})
+		return
+	}
+	order := c.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgMustBeOneOf, "order", "asc, desc")})
+		return
+	}
+	offset, limit, err := parsePagination(c, defaultChannelPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	entries, unindexed := []models.CacheEntryInfo{}, 0
+	if summaryCache != nil {
+		entries, unindexed = summaryCache.List()
+	}
+	slices.SortStableFunc(entries, func(a, b models.CacheEntryInfo) int {
+		// The cache key breaks ties, so pages don't overlap between requests
+		result := cmp.Or(compare(a, b), strings.Compare(a.Key, b.Key))
+		if order == "desc" {
+			return -result
+		}
+		return result
+	})
+
+	total := len(entries)
+	page := entries[min(offset, total):min(offset+limit, total)]
+	c.JSON(http.StatusOK, gin.H{
+		"entries":   page,
+		"total":     total,
+		"unindexed": unindexed,
+		"offset":    offset,
+		"limit":     limit,
+		"sort":      sortField,
+		"order":     order,
+	})
+}
+
+// PinCacheItemHandler pins a cached summary (?language= for other languages), so it is never evicted or
+// expires. Admin only.
+func PinCacheItemHandler(c *gin.Context) {
+	setCacheItemPinned(c, true)
+}
+
+// UnpinCacheItemHandler lets a pinned summary be evicted and expire again. Admin only.
+func UnpinCacheItemHandler(c *gin.Context) {
+	setCacheItemPinned(c, false)
+}
+
+func setCacheItemPinned(c *gin.Context, pinned bool) {
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+	language, err := services.NormalizeLanguage(c.Query("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidLanguage), "language": c.Query("language")})
+		return
+	}
+	key := models.CacheKey(videoID, language)
+
+	found := false
+	if summaryCache != nil {
+		if pinned {
+			found, err = summaryCache.Pin(key)
+		} else {
+			found, err = summaryCache.Unpin(key)
+		}
+	}
+	if err != nil {
+		logError("setCacheItemPinned: Failed to update cached summary %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgCacheUpdateFailed), "videoId": videoID})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+
+	userInfo, _ := auth.GetSessionUser(c)
+	if userInfo != nil {
+		logInfo("Admin %s set pinned=%t on cached summary %s", userInfo.ID, pinned, key)
+	}
+	c.JSON(http.StatusOK, gin.H{"videoId": videoID, "key": key, "pinned": pinned})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCacheListHandler(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	transcript := []services.TranscriptItem{{Text: strings.Repeat("long transcript ", 1000)}}
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: "aaaaaaaaaaa", Title: "Old", Summary: "short", CreatedAt: now.Add(-2 * time.Minute)}))
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: "bbbbbbbbbbb", Title: "New", Summary: "a longer summary", Transcript: transcript, CreatedAt: now}))
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: "ccccccccccc", Title: "Middle", CreatedAt: now.Add(-time.Minute)}))
+
+	router := gin.New()
+	router.GET("/admin/cache", GetCacheListHandler)
+	list := func(query string) (int, []models.CacheEntryInfo, int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/cache"+query, nil))
+		var response struct {
+			Entries []models.CacheEntryInfo `json:"entries"`
+			Total   int                     `json:"total"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec.Code, response.Entries, response.Total, rec.Body.String()
+	}
+	videoIDs := func(entries []models.CacheEntryInfo) []string {
+		ids := []string{}
+		for _, entry := range entries {
+			ids = append(ids, entry.VideoID)
+		}
+		return ids
+	}
+
+	code, entries, total, body := list("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, total)
+	assert.Contains(t, body, `"unindexed":0`)
+	assert.Equal(t, []string{"bbbbbbbbbbb", "ccccccccccc", "aaaaaaaaaaa"}, videoIDs(entries), "newest first by default")
+	assert.Equal(t, 16000, entries[0].TranscriptLength)
+	assert.Equal(t, 16, entries[0].SummaryLength)
+	assert.NotContains(t, body, "long transcript", "bodies are not listed")
+
+	_, entries, _, _ = list("?sort=summaryLength&order=asc")
+	assert.Equal(t, []string{"ccccccccccc", "aaaaaaaaaaa", "bbbbbbbbbbb"}, videoIDs(entries))
+
+	_, entries, total, _ = list("?sort=title&order=asc&offset=1&limit=1")
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"bbbbbbbbbbb"}, videoIDs(entries))
+
+	_, entries, _, _ = list("?offset=5")
+	assert.Empty(t, entries)
+
+	for _, query := range []string{"?sort=summary", "?order=up", "?limit=0"} {
+		code, _, _, _ = list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestPinCacheItemHandler(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Language: "en", Summary: "summary"}))
+
+	router := gin.New()
+	router.PUT("/admin/cache/:videoId/pin", PinCacheItemHandler)
+	router.DELETE("/admin/cache/:videoId/pin", UnpinCacheItemHandler)
+	request := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := request(http.MethodPut, "/admin/cache/"+testVideoID+"/pin?language=en")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"videoId": "dQw4w9WgXcQ", "key": "dQw4w9WgXcQ.en", "pinned": true}`, rec.Body.String())
+	item, _ := summaryCache.Get(testVideoID + ".en")
+	assert.True(t, item.Pinned)
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPut, "/admin/cache/"+testVideoID+"/pin").Code, "no summary in the default language")
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, "/admin/cache/invalid/pin").Code)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, "/admin/cache/"+testVideoID+"/pin?language=en").Code)
+	item, _ = summaryCache.Get(testVideoID + ".en")
+	assert.False(t, item.Pinned)
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Per-key counter names in counterStore. Costs are kept in micro-USD so they fit the integer counters.
+const (
+	counterCostByDay    = "cost_microusd_by_day"
+	counterCostByUser   = "cost_microusd_by_user"
+	counterTokensByDay  = "tokens_by_day"
+	counterTokensByUser = "tokens_by_user"
+)
+
+// analyticsEvent is a line of the ANALYTICS_EVENTS_FILE event stream
+type analyticsEvent struct {
+	Type             string    `json:"type"`
+	Time             time.Time `json:"time"`
+	VideoID          string    `json:"videoId"`
+	UserID           string    `json:"userId"`
+	Language         string    `json:"language,omitempty"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	CostUSD          float64   `json:"costUsd"`
+	Priced           bool      `json:"priced"` // False if the model has no OPENAI_MODEL_PRICING entry
+	Partial          bool      `json:"partial,omitempty"`
+}
+
+// analyticsMutex keeps concurrent workers from interleaving event lines
+var analyticsMutex sync.Mutex
+
+// recordSummaryUsage adds the tokens and estimated cost of a generated summary to the per-day and
+// per-user counters and to the analytics event stream. Models without a price only count tokens.
+func recordSummaryUsage(job SummarizationJob, language, model string, usage services.TokenUsage, partial bool) {
+	cost, priced := services.EstimateCost(model, usage)
+	if !priced && usage.TotalTokens() > 0 {
+		logDebug("No OPENAI_MODEL_PRICING entry for model %q; recording tokens only", model)
+	}
+
+	now := time.Now().UTC()
+	if counterStore != nil {
+		day := now.Format("2006-01-02")
+		microUSD := int64(math.Round(cost * 1e6))
+		counterStore.IncrementKey(counterCostByDay, day, microUSD)
+		counterStore.IncrementKey(counterCostByUser, job.UserID, microUSD)
+		counterStore.IncrementKey(counterTokensByDay, day, int64(usage.TotalTokens()))
+		counterStore.IncrementKey(counterTokensByUser, job.UserID, int64(usage.TotalTokens()))
+	}
+	recordServerKeyUsage(job, usage, partial)
+
+	appendAnalyticsEvent(analyticsEvent{
+		Type:             "summary",
+		Time:             now,
+		VideoID:          job.VideoID,
+		UserID:           job.UserID,
+		Language:         language,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		CostUSD:          cost,
+		Priced:           priced,
+		Partial:          partial,
+	})
+}
+
+// appendAnalyticsEvent writes an event as a JSON line to ANALYTICS_EVENTS_FILE, if configured
+func appendAnalyticsEvent(event analyticsEvent) {
+	path := os.Getenv("ANALYTICS_EVENTS_FILE")
+	if path == "" {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		logWarn("Failed to encode analytics event: %v", err)
+		return
+	}
+
+	analyticsMutex.Lock()
+	defer analyticsMutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logWarn("Failed to create analytics event directory: %v", err)
+		return
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logWarn("Failed to open analytics event file: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		logWarn("Failed to write analytics event: %v", err)
+	}
+}
+
+// usageStat is the aggregated usage of a day or a user in the admin stats
+type usageStat struct {
+	Key     string  `json:"key"`
+	CostUSD float64 `json:"costUsd"`
+	Tokens  int64   `json:"tokens"`
+}
+
+// collectUsageStats merges the cost and token counters of the same keys
+func collectUsageStats(costCounter, tokenCounter string) []usageStat {
+	stats := make(map[string]*usageStat)
+	stat := func(key string) *usageStat {
+		if stats[key] == nil {
+			stats[key] = &usageStat{Key: key}
+		}
+		return stats[key]
+	}
+	for _, entry := range counterStore.TopN(costCounter, -1) {
+		stat(entry.Key).CostUSD = float64(entry.Count) / 1e6
+	}
+	for _, entry := range counterStore.TopN(tokenCounter, -1) {
+		stat(entry.Key).Tokens = entry.Count
+	}
+
+	result := make([]usageStat, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// HandleAdminStats returns the summary count and the estimated cost and tokens per day (newest first)
+// and per user (most expensive first). ?days and ?users limit the lists (default 30 and 20). Admin only.
+func HandleAdminStats(c *gin.Context) {
+	if counterStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": localize(c, i18n.MsgCountersUnavailable)})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgNonNegativeInteger, "days")})
+		return
+	}
+	users, err := strconv.Atoi(c.DefaultQuery("users", "20"))
+	if err != nil || users < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgNonNegativeInteger, "users")})
+		return
+	}
+
+	byDay := collectUsageStats(counterCostByDay, counterTokensByDay)
+	sort.Slice(byDay, func(i, j int) bool { return byDay[i].Key > byDay[j].Key })
+	if len(byDay) > days {
+		byDay = byDay[:days]
+	}
+
+	byUser := collectUsageStats(counterCostByUser, counterTokensByUser)
+	sort.Slice(byUser, func(i, j int) bool {
+		if byUser[i].CostUSD != byUser[j].CostUSD {
+			return byUser[i].CostUSD > byUser[j].CostUSD
+		}
+		if byUser[i].Tokens != byUser[j].Tokens {
+			return byUser[i].Tokens > byUser[j].Tokens
+		}
+		return byUser[i].Key < byUser[j].Key
+	})
+	if len(byUser) > users {
+		byUser = byUser[:users]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"summariesTotal": counterStore.Get(counterSummariesTotal),
+		"costByDay":      byDay,
+		"costByUser":     byUser,
+	})
+}
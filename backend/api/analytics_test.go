@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupCounters gives the test a fresh counter store
+func setupCounters(t *testing.T) {
+	t.Helper()
+	store, err := models.NewCounterStore(filepath.Join(t.TempDir(), "counters.json"), 0)
+	assert.NoError(t, err)
+	original := counterStore
+	counterStore = store
+	t.Cleanup(func() { counterStore = original })
+}
+
+func TestRecordSummaryUsageAggregatesCost(t *testing.T) {
+	setupCounters(t)
+	eventsFile := filepath.Join(t.TempDir(), "events.jsonl")
+	t.Setenv("ANALYTICS_EVENTS_FILE", eventsFile)
+	t.Setenv("OPENAI_MODEL_PRICING", "priced-model:1/2")
+
+	recordSummaryUsage(SummarizationJob{VideoID: testVideoID, UserID: "alice"}, "ko", "priced-model", services.TokenUsage{PromptTokens: 1000, CompletionTokens: 1000}, false)
+	recordSummaryUsage(SummarizationJob{VideoID: testVideoID, UserID: "bob"}, "en", "unpriced-model", services.TokenUsage{PromptTokens: 300, CompletionTokens: 200}, false)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/stats", HandleAdminStats)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stats struct {
+		CostByDay  []usageStat `json:"costByDay"`
+		CostByUser []usageStat `json:"costByUser"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	if assert.Len(t, stats.CostByDay, 1) {
+		assert.Equal(t, time.Now().UTC().Format("2006-01-02"), stats.CostByDay[0].Key)
+		assert.InDelta(t, 3.0, stats.CostByDay[0].CostUSD, 1e-9)
+		assert.Equal(t, int64(2500), stats.CostByDay[0].Tokens)
+	}
+	assert.Equal(t, []usageStat{{Key: "alice", CostUSD: 3, Tokens: 2000}, {Key: "bob", Tokens: 500}}, stats.CostByUser)
+
+	file, err := os.Open(eventsFile)
+	assert.NoError(t, err)
+	defer file.Close()
+	var events []analyticsEvent
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		var event analyticsEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	if assert.Len(t, events, 2) {
+		assert.True(t, events[0].Priced)
+		assert.False(t, events[1].Priced)
+		assert.Zero(t, events[1].CostUSD)
+		assert.Equal(t, 200, events[1].CompletionTokens)
+	}
+}
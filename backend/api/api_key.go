@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Sources an OpenAI API key can be resolved from
+const (
+	apiKeySourceHeader = "header" // Authorization header of the request
+	apiKeySourceStored = "stored" // Key the user saved via PUT /user/api-key
+	apiKeySourceServer = "server" // Server's OPENAI_API_KEY, subject to the server key policy
+	apiKeySourceNone   = "none"   // No usable key
+)
+
+// apiKeyPrecedence returns which user key wins when both a header key and a stored key exist.
+// API_KEY_PRECEDENCE accepts "header" (default) or "stored".
+func apiKeyPrecedence() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("API_KEY_PRECEDENCE")))
+	switch value {
+	case "", apiKeySourceHeader:
+		return apiKeySourceHeader
+	case apiKeySourceStored:
+		return apiKeySourceStored
+	default:
+		logWarn("Unknown API_KEY_PRECEDENCE %q, using %q", value, apiKeySourceHeader)
+		return apiKeySourceHeader
+	}
+}
+
+// resolveAPIKey picks the key used for a request and reports where it came from.
+// User keys are tried in API_KEY_PRECEDENCE order; the server key is the fallback when the policy allows it,
+// in which case the returned key is empty and the services package uses OPENAI_API_KEY.
+func resolveAPIKey(headerKey, userID string) (string, string) {
+	storedKey, _ := models.GetUserAPIKey(userID)
+
+	candidates := []struct{ key, source string }{
+		{headerKey, apiKeySourceHeader},
+		{storedKey, apiKeySourceStored},
+	}
+	if apiKeyPrecedence() == apiKeySourceStored {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	}
+
+	for _, candidate := range candidates {
+		if candidate.key != "" {
+			return candidate.key, candidate.source
+		}
+	}
+
+	if services.GetAPIKeyPolicy().CanUseServerKey(userID) {
+		return "", apiKeySourceServer
+	}
+	return "", apiKeySourceNone
+}
+
+// apiKeyRequiredResponse is the 403 body of requests without a usable key. When the user's server key
+// quota is used up, it says so and when the quota resets.
+func apiKeyRequiredResponse(c *gin.Context, userID string) gin.H {
+	response := gin.H{"error": localize(c, i18n.MsgAPIKeyRequired)}
+	if status, ok := services.GetAPIKeyPolicy().QuotaStatus(userID); ok && status.Exhausted() {
+		response["error"] = localize(c, i18n.MsgServerKeyQuotaExhausted)
+		response["serverKeyQuota"] = status
+	}
+	return response
+}
+
+// checkUserAPIKey rejects the request with 400 when the user's own key is refused by the provider.
+// The server key and keys that can't be checked right now are let through; the job surfaces any later failure.
+func checkUserAPIKey(ctx context.Context, c *gin.Context, apiKey, source string) bool {
+	if apiKey == "" || (source != apiKeySourceHeader && source != apiKeySourceStored) {
+		return true
+	}
+	err := services.ValidateAPIKey(ctx, apiKey)
+	if errors.Is(err, services.ErrInvalidAPIKey) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  localize(c, i18n.MsgInvalidAPIKey),
+			"source": source,
+		})
+		return false
+	}
+	if err != nil {
+		logWarn("Could not validate %s API key, continuing: %v", source, err)
+	}
+	return true
+}
+
+// ResolveAPIKeySource reports which key source the given request would use for a summary.
+func ResolveAPIKeySource(c *gin.Context, userID string) string {
+	_, source := resolveAPIKey(extractAPIKeyFromHeader(c), userID)
+	return source
+}
+
+// SaveUserAPIKeyRequest represents the body of a request storing the user's API key
+type SaveUserAPIKeyRequest struct {
+	APIKey string `json:"apiKey" binding:"required"`
+}
+
+// SaveUserAPIKeyHandler stores the authenticated user's OpenAI API key on the server
+func SaveUserAPIKeyHandler(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgNotAuthenticated)})
+		return
+	}
+
+	var request SaveUserAPIKeyRequest
+	if !bindJSONBody(c, &request) {
+		return
+	}
+
+	if err := models.SaveUserAPIKey(userInfo.ID, request.APIKey); err != nil {
+		logError("SaveUserAPIKeyHandler: UserID %s: %v", userInfo.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgAPIKeyStoreFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stored": true})
+}
+
+// APIKeyPolicyRequest is the body of PUT /admin/api-key-policy
+type APIKeyPolicyRequest struct {
+	Policy string   `json:"policy" binding:"required"` // "all" or "designated"
+	Users  []string `json:"users"`                     // Replaces the designated users; omitted keeps the current list
+}
+
+// apiKeyPolicyResponse reports the server key policy and its designated users
+func apiKeyPolicyResponse(policy *services.APIKeyPolicy) gin.H {
+	return gin.H{
+		"policy": policy.GetApiKeyPolicy(),
+		"users":  policy.DesignatedUserIDs(),
+	}
+}
+
+// GetAPIKeyPolicyHandler returns who may use the server's OpenAI API key (admin only)
+func GetAPIKeyPolicyHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, apiKeyPolicyResponse(services.GetAPIKeyPolicy()))
+}
+
+// UpdateAPIKeyPolicyHandler changes the server key policy and designated users at runtime (admin only).
+// The change is not persisted; SERVER_OPENAI_API_KEY_POLICY and DESIGNATED_USERS apply again after a restart.
+func UpdateAPIKeyPolicyHandler(c *gin.Context) {
+	var request APIKeyPolicyRequest
+	if !bindJSONBody(c, &request) {
+		return
+	}
+	if request.Policy != services.PolicyAllUsers && request.Policy != services.PolicyDesignatedUsers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidAPIKeyPolicy)})
+		return
+	}
+
+	policy := services.GetAPIKeyPolicy()
+	if request.Users != nil {
+		policy.UpdateDesignatedUsers(request.Users)
+	}
+	if err := policy.SetPolicy(request.Policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	adminID := ""
+	if userInfo, ok := auth.GetSessionUser(c); ok && userInfo != nil {
+		adminID = userInfo.ID
+	}
+	logInfo("UpdateAPIKeyPolicyHandler: Admin %s set the server key policy to %q with %d designated user(s).", adminID, request.Policy, len(policy.DesignatedUserIDs()))
+	c.JSON(http.StatusOK, apiKeyPolicyResponse(policy))
+}
+
+// DeleteUserAPIKeyHandler removes the authenticated user's stored OpenAI API key
+func DeleteUserAPIKeyHandler(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgNotAuthenticated)})
+		return
+	}
+
+	if err := models.DeleteUserAPIKey(userInfo.ID); err != nil {
+		logError("DeleteUserAPIKeyHandler: UserID %s: %v", userInfo.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgAPIKeyDeleteFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stored": false})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveAPIKeyPrecedence(t *testing.T) {
+	models.SetUserAPIKeyDirectory(t.TempDir())
+	t.Cleanup(func() { models.SetUserAPIKeyDirectory("users/keys") })
+
+	assert.NoError(t, models.SaveUserAPIKey("stored-user", "sk-stored"))
+
+	tests := []struct {
+		name       string
+		precedence string
+		headerKey  string
+		userID     string
+		wantKey    string
+		wantSource string
+	}{
+		{"header wins by default", "", "sk-header", "stored-user", "sk-header", apiKeySourceHeader},
+		{"stored wins when configured", "stored", "sk-header", "stored-user", "sk-stored", apiKeySourceStored},
+		{"stored used without header", "header", "", "stored-user", "sk-stored", apiKeySourceStored},
+		{"header used without stored key", "stored", "sk-header", "other-user", "sk-header", apiKeySourceHeader},
+		{"server key as fallback", "", "", "other-user", "", apiKeySourceServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("API_KEY_PRECEDENCE", tt.precedence)
+			key, source := resolveAPIKey(tt.headerKey, tt.userID)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantSource, source)
+		})
+	}
+}
+
+func TestUpdateAPIKeyPolicyHandler(t *testing.T) {
+	policy := services.GetAPIKeyPolicy()
+	originalPolicy, originalUsers := policy.GetApiKeyPolicy(), policy.DesignatedUserIDs()
+	t.Cleanup(func() {
+		policy.UpdateDesignatedUsers(originalUsers)
+		policy.SetPolicy(originalPolicy)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/api-key-policy", GetAPIKeyPolicyHandler)
+	router.PUT("/admin/api-key-policy", UpdateAPIKeyPolicyHandler)
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/api-key-policy", strings.NewReader(body)))
+		return w
+	}
+
+	w := put(`{"policy": "designated", "users": ["bob", " alice ", ""]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"policy": "designated", "users": ["alice", "bob"]}`, w.Body.String())
+	assert.True(t, policy.CanUseServerKey("alice"))
+	assert.False(t, policy.CanUseServerKey("carol"))
+
+	// Omitting users keeps the list; an unknown policy changes nothing
+	assert.Equal(t, http.StatusBadRequest, put(`{"policy": "nobody", "users": []}`).Code)
+	assert.Equal(t, http.StatusOK, put(`{"policy": "all"}`).Code)
+	assert.True(t, policy.CanUseServerKey("carol"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api-key-policy", nil))
+	assert.JSONEq(t, `{"policy": "all", "users": ["alice", "bob"]}`, w.Body.String())
+
+	// Bodies over MAX_REQUEST_BODY_BYTES are refused before the policy is touched
+	t.Setenv("MAX_REQUEST_BODY_BYTES", "64")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, put(`{"policy": "designated", "users": ["`+strings.Repeat("a", 128)+`"]}`).Code)
+	assert.Equal(t, services.PolicyAllUsers, policy.GetApiKeyPolicy())
+}
+
+func TestCheckUserAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_MODELS_URL", server.URL)
+
+	check := func(key, source string) (bool, int) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		ok := checkUserAPIKey(context.Background(), c, key, source)
+		return ok, w.Code
+	}
+
+	ok, code := check("sk-revoked", apiKeySourceHeader)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, code)
+	ok, _ = check("sk-valid", apiKeySourceStored)
+	assert.True(t, ok)
+	ok, _ = check("", apiKeySourceServer) // The server key is never checked
+	assert.True(t, ok)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// archiveMetadata is the metadata.json entry of a summary archive
+type archiveMetadata struct {
+	VideoID    string             `json:"videoId"`
+	URL        string             `json:"url"`
+	Title      string             `json:"title"`
+	Channel    string             `json:"channel,omitempty"`
+	ChannelID  string             `json:"channelId,omitempty"`
+	Duration   int                `json:"duration,omitempty"` // Seconds
+	Model      string             `json:"model,omitempty"`
+	Coverage   float64            `json:"coverage,omitempty"`
+	Partial    bool               `json:"partial,omitempty"`
+	Timestamps []models.Timestamp `json:"timestamps"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// HandleSummaryArchive streams a ZIP with everything cached for a video: the summary as Markdown,
+// the transcript as VTT and JSON, and a metadata JSON. ?transcript=vtt|json|both|none (default both)
+// selects the transcript formats. Videos without a cached summary return 404.
+func HandleSummaryArchive(c *gin.Context) {
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+
+	transcriptFormat := c.DefaultQuery("transcript", "both")
+	switch transcriptFormat {
+	case "vtt", "json", "both", "none":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgMustBeOneOf, "transcript", "vtt, json, both, none")})
+		return
+	}
+
+	if summaryCache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+	item, found := summaryCache.Get(videoID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, videoID))
+	c.Status(http.StatusOK)
+
+	// Entries are written straight to the response, so memory use doesn't grow with the archive
+	archive := zip.NewWriter(c.Writer)
+	entries := []struct {
+		name    string
+		include bool
+		write   func(io.Writer) error
+	}{
+		{"summary.md", true, func(w io.Writer) error { return writeSummaryMarkdown(w, item) }},
+		{"transcript.vtt", len(item.Transcript) > 0 && (transcriptFormat == "vtt" || transcriptFormat == "both"), func(w io.Writer) error { return writeString(w, services.TranscriptToVTT(item.Transcript)) }},
+		{"transcript.json", len(item.Transcript) > 0 && (transcriptFormat == "json" || transcriptFormat == "both"), func(w io.Writer) error { return writeJSON(w, item.Transcript) }},
+		{"metadata.json", true, func(w io.Writer) error { return writeJSON(w, newArchiveMetadata(item)) }},
+	}
+	for _, entry := range entries {
+		if !entry.include {
+			continue
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: item.CreatedAt})
+		if err == nil {
+			err = entry.write(w)
+		}
+		if err != nil {
+			// Headers are already sent; the truncated archive tells the client something went wrong
+			logError("HandleSummaryArchive: VideoID %s: Failed to write %s: %v", videoID, entry.name, err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		logError("HandleSummaryArchive: VideoID %s: Failed to finish archive: %v", videoID, err)
+	}
+}
+
+// newArchiveMetadata builds the metadata entry of a cached summary
+func newArchiveMetadata(item *models.CacheItem) archiveMetadata {
+	timestamps := item.Timestamps
+	if timestamps == nil {
+		timestamps = []models.Timestamp{}
+	}
+	return archiveMetadata{
+		VideoID:    item.VideoID,
+		URL:        services.CanonicalVideoURL(item.VideoID),
+		Title:      item.Title,
+		Channel:    item.Channel,
+		ChannelID:  item.ChannelID,
+		Duration:   item.Duration,
+		Model:      item.Model,
+		Coverage:   item.Coverage,
+		Partial:    item.Partial,
+		Timestamps: timestamps,
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+// writeSummaryMarkdown renders a cached summary as a Markdown document
+func writeSummaryMarkdown(w io.Writer, item *models.CacheItem) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", item.Title)
+	if item.Channel != "" {
+		fmt.Fprintf(&b, "- Channel: %s\n", item.Channel)
+	}
+	fmt.Fprintf(&b, "- Video: %s\n", services.CanonicalVideoURL(item.VideoID))
+	if item.Partial {
+		b.WriteString("- Note: this summary is incomplete\n")
+	}
+	fmt.Fprintf(&b, "\n## Summary\n\n%s\n", strings.TrimSpace(item.Summary))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeString writes s to w
+func writeString(w io.Writer, s string) error {
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveArchive(t *testing.T, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/summary/:videoId/archive", HandleSummaryArchive)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandleSummaryArchive(t *testing.T) {
+	setupWorkerTest(t)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+		VideoID:    testVideoID,
+		Title:      "Archive title",
+		Summary:    "[00:01] Opening",
+		Channel:    "Channel",
+		Duration:   212,
+		Model:      "gpt-4.1-nano",
+		Transcript: []services.TranscriptItem{{Text: "hello", Start: 1.5, Duration: 2}},
+	}))
+
+	rec := serveArchive(t, "/api/summary/"+testVideoID+"/archive")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+
+	assert.Contains(t, files["summary.md"], "# Archive title")
+	assert.Contains(t, files["summary.md"], "[00:01] Opening")
+	assert.Contains(t, files["transcript.vtt"], "00:00:01.500 --> 00:00:03.500\nhello")
+	assert.Contains(t, files["transcript.json"], `"text": "hello"`)
+	assert.Contains(t, files["metadata.json"], `"model": "gpt-4.1-nano"`)
+	assert.Contains(t, files["metadata.json"], `"duration": 212`)
+}
+
+func TestHandleSummaryArchiveTranscriptFormat(t *testing.T) {
+	setupWorkerTest(t)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+		VideoID:    testVideoID,
+		Title:      "Archive title",
+		Transcript: []services.TranscriptItem{{Text: "hello", Start: 0, Duration: 1}},
+	}))
+
+	rec := serveArchive(t, "/api/summary/"+testVideoID+"/archive?transcript=vtt")
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"summary.md", "transcript.vtt", "metadata.json"}, names)
+
+	assert.Equal(t, http.StatusBadRequest, serveArchive(t, "/api/summary/"+testVideoID+"/archive?transcript=srt").Code)
+}
+
+func TestHandleSummaryArchiveNotCached(t *testing.T) {
+	setupWorkerTest(t)
+	assert.Equal(t, http.StatusNotFound, serveArchive(t, "/api/summary/"+testVideoID+"/archive").Code)
+}
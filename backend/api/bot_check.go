@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// botCheckErrorCode is the error of a summary_error for a video YouTube kept refusing with a bot check
+const botCheckErrorCode = "bot_check"
+
+const (
+	defaultBotCheckRetries           = 2
+	defaultBotCheckRetryDelaySeconds = 10
+)
+
+// botCheckRetries returns how often a yt-dlp call refused with a bot check is retried, configured via
+// YTDLP_BOT_CHECK_RETRIES
+func botCheckRetries() int {
+	return max(services.GetEnvInt("YTDLP_BOT_CHECK_RETRIES", defaultBotCheckRetries), 0)
+}
+
+// botCheckRetryDelay returns the wait before the first retry, configured via
+// YTDLP_BOT_CHECK_RETRY_DELAY_SECONDS. It doubles with every further retry.
+func botCheckRetryDelay() time.Duration {
+	return time.Duration(max(services.GetEnvInt("YTDLP_BOT_CHECK_RETRY_DELAY_SECONDS", defaultBotCheckRetryDelaySeconds), 0)) * time.Second
+}
+
+// retryOnBotCheck calls fetch until it succeeds, fails with an error other than services.ErrBotCheck or the
+// retries are used up. YouTube's bot checks and rate limits usually pass after a while, so failing the job
+// right away would only make users retry by hand.
+func retryOnBotCheck(ctx context.Context, job SummarizationJob, fetch func() error) error {
+	retries, delay := botCheckRetries(), botCheckRetryDelay()
+	for attempt := 0; ; attempt++ {
+		err := fetch()
+		if !errors.Is(err, services.ErrBotCheck) || attempt >= retries {
+			return err
+		}
+		logWarn("Worker: VideoID %s, UserID %s: YouTube asked for a bot check, retrying in %s (%d/%d).", job.VideoID, job.UserID, delay, attempt+1, retries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryOnBotCheck(t *testing.T) {
+	t.Setenv("YTDLP_BOT_CHECK_RETRIES", "2")
+	t.Setenv("YTDLP_BOT_CHECK_RETRY_DELAY_SECONDS", "0")
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1"}
+	botCheck := fmt.Errorf("%w: exit status 1", services.ErrBotCheck)
+
+	calls := 0
+	err := retryOnBotCheck(context.Background(), job, func() error {
+		calls++
+		if calls < 3 {
+			return botCheck
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "passes on the last retry")
+
+	calls = 0
+	err = retryOnBotCheck(context.Background(), job, func() error {
+		calls++
+		return botCheck
+	})
+	assert.ErrorIs(t, err, services.ErrBotCheck)
+	assert.Equal(t, 3, calls, "gives up after the retries")
+
+	calls = 0
+	err = retryOnBotCheck(context.Background(), job, func() error {
+		calls++
+		return errors.New("video unavailable")
+	})
+	assert.EqualError(t, err, "video unavailable")
+	assert.Equal(t, 1, calls, "other errors are not retried")
+}
+
+func TestRetryOnBotCheckStopsWithContext(t *testing.T) {
+	t.Setenv("YTDLP_BOT_CHECK_RETRY_DELAY_SECONDS", "60")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := retryOnBotCheck(ctx, SummarizationJob{VideoID: testVideoID}, func() error { return services.ErrBotCheck })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestJobErrorPayloadForBotCheck(t *testing.T) {
+	err := &jobStageError{stage: jobStageTranscript, err: fmt.Errorf("failed to get transcript: %w", services.ErrBotCheck)}
+	payload := jobErrorPayload(testVideoID, err, i18n.English)
+	assert.Equal(t, botCheckErrorCode, payload["error"])
+	assert.Equal(t, i18n.T(i18n.MsgBotCheck, i18n.English), payload["message"])
+	assert.Equal(t, jobStageTranscript, payload["code"])
+}
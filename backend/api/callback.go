@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+const (
+	defaultCallbackMaxRetries = 3
+	callbackRequestTimeout    = 10 * time.Second
+	callbackInitialBackoff    = time.Second
+)
+
+// jobCallback is a callback URL registered by a subscriber of a summarization job
+type jobCallback struct {
+	UserID string
+	URL    string
+}
+
+// Callback URLs registered per active video job (job key -> callbacks).
+// Guarded by activeVideoJobsMutex, like activeVideoJobs itself.
+var jobCallbacks = make(map[string][]jobCallback)
+
+// errPrivateCallbackAddress is returned when a callback host resolves to an internal address
+var errPrivateCallbackAddress error = i18n.NewError(i18n.MsgCallbackHostPrivate)
+
+// allowedCallbackHosts returns the lower-cased host names listed in ALLOWED_CALLBACK_HOSTS
+func allowedCallbackHosts() map[string]bool {
+	hosts := make(map[string]bool)
+	for _, host := range strings.Split(os.Getenv("ALLOWED_CALLBACK_HOSTS"), ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			hosts[host] = true
+		}
+	}
+	return hosts
+}
+
+// isPublicIP reports whether ip is a routable, non-internal address
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// validateCallbackURL checks that a callback URL uses http(s), targets a host on the
+// ALLOWED_CALLBACK_HOSTS allow-list and does not resolve to a private address.
+func validateCallbackURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return i18n.NewError(i18n.MsgCallbackURLMalformed, err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return i18n.NewError(i18n.MsgCallbackURLScheme)
+	}
+	if parsed.User != nil {
+		return i18n.NewError(i18n.MsgCallbackURLCredentials)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	allowed := allowedCallbackHosts()
+	if len(allowed) == 0 {
+		return i18n.NewError(i18n.MsgCallbacksDisabled)
+	}
+	if !allowed[host] {
+		return i18n.NewError(i18n.MsgCallbackHostNotAllowed, host)
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return i18n.NewError(i18n.MsgCallbackHostUnresolved, host, err)
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return errPrivateCallbackAddress
+		}
+	}
+	return nil
+}
+
+// callbackHTTPClient refuses to connect to internal addresses at dial time, so a host that
+// re-resolves to a private IP after validation (DNS rebinding) still can't be reached.
+var callbackHTTPClient = &http.Client{
+	Timeout: callbackRequestTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: callbackRequestTimeout,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+					return errPrivateCallbackAddress
+				}
+				return nil
+			},
+		}).DialContext,
+	},
+	// Redirects could point anywhere; callbacks must hit the validated URL only.
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// registerJobCallback records a callback URL for a video job.
+// The caller must hold activeVideoJobsMutex.
+func registerJobCallback(key, userID, callbackURL string) {
+	if callbackURL == "" {
+		return
+	}
+	for _, cb := range jobCallbacks[key] {
+		if cb.UserID == userID && cb.URL == callbackURL {
+			return
+		}
+	}
+	jobCallbacks[key] = append(jobCallbacks[key], jobCallback{UserID: userID, URL: callbackURL})
+}
+
+// deliverCallbacks POSTs the result of a job to every registered callback in the background.
+func deliverCallbacks(callbacks []jobCallback, videoID string, summaryResp *SummaryResponse, jobErr error) {
+	if len(callbacks) == 0 {
+		return
+	}
+
+	event := "summary_complete"
+	var payload interface{} = summaryResp
+	if jobErr != nil || summaryResp == nil {
+		event = "summary_error"
+		if jobErr == nil {
+			jobErr = i18n.NewError(i18n.MsgSummarizationFailed)
+		}
+		// Callback endpoints are servers, not users, so they get the default language
+		payload = jobErrorPayload(videoID, jobErr, i18n.DefaultLanguage)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logError("Failed to marshal callback payload for VideoID %s: %v", videoID, err)
+		return
+	}
+
+	for _, cb := range callbacks {
+		go postCallback(cb, event, body)
+	}
+}
+
+// postCallback sends one signed callback, retrying with exponential backoff on failures.
+func postCallback(cb jobCallback, event string, body []byte) {
+	maxRetries := services.GetEnvInt("CALLBACK_MAX_RETRIES", defaultCallbackMaxRetries)
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	signature := signCallbackBody(body)
+
+	backoff := callbackInitialBackoff
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		retryable, err := sendCallback(cb.URL, event, signature, body)
+		if err == nil {
+			logInfo("Delivered %s callback to %s for UserID %s.", event, cb.URL, cb.UserID)
+			return
+		}
+		logWarn("Callback attempt %d to %s failed: %v", attempt+1, cb.URL, err)
+		if !retryable {
+			return
+		}
+	}
+	logError("Giving up on %s callback to %s for UserID %s after %d attempt(s).", event, cb.URL, cb.UserID, maxRetries+1)
+}
+
+// sendCallback performs a single callback request. It reports whether a failure is worth retrying.
+func sendCallback(callbackURL, event, signature string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callbackRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Callback-Event", event)
+	if signature != "" {
+		req.Header.Set("X-Signature-256", signature)
+	}
+
+	resp, err := callbackHTTPClient.Do(req)
+	if err != nil {
+		return !errors.Is(err, errPrivateCallbackAddress), err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("callback endpoint responded with status %d", resp.StatusCode)
+}
+
+// signCallbackBody returns the "sha256=<hex>" HMAC of the body using CALLBACK_SIGNING_SECRET,
+// or an empty string when no secret is configured.
+func signCallbackBody(body []byte) string {
+	secret := os.Getenv("CALLBACK_SIGNING_SECRET")
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCaptionsRatePerMinute limits how often a user may list caption tracks, since each lookup may spawn yt-dlp
+const defaultCaptionsRatePerMinute = 10
+
+// captionsLimiter rate-limits GET /api/captions per user
+var captionsLimiter = newRateLimiter(defaultCaptionsRatePerMinute, time.Minute)
+
+// initCaptionsLimiter applies CAPTIONS_RATE_LIMIT_PER_MINUTE (0 disables the limit)
+func initCaptionsLimiter() {
+	captionsLimiter = newRateLimiter(services.GetEnvInt("CAPTIONS_RATE_LIMIT_PER_MINUTE", defaultCaptionsRatePerMinute), time.Minute)
+}
+
+// HandleListCaptions returns the manual and automatic caption languages available for a video
+// so a client can let the user choose which one to summarize from.
+func HandleListCaptions(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgNotAuthenticated)})
+		return
+	}
+
+	videoURL := strings.TrimSpace(c.Query("url"))
+	if videoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgURLRequired)})
+		return
+	}
+
+	videoID, err := services.GetVideoID(videoURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, videoURLErrorResponse(err, i18n.RequestLanguage(c.Request)))
+		return
+	}
+
+	if !captionsLimiter.allow(userInfo.ID) {
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": localize(c, i18n.MsgTooManyCaptionLookups)})
+		return
+	}
+
+	videoInfo, err := services.GetVideoInfoCached(requestContext(c), videoID)
+	if err != nil {
+		logError("HandleListCaptions: VideoID %s: %v", videoID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": localize(c, i18n.MsgCaptionListFailed), "videoId": videoID})
+		return
+	}
+
+	captions := videoInfo.Captions
+	if captions == nil {
+		captions = []services.CaptionTrack{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"videoId":  videoID,
+		"captions": captions,
+	})
+}
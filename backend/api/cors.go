@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowHeaders = "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With"
+	corsAllowMethods = "POST, OPTIONS, GET, PUT, DELETE"
+)
+
+// allowedCORSOrigins returns the origins listed in CORS_ALLOWED_ORIGINS, without trailing slashes
+func allowedCORSOrigins() map[string]bool {
+	origins := make(map[string]bool)
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			origins[strings.ToLower(origin)] = true
+		}
+	}
+	return origins
+}
+
+// CORSMiddleware lets pages on the origins listed in CORS_ALLOWED_ORIGINS call the API with credentials.
+// The request's Origin is echoed back only if it is on the list, since browsers reject a wildcard origin
+// for credentialed requests; other origins get no CORS headers. CORS_ALLOW_ALL_ORIGINS=true allows every
+// origin for local development. Same-origin requests, such as those of the bundled frontend, need neither.
+func CORSMiddleware() gin.HandlerFunc {
+	origins := allowedCORSOrigins()
+	allowAll := services.GetEnvBool("CORS_ALLOW_ALL_ORIGINS", false)
+	if allowAll {
+		logWarn("CORS_ALLOW_ALL_ORIGINS is enabled: any website may call the API with its visitors' sessions")
+	}
+
+	return func(c *gin.Context) {
+		// Responses differ by Origin, so caches must not share them between origins
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		if origin != "" && (allowAll || origins[strings.ToLower(origin)]) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			c.Writer.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
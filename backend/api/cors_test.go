@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware())
+	router.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSMiddlewareAllowlist(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, http://localhost:3000/")
+	t.Setenv("CORS_ALLOW_ALL_ORIGINS", "false")
+	router := newCORSTestRouter()
+
+	for _, origin := range []string{"https://app.example.com", "http://localhost:3000", "HTTPS://APP.EXAMPLE.COM"} {
+		rec := corsRequest(router, http.MethodGet, origin)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, origin, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"), origin)
+		assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+	}
+
+	for _, origin := range []string{"https://evil.example.com", "https://app.example.com.evil.com", "null", ""} {
+		rec := corsRequest(router, http.MethodGet, origin)
+		assert.Equal(t, http.StatusOK, rec.Code, "disallowed origins are left to the browser's same-origin policy")
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+
+	// Preflight requests are answered without reaching the handlers
+	rec := corsRequest(router, http.MethodOptions, "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "DELETE")
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "X-Requested-With")
+
+	rec = corsRequest(router, http.MethodOptions, "https://evil.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORSMiddlewareAllowAllOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	t.Setenv("CORS_ALLOW_ALL_ORIGINS", "true")
+	router := newCORSTestRouter()
+
+	rec := corsRequest(router, http.MethodGet, "http://localhost:5173")
+	assert.Equal(t, "http://localhost:5173", rec.Header().Get("Access-Control-Allow-Origin"), "the origin is echoed instead of *")
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	rec = corsRequest(router, http.MethodGet, "")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddlewareWithoutAllowlist(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	t.Setenv("CORS_ALLOW_ALL_ORIGINS", "")
+	router := newCORSTestRouter()
+
+	rec := corsRequest(router, http.MethodGet, "https://app.example.com")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
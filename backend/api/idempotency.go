@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks responses that were answered from an earlier request with the same key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength keeps clients from using the map as free storage
+	maxIdempotencyKeyLength = 128
+	// maxIdempotentResponses bounds the memory used by remembered responses
+	maxIdempotentResponses       = 10000
+	defaultIdempotencyTTLSeconds = 600
+)
+
+// idempotentResponse is the response to the first request sent with an idempotency key.
+// done is closed once the response is complete; the other fields must not be read before that.
+type idempotentResponse struct {
+	done        chan struct{}
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+var (
+	idempotentResponsesMutex sync.Mutex
+	// idempotentResponses holds the responses by user ID, route and idempotency key
+	idempotentResponses = make(map[string]*idempotentResponse)
+)
+
+// idempotencyTTL returns how long responses are remembered, configured via IDEMPOTENCY_KEY_TTL_SECONDS.
+// A value of 0 disables idempotency keys.
+func idempotencyTTL() time.Duration {
+	seconds := services.GetEnvInt("IDEMPOTENCY_KEY_TTL_SECONDS", defaultIdempotencyTTLSeconds)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// withIdempotencyKey runs handler unless the user already sent a request with the same Idempotency-Key
+// header to the route. Repeated requests get the response of the first one, waiting for it if it is still
+// being handled, so a double-clicked or retried request doesn't start a second summary. Only successful
+// responses are remembered; after an error the request can be retried with the same key.
+func withIdempotencyKey(c *gin.Context, handler gin.HandlerFunc) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	ttl := idempotencyTTL()
+	if key == "" || ttl == 0 {
+		handler(c)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgIdempotencyKeyTooLong, idempotencyKeyHeader, maxIdempotencyKeyLength)})
+		return
+	}
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		handler(c) // Rejected by the handler
+		return
+	}
+
+	storeKey := userInfo.ID + "\x00" + c.FullPath() + "\x00" + key
+	response, first := startIdempotentRequest(storeKey, time.Now())
+	if !first {
+		select {
+		case <-response.done:
+		case <-c.Request.Context().Done():
+			return
+		}
+		logInfo("Replaying the response for Idempotency-Key %q of UserID %s.", key, userInfo.ID)
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(response.status, response.contentType, response.body)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	defer func() {
+		c.Writer = recorder.ResponseWriter
+		finishIdempotentRequest(storeKey, response, recorder, ttl)
+	}()
+	handler(c)
+}
+
+// startIdempotentRequest returns the response remembered for storeKey. If there is none, an empty one is
+// registered and true is returned: the caller must handle the request and finish the response.
+func startIdempotentRequest(storeKey string, now time.Time) (*idempotentResponse, bool) {
+	idempotentResponsesMutex.Lock()
+	defer idempotentResponsesMutex.Unlock()
+
+	if response, ok := idempotentResponses[storeKey]; ok {
+		select {
+		case <-response.done:
+			if now.Before(response.expires) {
+				return response, false
+			}
+		default:
+			return response, false // Still being handled
+		}
+	}
+
+	if len(idempotentResponses) >= maxIdempotentResponses {
+		dropExpiredIdempotentResponsesLocked(now)
+	}
+	response := &idempotentResponse{done: make(chan struct{})}
+	if len(idempotentResponses) < maxIdempotentResponses {
+		idempotentResponses[storeKey] = response
+	}
+	return response, true
+}
+
+// finishIdempotentRequest completes the response and wakes up requests waiting for it.
+// Unsuccessful responses are forgotten so that the request can be retried.
+func finishIdempotentRequest(storeKey string, response *idempotentResponse, recorder *responseRecorder, ttl time.Duration) {
+	idempotentResponsesMutex.Lock()
+	defer idempotentResponsesMutex.Unlock()
+
+	response.status = recorder.Status()
+	response.contentType = recorder.Header().Get("Content-Type")
+	response.body = recorder.body.Bytes()
+	response.expires = time.Now().Add(ttl)
+	close(response.done)
+
+	if response.status >= http.StatusBadRequest && idempotentResponses[storeKey] == response {
+		delete(idempotentResponses, storeKey)
+	}
+}
+
+// dropExpiredIdempotentResponsesLocked removes expired responses. Callers must hold idempotentResponsesMutex.
+func dropExpiredIdempotentResponsesLocked(now time.Time) {
+	for storeKey, response := range idempotentResponses {
+		select {
+		case <-response.done:
+			if !now.Before(response.expires) {
+				delete(idempotentResponses, storeKey)
+			}
+		default:
+		}
+	}
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSummaryRequestIdempotencyKey(t *testing.T) {
+	router, queue := setupSummaryRequestTest(t, "user1")
+	t.Cleanup(func() {
+		idempotentResponsesMutex.Lock()
+		idempotentResponses = make(map[string]*idempotentResponse)
+		idempotentResponsesMutex.Unlock()
+	})
+	withKey := func(key string) http.Header {
+		return http.Header{idempotencyKeyHeader: {key}}
+	}
+
+	first := postSummary(router, "user1", withKey("click-1"))
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.Empty(t, first.Header().Get(idempotentReplayedHeader))
+	assert.Len(t, queue, 1)
+
+	// The retry gets the first response instead of "already in progress"
+	retry := postSummary(router, "user1", withKey("click-1"))
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+
+	other := postSummary(router, "user1", withKey("click-2"))
+	assert.Empty(t, other.Header().Get(idempotentReplayedHeader))
+	assert.Contains(t, other.Body.String(), "already in progress")
+	assert.Len(t, queue, 1)
+
+	// Errors aren't remembered, so the request can be retried with the same key
+	activeVideoJobsMutex.Lock()
+	delete(activeVideoJobs, SummarizationJob{VideoID: testVideoID}.key())
+	activeVideoJobsMutex.Unlock()
+	<-queue
+	jobQueue = make(chan SummarizationJob) // Full
+	busy := postSummary(router, "user1", withKey("click-3"))
+	assert.Equal(t, http.StatusServiceUnavailable, busy.Code)
+	jobQueue = queue
+	retry = postSummary(router, "user1", withKey("click-3"))
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Empty(t, retry.Header().Get(idempotentReplayedHeader))
+	assert.Len(t, queue, 1)
+}
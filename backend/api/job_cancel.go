@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// defaultAbandonGraceSeconds is how long a disconnected user may take to reconnect before their jobs are dropped
+const defaultAbandonGraceSeconds = 30
+
+// errJobAbandoned is the cancellation cause of a running job whose only subscriber disconnected
+var errJobAbandoned = errors.New("all subscribers disconnected")
+
+// runningJob is a job a worker is processing, together with the cancellation of its context
+type runningJob struct {
+	job    SummarizationJob
+	cancel context.CancelCauseFunc
+}
+
+// abandonedJob identifies one queued copy of a job by its key and the time it was queued
+type abandonedJob struct {
+	key        string
+	enqueuedAt time.Time
+}
+
+var (
+	// runningJobs holds the jobs workers are processing by job key. Guarded by activeVideoJobsMutex.
+	runningJobs = make(map[string]*runningJob)
+
+	// abandonedJobs holds queued jobs nobody waits for anymore, so workers skip them when they come up.
+	// Guarded by activeVideoJobsMutex.
+	abandonedJobs = make(map[abandonedJob]struct{})
+)
+
+// abandonJobsAfterDisconnect drops the jobs of a user whose SSE connection closed, once they stayed
+// disconnected for ABANDONED_JOB_GRACE_SECONDS, so a reloading page doesn't lose its summary.
+// Disabled with CANCEL_ABANDONED_JOBS=false.
+func abandonJobsAfterDisconnect(userID string) {
+	if !services.GetEnvBool("CANCEL_ABANDONED_JOBS", true) {
+		return
+	}
+	grace := services.GetEnvInt("ABANDONED_JOB_GRACE_SECONDS", defaultAbandonGraceSeconds)
+	if grace <= 0 {
+		abandonJobsOf(userID)
+		return
+	}
+	time.AfterFunc(time.Duration(grace)*time.Second, func() {
+		clientChannelsMutex.RLock()
+		_, reconnected := clientChannels[userID]
+		clientChannelsMutex.RUnlock()
+		if !reconnected {
+			abandonJobsOf(userID)
+		}
+	})
+}
+
+// abandonJobsOf drops the jobs userID is the only subscriber of and that have no callback to deliver.
+// Queued jobs are skipped by the workers and running ones are cancelled, except for jobs reprocessing
+// a cached summary. Jobs with other subscribers keep running so those still get their result.
+func abandonJobsOf(userID string) []string {
+	activeVideoJobsMutex.Lock()
+	var abandoned []string
+	for key, subscribers := range activeVideoJobs {
+		if len(subscribers) != 1 || subscribers[0] != userID || len(jobCallbacks[key]) > 0 {
+			continue
+		}
+		if abandonJobLocked(key) {
+			abandoned = append(abandoned, key)
+		}
+	}
+	activeVideoJobsMutex.Unlock()
+
+	for _, key := range abandoned {
+		unjournalJob(key)
+		logInfo("Abandoned job %s: its only subscriber %s disconnected.", key, userID)
+	}
+	return abandoned
+}
+
+// abandonJobLocked unregisters a job nobody waits for anymore: a queued job is skipped by the workers and
+// a running one is cancelled. Jobs reprocessing a cached summary are kept; false is returned for those.
+// The caller must hold activeVideoJobsMutex and remove the job from the journal.
+func abandonJobLocked(key string) bool {
+	running, isRunning := runningJobs[key]
+	if isRunning && running.job.Reprocess {
+		return false
+	}
+
+	delete(activeVideoJobs, key)
+	delete(jobCallbacks, key)
+	releaseServerKeySummary(key)
+	if enqueuedAt, queued := queuedJobs[key]; queued {
+		delete(queuedJobs, key)
+		abandonedJobs[abandonedJob{key: key, enqueuedAt: enqueuedAt}] = struct{}{}
+	}
+	if isRunning {
+		running.cancel(errJobAbandoned)
+	}
+	return true
+}
+
+// unsubscribeFromVideo removes userID, and the callbacks they registered, from the jobs for a video in any
+// language or style, because the user cancelled the summary. Jobs nobody else waits for are abandoned as
+// after a disconnect. It returns the keys of the jobs the user was removed from.
+func unsubscribeFromVideo(userID, videoID string) []string {
+	activeVideoJobsMutex.Lock()
+	var left, abandoned []string
+	for key, subscribers := range activeVideoJobs {
+		if !isJobKeyOf(key, videoID) || !slices.Contains(subscribers, userID) {
+			continue
+		}
+		left = append(left, key)
+
+		// New slices: the journal may still refer to the old ones
+		remaining := slices.DeleteFunc(slices.Clone(subscribers), func(id string) bool { return id == userID })
+		callbacks := slices.DeleteFunc(slices.Clone(jobCallbacks[key]), func(cb jobCallback) bool { return cb.UserID == userID })
+		if len(remaining) == 0 && len(callbacks) == 0 && abandonJobLocked(key) {
+			abandoned = append(abandoned, key)
+			continue
+		}
+		activeVideoJobs[key] = remaining // Empty when only a callback is left to deliver
+		if len(callbacks) > 0 {
+			jobCallbacks[key] = callbacks
+		} else {
+			delete(jobCallbacks, key)
+		}
+		rejournalJobLocked(key)
+	}
+	activeVideoJobsMutex.Unlock()
+
+	for _, key := range abandoned {
+		unjournalJob(key)
+		logInfo("Abandoned job %s: its only subscriber %s cancelled it.", key, userID)
+	}
+	return left
+}
+
+// isJobKeyOf reports whether a job key, which starts with the video ID, belongs to videoID
+func isJobKeyOf(key, videoID string) bool {
+	rest, found := strings.CutPrefix(key, videoID)
+	return found && (rest == "" || rest[0] == '.' || rest[0] == '~')
+}
+
+// startRunningJob registers a job a worker picked up and returns the context to process it with.
+// It returns false if the job was abandoned while it waited in the queue.
+func startRunningJob(ctx context.Context, job SummarizationJob) (context.Context, func(), bool) {
+	key := job.key()
+	activeVideoJobsMutex.Lock()
+	defer activeVideoJobsMutex.Unlock()
+
+	delete(queuedJobs, key)
+	queued := abandonedJob{key: key, enqueuedAt: job.EnqueuedAt}
+	if _, abandoned := abandonedJobs[queued]; abandoned {
+		delete(abandonedJobs, queued)
+		if !job.Reprocess {
+			return nil, nil, false
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	running := &runningJob{job: job, cancel: cancel}
+	runningJobs[key] = running
+	finish := func() {
+		activeVideoJobsMutex.Lock()
+		if runningJobs[key] == running {
+			delete(runningJobs, key)
+		}
+		activeVideoJobsMutex.Unlock()
+		cancel(nil)
+	}
+	return ctx, finish, true
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbandonedRunningJobIsCancelled(t *testing.T) {
+	setupWorkerTest(t)
+	const sharedVideoID = "aaaaaaaaaaa"
+	subscribe("user1", testVideoID)
+	subscribe("user1", sharedVideoID)
+	other := subscribe("user2", sharedVideoID)
+
+	started := make(chan string, 2)
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		started <- job.VideoID
+		if job.VideoID == sharedVideoID {
+			return &SummaryResponse{VideoID: job.VideoID}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	done := make(chan []string)
+	go func() { done <- handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"}) }()
+	assert.Equal(t, testVideoID, <-started)
+
+	// user1 is the only one waiting for testVideoID, but not for the shared video
+	assert.Equal(t, []string{testVideoID}, abandonJobsOf("user1"))
+	select {
+	case notified := <-done:
+		assert.Empty(t, notified)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the abandoned job was not cancelled")
+	}
+	assert.False(t, isJobActive(testVideoID))
+
+	notified := handleJob(1, SummarizationJob{VideoID: sharedVideoID, UserID: "user1"})
+	assert.ElementsMatch(t, []string{"user1", "user2"}, notified)
+	assert.Contains(t, receive(other), "summary_complete")
+}
+
+func TestAbandonedQueuedJobIsSkipped(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue := jobQueue
+	jobQueue = make(chan SummarizationJob, 2)
+	t.Cleanup(func() { jobQueue = originalQueue })
+
+	processed := 0
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		processed++
+		return &SummaryResponse{VideoID: job.VideoID}, nil
+	}
+
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1"}
+	assert.True(t, subscribeToJob(job.key(), "user1", ""))
+	assert.True(t, tryEnqueueJob(job))
+	abandonJobsOf("user1")
+
+	// Requested again before a worker got to the first copy: only the new copy is processed
+	ch := subscribe("user1", testVideoID)
+	assert.True(t, tryEnqueueJob(job))
+	handleJob(1, <-jobQueue)
+	assert.Equal(t, 0, processed)
+	handleJob(1, <-jobQueue)
+	assert.Equal(t, 1, processed)
+	assert.Contains(t, receive(ch), "summary_complete")
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/akirose/youtube-summarizer/models"
+)
+
+// jobJournal records queued jobs under CACHE_DIR so they are re-queued after a restart.
+// nil disables journaling.
+var jobJournal *models.JobJournal
+
+// journaledJobs holds the queued jobs recorded in jobJournal by job key, so the entry can be rewritten
+// when subscribers join. Guarded by activeVideoJobsMutex.
+var journaledJobs = make(map[string]SummarizationJob)
+
+// journalEntry is a queued job as recorded in the journal, together with who to notify when it completes
+type journalEntry struct {
+	Job         SummarizationJob `json:"job"`
+	Subscribers []string         `json:"subscribers"`
+	Callbacks   []jobCallback    `json:"callbacks,omitempty"`
+
+	// OwnAPIKey is set when the requester supplied their own API key. The key itself is never written
+	// to disk, so a restored job runs under the server key policy instead.
+	OwnAPIKey bool `json:"ownApiKey,omitempty"`
+}
+
+// initJobJournal opens the job journal in cacheDir and re-queues the jobs left unfinished by the
+// previous run, restoring their subscribers and callbacks. Workers must already be running.
+func initJobJournal(cacheDir string) error {
+	journal, err := models.OpenJobJournal(filepath.Join(cacheDir, "jobs", "queue.json"))
+	if err != nil {
+		return err
+	}
+	jobJournal = journal
+
+	for key, data := range journal.Entries() {
+		var entry journalEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			logWarn("Dropping unreadable job %s from the job journal: %v", key, err)
+			journal.Remove(key)
+			continue
+		}
+		restoreJournaledJob(key, entry)
+	}
+	return nil
+}
+
+// restoreJournaledJob registers a job from the journal as active again and re-queues it
+func restoreJournaledJob(key string, entry journalEntry) {
+	job := entry.Job
+	if entry.OwnAPIKey {
+		logWarn("Restored job %s was requested with the user's own API key, which is not persisted. It runs under the server key policy.", key)
+	}
+
+	activeVideoJobsMutex.Lock()
+	activeVideoJobs[key] = entry.Subscribers
+	jobCallbacks[key] = entry.Callbacks
+	activeVideoJobsMutex.Unlock()
+
+	// Subscribers are notified over SSE once they reconnect; results sent before that are dropped
+	logInfo("Restoring unfinished job %s from the job journal with %d subscriber(s).", key, len(entry.Subscribers))
+	if !tryEnqueueJob(job) {
+		completeJob(job, nil, errJobQueueFull, "")
+		logWarn("Job queue full while restoring job %s. Its subscribers were notified of the failure.", key)
+	}
+}
+
+// journalJob records a queued job. Jobs are journaled again whenever subscribers join.
+func journalJob(job SummarizationJob) {
+	if jobJournal == nil {
+		return
+	}
+	activeVideoJobsMutex.Lock()
+	defer activeVideoJobsMutex.Unlock()
+	journaledJobs[job.key()] = job
+	rejournalJobLocked(job.key())
+}
+
+// rejournalJobLocked rewrites the journal entry of a queued job with its current subscribers and
+// callbacks. The caller must hold activeVideoJobsMutex.
+func rejournalJobLocked(key string) {
+	job, ok := journaledJobs[key]
+	if !ok || jobJournal == nil {
+		return
+	}
+	entry := journalEntry{
+		Job:         job,
+		Subscribers: activeVideoJobs[key],
+		Callbacks:   jobCallbacks[key],
+		OwnAPIKey:   job.APIKey != "",
+	}
+	entry.Job.APIKey = ""
+	entry.Job.Transcript = nil // Fetched again; it can be large
+	if err := jobJournal.Put(key, entry); err != nil {
+		logWarn("Failed to journal job %s: %v", key, err)
+	}
+}
+
+// unjournalJob removes a finished job from the journal
+func unjournalJob(key string) {
+	activeVideoJobsMutex.Lock()
+	_, ok := journaledJobs[key]
+	delete(journaledJobs, key)
+	activeVideoJobsMutex.Unlock()
+
+	if !ok || jobJournal == nil {
+		return
+	}
+	if err := jobJournal.Remove(key); err != nil {
+		logWarn("Failed to remove job %s from the job journal: %v", key, err)
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaledJobsAreRestoredAfterRestart(t *testing.T) {
+	setupWorkerTest(t)
+	cacheDir := t.TempDir()
+	originalQueue := jobQueue
+	t.Cleanup(func() {
+		jobQueue = originalQueue
+		jobJournal = nil
+	})
+
+	// First run: a job is queued and a second user subscribes before it is processed
+	jobQueue = make(chan SummarizationJob, 1)
+	assert.NoError(t, initJobJournal(cacheDir))
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-secret", Languages: []string{"en"}}
+	assert.True(t, subscribeToJob(job.key(), "user1", ""))
+	assert.True(t, tryEnqueueJob(job))
+	assert.False(t, subscribeToJob(job.key(), "user2", ""))
+
+	data, err := os.ReadFile(filepath.Join(cacheDir, "jobs", "queue.json"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-secret", "API keys must not be persisted")
+
+	// Restart: in-memory state is lost and the journal is read back
+	setupWorkerTest(t)
+	jobQueue = make(chan SummarizationJob, 1)
+	assert.NoError(t, initJobJournal(cacheDir))
+
+	restored := <-jobQueue
+	assert.Equal(t, testVideoID, restored.VideoID)
+	assert.Equal(t, []string{"en"}, restored.Languages)
+	assert.Empty(t, restored.APIKey, "API keys must not be persisted")
+
+	activeVideoJobsMutex.RLock()
+	assert.Equal(t, []string{"user1", "user2"}, activeVideoJobs[job.key()])
+	activeVideoJobsMutex.RUnlock()
+
+	// Completing the job removes it from the journal
+	completeJob(restored, &SummaryResponse{VideoID: testVideoID}, nil, "")
+	assert.Empty(t, jobJournal.Entries())
+}
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/akirose/youtube-summarizer/services"
+
+// Shorthands for the leveled logger shared by all packages (see services.ConfigureLogLevel)
+func logDebug(format string, args ...interface{}) { services.LogDebug(format, args...) }
+func logInfo(format string, args ...interface{})  { services.LogInfo(format, args...) }
+func logWarn(format string, args ...interface{})  { services.LogWarn(format, args...) }
+func logError(format string, args ...interface{}) { services.LogError(format, args...) }
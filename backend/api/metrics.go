@@ -0,0 +1,119 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace prefixes every metric exposed on /metrics
+const metricsNamespace = "youtube_summarizer"
+
+// Label values of the cache lookup counter
+const (
+	cacheSourceRequest = "request" // HandleSummaryRequest, before a job is queued
+	cacheSourceWorker  = "worker"  // processSummarizationJob, when a worker picks up a job
+)
+
+// metricsRegistry holds the collectors served on /metrics. A dedicated registry instead of the
+// global default one keeps repeated InitSummaryModule calls in tests from registering twice.
+var metricsRegistry = prometheus.NewRegistry()
+
+var registerMetricsOnce sync.Once
+
+var (
+	// Labels stay low-cardinality on purpose: never add the video or user ID
+	cacheLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "cache_lookups_total",
+		Help:      "Summary cache lookups by source and result (hit or miss).",
+	}, []string{"source", "result"})
+
+	busyWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "busy_workers",
+		Help:      "Number of workers currently processing a summarization job.",
+	})
+
+	workerRestartsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "worker_restarts_total",
+		Help:      "Number of workers that died from a panic and were replaced.",
+	})
+
+	jobDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "job_duration_seconds",
+		Help:      "Time a worker spent on a summarization job, by outcome (success or error).",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	}, []string{"outcome"})
+)
+
+// registerMetrics registers the collectors once; gauges read the job state when scraped
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		metricsRegistry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+			cacheLookupsTotal,
+			busyWorkers,
+			workerRestartsTotal,
+			jobDurationSeconds,
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "job_queue_length",
+				Help:      "Number of summarization jobs waiting in the job queue.",
+			}, func() float64 { return float64(len(jobQueue)) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "job_queue_capacity",
+				Help:      "Capacity of the job queue; requests are rejected when it is full.",
+			}, func() float64 { return float64(cap(jobQueue)) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "active_jobs",
+				Help:      "Number of videos being summarized or waiting in the queue.",
+			}, func() float64 {
+				activeVideoJobsMutex.RLock()
+				defer activeVideoJobsMutex.RUnlock()
+				return float64(len(activeVideoJobs))
+			}),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "sse_clients",
+				Help:      "Number of connected SSE clients.",
+			}, func() float64 {
+				clientChannelsMutex.RLock()
+				defer clientChannelsMutex.RUnlock()
+				return float64(len(clientChannels))
+			}),
+		)
+	})
+}
+
+// recordCacheLookup counts a summary cache lookup
+func recordCacheLookup(source string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookupsTotal.WithLabelValues(source, result).Inc()
+}
+
+// recordJobDuration observes how long a worker spent on a job
+func recordJobDuration(start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	jobDurationSeconds.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
+
+// MetricsHandler serves the Prometheus metrics registered by InitSummaryModule
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+}
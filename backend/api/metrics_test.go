@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// jobDurationCount returns how many jobs with the given outcome the duration histogram observed
+func jobDurationCount(t *testing.T, outcome string) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	assert.NoError(t, jobDurationSeconds.WithLabelValues(outcome).(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestMetricsReportJobStateAndCacheLookups(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue := jobQueue
+	jobQueue = make(chan SummarizationJob, 5)
+	t.Cleanup(func() { jobQueue = originalQueue })
+	registerMetrics()
+
+	jobQueue <- SummarizationJob{VideoID: testVideoID}
+	subscribe("user1", testVideoID)
+
+	hits := testutil.ToFloat64(cacheLookupsTotal.WithLabelValues(cacheSourceWorker, "hit"))
+	transcript := []services.TranscriptItem{{Text: "hello", Start: 0, Duration: 1}}
+	assert.NoError(t, summaryCache.Set(testVideoID, "Title", "[00:00] summary", nil, transcript))
+	_, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+	assert.NoError(t, err)
+	assert.Equal(t, hits+1, testutil.ToFloat64(cacheLookupsTotal.WithLabelValues(cacheSourceWorker, "hit")))
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return nil, errors.New("boom")
+	}
+	failures := jobDurationCount(t, "error")
+	handleJob(1, SummarizationJob{VideoID: "aaaaaaaaaaa", UserID: "user2"})
+	assert.Equal(t, failures+1, jobDurationCount(t, "error"))
+	assert.Equal(t, 0.0, testutil.ToFloat64(busyWorkers))
+
+	router := gin.New()
+	router.GET("/metrics", MetricsHandler())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "youtube_summarizer_job_queue_length 1")
+	assert.Contains(t, body, "youtube_summarizer_job_queue_capacity 5")
+	assert.Contains(t, body, "youtube_summarizer_active_jobs 1")
+	assert.Contains(t, body, "youtube_summarizer_sse_clients 1")
+	assert.Contains(t, body, `youtube_summarizer_cache_lookups_total{result="hit",source="worker"}`)
+	assert.NotContains(t, body, testVideoID)
+}
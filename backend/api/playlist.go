@@ -0,0 +1,185 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaxPlaylistSize bounds how many videos a playlist request may queue when MAX_PLAYLIST_SIZE is not set
+const defaultMaxPlaylistSize = 50
+
+// PlaylistSummaryRequest is the body of POST /api/summary/playlist
+type PlaylistSummaryRequest struct {
+	URL string `json:"url" binding:"required"` // Playlist URL, or a watch URL with a list parameter
+
+	// Language, Model, Temperature and Style apply to every video, as in SummaryRequest
+	Language    string   `json:"language,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	Style       string   `json:"style,omitempty"`
+}
+
+// PlaylistSummaryResponse lists what happened to each video of a playlist request.
+// Summaries of queued and in-progress videos arrive as summary_complete SSE events.
+type PlaylistSummaryResponse struct {
+	PlaylistID string   `json:"playlistId"`
+	VideoIDs   []string `json:"videoIds"`   // Every video of the playlist, in playlist order
+	Queued     []string `json:"queued"`     // Newly queued videos
+	InProgress []string `json:"inProgress"` // Videos already being summarized; the user was subscribed to them
+	Cached     []string `json:"cached"`     // Videos already summarized; added to the user's history
+	Rejected   []string `json:"rejected"`   // Videos not queued because the job queue was full
+}
+
+// maxPlaylistSize returns how many videos a playlist may have, configured via MAX_PLAYLIST_SIZE
+func maxPlaylistSize() int {
+	if n := services.GetEnvInt("MAX_PLAYLIST_SIZE", defaultMaxPlaylistSize); n > 0 {
+		return n
+	}
+	return defaultMaxPlaylistSize
+}
+
+// HandlePlaylistSummaryRequest enumerates the videos of a YouTube playlist and queues a summarization
+// job for each one through the same queue and deduplication as single video requests. Playlists with
+// more than MAX_PLAYLIST_SIZE videos are rejected with 400 instead of flooding the queue.
+func HandlePlaylistSummaryRequest(c *gin.Context) {
+	var request PlaylistSummaryRequest
+	if !bindJSONBody(c, &request) {
+		return
+	}
+
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": localize(c, i18n.MsgUserNotFound),
+		})
+		return
+	}
+	userID := userInfo.ID
+
+	// Same field checks as single video requests
+	if verr := validateSummaryRequest(&SummaryRequest{URL: request.URL, Language: request.Language, Model: request.Model, Temperature: request.Temperature, Style: request.Style}); verr != nil {
+		c.JSON(http.StatusBadRequest, verr.response(i18n.RequestLanguage(c.Request)))
+		return
+	}
+
+	userAPIKey, keySource := resolveAPIKey(extractAPIKeyFromHeader(c), userID)
+	if keySource == apiKeySourceNone {
+		c.JSON(http.StatusForbidden, apiKeyRequiredResponse(c, userID))
+		return
+	}
+	model := request.Model
+	if model != "" && userAPIKey == "" {
+		logInfo("HandlePlaylistSummaryRequest: Ignoring model %q requested by UserID %s without their own API key.", model, userID)
+		model = ""
+	}
+
+	playlistID, err := services.GetPlaylistID(request.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	requestID := requestIDFor(c)
+	c.Header(requestIDHeader, requestID)
+	ctx, span := services.StartSpan(requestContext(c), "HandlePlaylistSummaryRequest", services.RequestIDAttr(requestID))
+	defer span.End()
+
+	if !checkUserAPIKey(ctx, c, userAPIKey, keySource) {
+		return
+	}
+
+	// Asking for one more video than allowed tells an oversized playlist apart without listing all of it
+	maxSize := maxPlaylistSize()
+	videoIDs, err := services.GetPlaylistVideoIDs(ctx, playlistID, maxSize+1)
+	if err != nil {
+		logError("HandlePlaylistSummaryRequest: PlaylistID %s: Failed to list videos: %v", playlistID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": localize(c, i18n.MsgPlaylistListFailed), "playlistId": playlistID})
+		return
+	}
+	if len(videoIDs) > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgPlaylistTooLarge, maxSize), "playlistId": playlistID})
+		return
+	}
+	if len(videoIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgPlaylistEmpty), "playlistId": playlistID})
+		return
+	}
+
+	template := SummarizationJob{
+		UserID: userID,
+		APIKey: userAPIKey,
+		IsSSE:  true,
+
+		Languages:    normalizeLanguages([]string{request.Language}),
+		Model:        model,
+		Temperature:  request.Temperature,
+		Style:        request.Style,
+		RequestID:    requestID,
+		TraceCarrier: injectTraceContext(ctx),
+
+		MessageLanguage: i18n.RequestLanguage(c.Request),
+	}
+	resp := enqueuePlaylistVideos(template, videoIDs)
+	resp.PlaylistID = playlistID
+
+	logInfo("HandlePlaylistSummaryRequest: PlaylistID %s by UserID %s: %d video(s), %d queued, %d in progress, %d cached, %d rejected.",
+		playlistID, userID, len(videoIDs), len(resp.Queued), len(resp.InProgress), len(resp.Cached), len(resp.Rejected))
+	if len(resp.Rejected) > 0 && len(resp.Queued) == 0 && len(resp.InProgress) == 0 {
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// enqueuePlaylistVideos queues a copy of the template job for every video that is neither cached
+// nor already being summarized. Cached videos are added to the user's history, and the user is
+// subscribed to videos already in progress. Videos are rejected once the queue is full or the user's
+// server key quota is used up.
+func enqueuePlaylistVideos(template SummarizationJob, videoIDs []string) PlaylistSummaryResponse {
+	resp := PlaylistSummaryResponse{
+		VideoIDs:   videoIDs,
+		Queued:     []string{},
+		InProgress: []string{},
+		Cached:     []string{},
+		Rejected:   []string{},
+	}
+	userID := template.UserID
+	for _, videoID := range videoIDs {
+		job := template
+		job.VideoID = videoID
+		job.URL = services.CanonicalVideoURL(videoID)
+
+		if summaryCache != nil {
+			if cachedItem, found := summaryCache.Get(job.cacheKey()); found && !cachedItem.Partial && cachedItem.SummaryStyle() == job.style() {
+				if err := models.AddUserSummary(userID, videoID, cachedItem.Title); err != nil {
+					logWarn("HandlePlaylistSummaryRequest (Cache Hit): UserID %s, VideoID %s: Failed to add user summary: %v", userID, videoID, err)
+				}
+				resp.Cached = append(resp.Cached, videoID)
+				continue
+			}
+		}
+
+		if !subscribeToJob(job.key(), userID, "") {
+			resp.InProgress = append(resp.InProgress, videoID)
+			continue
+		}
+		if !reserveServerKeySummary(job) {
+			completeJob(job, nil, errServerKeyQuotaExhausted, userID)
+			resp.Rejected = append(resp.Rejected, videoID)
+			continue
+		}
+		if !tryEnqueueJob(job) {
+			// Unregister the job and tell anyone who subscribed in the meantime, as for single videos
+			completeJob(job, nil, errJobQueueFull, userID)
+			resp.Rejected = append(resp.Rejected, videoID)
+			continue
+		}
+		resp.Queued = append(resp.Queued, videoID)
+	}
+	return resp
+}
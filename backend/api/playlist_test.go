@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnqueuePlaylistVideos(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue := jobQueue
+	jobQueue = make(chan SummarizationJob, 1)
+	t.Cleanup(func() { jobQueue = originalQueue })
+
+	cachedID, activeID, queuedID, rejectedID := "aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc", "ddddddddddd"
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: cachedID, Title: "Cached", Summary: "done"}))
+	subscribeToJob(activeID, "user2", "")
+
+	template := SummarizationJob{UserID: "user1", APIKey: "sk-test", IsSSE: true, Languages: []string{"ko"}, Model: "gpt-4o"}
+	resp := enqueuePlaylistVideos(template, []string{cachedID, activeID, queuedID, rejectedID})
+
+	assert.Equal(t, []string{cachedID, activeID, queuedID, rejectedID}, resp.VideoIDs)
+	assert.Equal(t, []string{cachedID}, resp.Cached)
+	assert.Equal(t, []string{activeID}, resp.InProgress)
+	assert.Equal(t, []string{queuedID}, resp.Queued)
+	assert.Equal(t, []string{rejectedID}, resp.Rejected, "the queue only had room for one job")
+
+	job := <-jobQueue
+	assert.Equal(t, queuedID, job.VideoID)
+	assert.Equal(t, "https://www.youtube.com/watch?v="+queuedID, job.URL)
+	assert.Equal(t, "gpt-4o", job.Model)
+
+	summaries, err := models.GetUserSummaries("user1", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) {
+		assert.Equal(t, cachedID, summaries[0].VideoID)
+	}
+	activeVideoJobsMutex.RLock()
+	assert.Equal(t, []string{"user2", "user1"}, activeVideoJobs[activeID], "the user is subscribed to the job already in progress")
+	activeVideoJobsMutex.RUnlock()
+	assert.False(t, isJobActive(rejectedID), "rejected jobs are unregistered")
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// defaultSummaryLanguage is the language summaries are written in unless another one is requested
+const defaultSummaryLanguage = services.DefaultSummaryLanguage
+
+// summaryQuality collects the caveats that apply to a generated summary
+type summaryQuality struct {
+	Translated  bool // Summarized from machine-translated captions
+	LowCoverage bool // The transcript only covers part of the video
+	Truncated   bool // The transcript was cut short to fit the model's limits
+}
+
+// qualityNoteTexts holds the localized note for each caveat, keyed by language
+var qualityNoteTexts = map[string]map[string]string{
+	"ko": {
+		"translated":  "⚠️ 자동 번역된 자막 기반 요약",
+		"lowCoverage": "⚠️ 자막이 영상 일부만 포함하여 요약이 불완전할 수 있음",
+		"truncated":   "⚠️ 자막이 길어 일부만 요약됨",
+	},
+	"en": {
+		"translated":  "⚠️ Summary based on machine-translated captions",
+		"lowCoverage": "⚠️ Captions cover only part of the video; the summary may be incomplete",
+		"truncated":   "⚠️ Only part of a long transcript was summarized",
+	},
+	"ja": {
+		"translated":  "⚠️ 自動翻訳された字幕に基づく要約",
+		"lowCoverage": "⚠️ 字幕が動画の一部のみのため、要約が不完全な可能性があります",
+		"truncated":   "⚠️ 字幕が長いため一部のみ要約されています",
+	},
+}
+
+// qualityNote returns the note describing the caveats of a summary in the given language,
+// or an empty string if there are none. Unknown languages fall back to English.
+func qualityNote(quality summaryQuality, language string) string {
+	texts, ok := qualityNoteTexts[strings.ToLower(language)]
+	if !ok {
+		texts = qualityNoteTexts["en"]
+	}
+
+	var lines []string
+	if quality.Translated {
+		lines = append(lines, texts["translated"])
+	}
+	if quality.LowCoverage {
+		lines = append(lines, texts["lowCoverage"])
+	}
+	if quality.Truncated {
+		lines = append(lines, texts["truncated"])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withQualityNote prepends the quality note to the summary text when PREPEND_QUALITY_NOTE is enabled,
+// so the caveat travels with the text even for clients that ignore the structured flags.
+func withQualityNote(summary string, quality summaryQuality, language string) string {
+	if !services.GetEnvBool("PREPEND_QUALITY_NOTE", false) {
+		return summary
+	}
+	note := qualityNote(quality, language)
+	if note == "" {
+		return summary
+	}
+	return note + "\n\n" + summary
+}
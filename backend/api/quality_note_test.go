@@ -0,0 +1,21 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithQualityNote(t *testing.T) {
+	quality := summaryQuality{LowCoverage: true}
+
+	t.Setenv("PREPEND_QUALITY_NOTE", "false")
+	assert.Equal(t, "summary", withQualityNote("summary", quality, "ko"), "disabled by default")
+
+	t.Setenv("PREPEND_QUALITY_NOTE", "true")
+	assert.Equal(t, "summary", withQualityNote("summary", summaryQuality{}, "ko"), "no note without caveats")
+	assert.Equal(t, "⚠️ 자막이 영상 일부만 포함하여 요약이 불완전할 수 있음\n\nsummary", withQualityNote("summary", quality, "ko"))
+
+	note := qualityNote(summaryQuality{Translated: true, Truncated: true}, "fr")
+	assert.Equal(t, "⚠️ Summary based on machine-translated captions\n⚠️ Only part of a long transcript was summarized", note, "unknown languages fall back to English")
+}
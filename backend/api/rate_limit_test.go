@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterPerKey(t *testing.T) {
+	limiter := newRateLimiter(2, time.Minute)
+
+	assert.True(t, limiter.allow("user1"))
+	assert.True(t, limiter.allow("user1"))
+	assert.False(t, limiter.allow("user1"))
+	assert.True(t, limiter.allow("user2"), "limits are tracked per key")
+
+	// Events outside the window no longer count
+	limiter.window = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	assert.True(t, limiter.allow("user1"))
+
+	assert.True(t, newRateLimiter(0, time.Minute).allow("user1"), "0 disables the limit")
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// HandleRegenerateSummary discards the cached summary of a video and summarizes it again.
+// It takes the same body as POST /api/summary and answers 202 like a queued summary; the result arrives via SSE.
+// While a job for the video is already running, the user is subscribed to it instead of starting another one.
+func HandleRegenerateSummary(c *gin.Context) {
+	req, ok := bindSummaryRequest(c, "HandleRegenerateSummary")
+	if !ok {
+		return
+	}
+	userID, videoID := req.userID, req.videoID
+
+	requestID := requestIDFor(c)
+	c.Header(requestIDHeader, requestID)
+	ctx, span := services.StartSpan(requestContext(c), "HandleRegenerateSummary", services.VideoIDAttr(videoID), services.RequestIDAttr(requestID))
+	defer span.End()
+
+	if !checkUserAPIKey(ctx, c, req.userAPIKey, req.keySource) {
+		return
+	}
+	if !checkRequestVideoDuration(ctx, c, videoID) {
+		return
+	}
+
+	job := newSummarizationJob(ctx, req, requestID)
+	job.Regenerate = true
+	if !subscribeToJob(job.key(), userID, req.CallbackURL) {
+		// Repeated regenerate requests end up here until the running job has finished
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  localize(c, i18n.MsgSummaryInProgress),
+			"video_id": videoID,
+		})
+		return
+	}
+
+	// Only discard the cache once this request owns the job, so a concurrent one can't be left without a summary.
+	// Deleting drops the pin, so the job pins the new summaries of pinned ones again.
+	if summaryCache != nil {
+		for _, language := range req.languages {
+			key := models.CacheKey(videoID, language)
+			if summaryCache.IsPinned(key) {
+				if job.Pinned == nil {
+					job.Pinned = make(map[string]bool)
+				}
+				job.Pinned[key] = true
+			}
+			if err := summaryCache.Delete(key); err != nil {
+				logError("HandleRegenerateSummary: UserID %s, VideoID %s: Failed to delete cached summary: %v", userID, videoID, err)
+				completeJob(job, nil, &jobStageError{stage: jobStageCache, err: err}, userID)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgCacheDeleteFailed), "video_id": videoID})
+				return
+			}
+		}
+	}
+	logInfo("HandleRegenerateSummary: Regenerating summary of VideoID %s for UserID %s.", videoID, userID)
+	submitSummaryJob(c, job)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegenerateKeepsPinnedSummariesPinned(t *testing.T) {
+	router, queue := setupSummaryRequestTest(t, "user1")
+	router.POST("/api/summary/regenerate", HandleRegenerateSummary)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "duration": 5}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Fresh summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	transcript := [][]services.TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}}
+	assert.NoError(t, summaryCache.Set(testVideoID, "Video", "[00:00] Bad summary", nil, transcript[0]))
+	_, err := summaryCache.Pin(testVideoID)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/summary/regenerate", strings.NewReader(`{"url": "https://www.youtube.com/watch?v=`+testVideoID+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-user1"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String()) || !assert.Len(t, queue, 1) {
+		return
+	}
+	_, cached := summaryCache.Get(testVideoID)
+	assert.False(t, cached, "the bad summary is discarded")
+
+	job := <-queue
+	assert.True(t, job.Pinned[testVideoID])
+	job.APIKey, job.Transcript = "sk-test", transcript
+	_, err = processSummarizationJob(context.Background(), job)
+	assert.NoError(t, err)
+
+	item, ok := summaryCache.Get(testVideoID)
+	if assert.True(t, ok) {
+		assert.Contains(t, item.Summary, "Fresh summary")
+		assert.True(t, item.Pinned, "the regenerated summary stays pinned")
+	}
+	assert.True(t, summaryCache.IsPinned(testVideoID))
+}
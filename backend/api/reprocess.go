@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// reprocessUserID is the user reprocessing jobs run as, so their cost is not charged to the original requester
+const reprocessUserID = "system:reprocess"
+
+// defaultReprocessIntervalMinutes is how often low-coverage summaries are checked when REPROCESS_INTERVAL_MINUTES is not set
+const defaultReprocessIntervalMinutes = 360
+
+// defaultReprocessRecheckHours is how long a summary is left alone after its captions were checked
+const defaultReprocessRecheckHours = 24
+
+// minCoverageImprovement is how many percentage points the coverage must improve before a summary is regenerated
+const minCoverageImprovement = 10.0
+
+// fetchTranscript fetches a video's transcript for reprocessing. Tests replace it to control the captions found.
+var fetchTranscript = services.GetTranscript
+
+// startCoverageReprocessing periodically regenerates summaries whose transcript covered less than
+// LOW_COVERAGE_THRESHOLD percent of the video, once better captions are available.
+// Enabled with REPROCESS_LOW_COVERAGE=true.
+func startCoverageReprocessing() {
+	if !services.GetEnvBool("REPROCESS_LOW_COVERAGE", false) {
+		return
+	}
+	minutes := services.GetEnvInt("REPROCESS_INTERVAL_MINUTES", defaultReprocessIntervalMinutes)
+	if minutes <= 0 {
+		minutes = defaultReprocessIntervalMinutes
+	}
+	logInfo("Checking low-coverage summaries for better captions every %d minute(s).", minutes)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if queued := reprocessLowCoverageSummaries(context.Background()); queued > 0 {
+				logInfo("Queued %d low-coverage summary(s) for reprocessing.", queued)
+			}
+		}
+	}()
+}
+
+// reprocessLowCoverageSummaries checks the captions of cached low-coverage summaries and queues a
+// reprocessing job for each one whose coverage improved. A summary is checked at most once every
+// REPROCESS_RECHECK_HOURS. It returns the number of jobs queued.
+func reprocessLowCoverageSummaries(ctx context.Context) int {
+	if summaryCache == nil {
+		return 0
+	}
+	threshold := float64(services.GetEnvInt("LOW_COVERAGE_THRESHOLD", defaultLowCoverageThreshold))
+	recheckAfter := time.Duration(services.GetEnvInt("REPROCESS_RECHECK_HOURS", defaultReprocessRecheckHours)) * time.Hour
+
+	queued := 0
+	for _, key := range summaryCache.LowCoverageKeys(threshold) {
+		item, found := summaryCache.Get(key)
+		if !found || item.Partial || item.Duration <= 0 {
+			continue
+		}
+		if item.CoverageCheckedAt != nil && time.Since(*item.CoverageCheckedAt) < recheckAfter {
+			continue
+		}
+		if reprocessIfCoverageImproved(ctx, item) {
+			queued++
+		}
+	}
+	return queued
+}
+
+// reprocessIfCoverageImproved fetches the current captions of a cached summary's video and queues a
+// job regenerating the summary from them if they cover noticeably more of the video. The original
+// requester is subscribed to the job, so they receive the new summary if connected via SSE.
+func reprocessIfCoverageImproved(ctx context.Context, item *models.CacheItem) bool {
+	job := SummarizationJob{
+		VideoID:   item.VideoID,
+		UserID:    reprocessUserID,
+		Language:  item.Language,
+		Style:     item.Style,
+		Reprocess: true,
+	}
+	key := job.key()
+	if isJobActive(key) {
+		return false // Checked again on the next sweep
+	}
+
+	chunks, err := fetchTranscript(ctx, item.VideoID, transcriptChunkSeconds())
+	if err != nil {
+		logWarn("Reprocess: VideoID %s: Failed to fetch transcript: %v", item.VideoID, err)
+		markCoverageChecked(item)
+		return false
+	}
+	transcriptItems := services.MergeTranscriptChunks(chunks)
+	coverage := services.TranscriptCoverage(transcriptItems, item.Duration)
+	if coverage < item.Coverage+minCoverageImprovement {
+		logDebug("Reprocess: VideoID %s: Coverage %.1f%% -> %.1f%%, not regenerating", item.VideoID, item.Coverage, coverage)
+		markCoverageChecked(item)
+		return false
+	}
+
+	activeVideoJobsMutex.Lock()
+	if _, active := activeVideoJobs[key]; active {
+		activeVideoJobsMutex.Unlock()
+		return false
+	}
+	subscribers := []string{}
+	if item.RequestedBy != "" {
+		subscribers = append(subscribers, item.RequestedBy)
+	}
+	activeVideoJobs[key] = subscribers
+	activeVideoJobsMutex.Unlock()
+
+	// Mark before queuing, so a failing job is not retried before the recheck interval
+	markCoverageChecked(item)
+	job.Transcript = chunks
+	if !tryEnqueueJob(job) {
+		completeJob(job, nil, errJobQueueFull, item.RequestedBy)
+		// Restore the unchecked item, so it is retried on the next sweep
+		if err := summaryCache.SetItem(item); err != nil {
+			logWarn("Reprocess: VideoID %s: Failed to restore cache item: %v", item.VideoID, err)
+		}
+		logWarn("Reprocess: VideoID %s: Job queue full, retrying on the next sweep.", item.VideoID)
+		return false
+	}
+	logInfo("Reprocess: VideoID %s: Coverage improved from %.1f%% to %.1f%%, regenerating summary.", item.VideoID, item.Coverage, coverage)
+	return true
+}
+
+// isJobActive reports whether a job with the key is queued or being processed
+func isJobActive(key string) bool {
+	activeVideoJobsMutex.RLock()
+	defer activeVideoJobsMutex.RUnlock()
+	_, active := activeVideoJobs[key]
+	return active
+}
+
+// markCoverageChecked records that a summary's captions were just checked
+func markCoverageChecked(item *models.CacheItem) {
+	checked := *item
+	now := time.Now()
+	checked.CoverageCheckedAt = &now
+	if err := summaryCache.SetItem(&checked); err != nil {
+		logWarn("Reprocess: VideoID %s: Failed to record coverage check: %v", item.VideoID, err)
+	}
+}
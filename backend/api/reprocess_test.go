@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTranscript makes reprocessing find captions covering the first `seconds` of every video
+func fakeTranscript(t *testing.T, seconds float64, fetches *int) {
+	t.Helper()
+	original := fetchTranscript
+	fetchTranscript = func(ctx context.Context, videoID string, chunkSize float64) ([][]services.TranscriptItem, error) {
+		*fetches++
+		return [][]services.TranscriptItem{{{Text: "better captions", Start: 0, Duration: seconds}}}, nil
+	}
+	t.Cleanup(func() { fetchTranscript = original })
+}
+
+func TestReprocessLowCoverageSummaries(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue := jobQueue
+	jobQueue = make(chan SummarizationJob, 1)
+	t.Cleanup(func() { jobQueue = originalQueue })
+
+	fetches := 0
+	fakeTranscript(t, 90, &fetches)
+
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Title: "Low", Summary: "old", Coverage: 40, Duration: 100, RequestedBy: "user1"}))
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: "aaaaaaaaaaa", Title: "Full", Summary: "ok", Coverage: 95, Duration: 100}))
+
+	assert.Equal(t, 1, reprocessLowCoverageSummaries(context.Background()))
+	assert.Equal(t, 1, fetches, "summaries with good coverage are not checked")
+
+	job := <-jobQueue
+	assert.Equal(t, testVideoID, job.VideoID)
+	assert.True(t, job.Reprocess)
+	assert.Equal(t, reprocessUserID, job.UserID)
+	activeVideoJobsMutex.RLock()
+	assert.Equal(t, []string{"user1"}, activeVideoJobs[testVideoID], "the original requester is notified of the new summary")
+	activeVideoJobsMutex.RUnlock()
+
+	// Not checked again while the job is active, nor within the recheck interval afterwards
+	assert.Equal(t, 0, reprocessLowCoverageSummaries(context.Background()))
+	completeJob(job, nil, nil, "")
+	assert.Equal(t, 0, reprocessLowCoverageSummaries(context.Background()))
+	assert.Equal(t, 1, fetches)
+}
+
+func TestReprocessSkipsSummariesWithoutBetterCaptions(t *testing.T) {
+	setupWorkerTest(t)
+	fetches := 0
+	fakeTranscript(t, 45, &fetches)
+
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Coverage: 40, Duration: 100}))
+
+	assert.Equal(t, 0, reprocessLowCoverageSummaries(context.Background()))
+	item, _ := summaryCache.Get(testVideoID)
+	if assert.NotNil(t, item.CoverageCheckedAt) {
+		assert.WithinDuration(t, time.Now(), *item.CoverageCheckedAt, time.Minute)
+	}
+	assert.False(t, isJobActive(testVideoID))
+
+	// Checked again once the recheck interval has passed
+	t.Setenv("REPROCESS_RECHECK_HOURS", "0")
+	reprocessLowCoverageSummaries(context.Background())
+	assert.Equal(t, 2, fetches)
+}
+
+func TestProcessSummarizationJobReprocessReplacesCachedSummary(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "duration": 100}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] New summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Summary: "old", Coverage: 40, Duration: 100, RequestedBy: "user1"}))
+
+	transcript := [][]services.TranscriptItem{{{Text: "better captions", Start: 0, Duration: 90}}}
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: reprocessUserID, APIKey: "sk-test", Reprocess: true, Transcript: transcript})
+	assert.NoError(t, err)
+	assert.True(t, resp.Reprocessed)
+	assert.Contains(t, resp.Summary, "New summary")
+
+	item, _ := summaryCache.Get(testVideoID)
+	assert.Equal(t, 90.0, item.Coverage)
+	assert.Equal(t, "user1", item.RequestedBy)
+	summaries, err := models.GetUserSummaries(reprocessUserID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, summaries, "reprocessing does not add to anyone's history")
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// Windows server key quotas are counted in. Both start at midnight UTC.
+const (
+	quotaWindowDay   = "day"
+	quotaWindowMonth = "month"
+)
+
+// Per-key counter names of server key usage by user, followed by the period of the window (e.g. "2026-10-16")
+const (
+	counterServerKeySummaries = "server_key_summaries:"
+	counterServerKeyTokens    = "server_key_tokens:"
+)
+
+// serverKeyQuota limits how many summaries and tokens each user may use the server key for per day or month.
+// Usage is kept in counterStore, so it survives restarts. A limit of 0 means unlimited.
+// Jobs count against the summary limit from when they are admitted, so a user can't start more jobs at
+// once than the quota has left.
+type serverKeyQuota struct {
+	window       string
+	summaryLimit int64
+	tokenLimit   int64
+	now          func() time.Time
+
+	mutex    sync.Mutex
+	reserved map[string]string // Job key -> user ID of admitted server key jobs that haven't finished yet
+}
+
+// errServerKeyQuotaExhausted fails jobs that are refused because the requester's server key quota is used up
+var errServerKeyQuotaExhausted error = i18n.NewError(i18n.MsgServerKeyQuotaExhausted)
+
+// activeServerKeyQuota is the quota usage is recorded for, nil if none is configured
+var activeServerKeyQuota *serverKeyQuota
+
+// initServerKeyQuota sets up the quota configured via SERVER_KEY_QUOTA_SUMMARIES, SERVER_KEY_QUOTA_TOKENS
+// and SERVER_KEY_QUOTA_WINDOW. Counters must already be initialized.
+func initServerKeyQuota() {
+	quota := &serverKeyQuota{
+		window:       strings.ToLower(strings.TrimSpace(os.Getenv("SERVER_KEY_QUOTA_WINDOW"))),
+		summaryLimit: int64(max(services.GetEnvInt("SERVER_KEY_QUOTA_SUMMARIES", 0), 0)),
+		tokenLimit:   int64(max(services.GetEnvInt("SERVER_KEY_QUOTA_TOKENS", 0), 0)),
+		now:          time.Now,
+		reserved:     make(map[string]string),
+	}
+	if quota.window == "" {
+		quota.window = quotaWindowDay
+	} else if quota.window != quotaWindowDay && quota.window != quotaWindowMonth {
+		logWarn("Unknown SERVER_KEY_QUOTA_WINDOW %q, using %q", quota.window, quotaWindowDay)
+		quota.window = quotaWindowDay
+	}
+
+	activeServerKeyQuota = nil
+	if quota.summaryLimit == 0 && quota.tokenLimit == 0 {
+		services.GetAPIKeyPolicy().SetServerKeyQuota(nil)
+		return
+	}
+	if counterStore == nil {
+		logWarn("Server key quota disabled: usage counters are not available.")
+		services.GetAPIKeyPolicy().SetServerKeyQuota(nil)
+		return
+	}
+	activeServerKeyQuota = quota
+	services.GetAPIKeyPolicy().SetServerKeyQuota(quota)
+	logInfo("Server key quota per user and %s: %d summaries, %d tokens (0 = unlimited).", quota.window, quota.summaryLimit, quota.tokenLimit)
+}
+
+// period returns the key of the window containing t and when the next one starts
+func (q *serverKeyQuota) period(t time.Time) (string, time.Time) {
+	t = t.UTC()
+	if q.window == quotaWindowMonth {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.Format("2006-01"), start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01-02"), start.AddDate(0, 0, 1)
+}
+
+// Status implements services.ServerKeyQuota
+func (q *serverKeyQuota) Status(userID string) services.QuotaStatus {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.statusLocked(userID)
+}
+
+// statusLocked returns the user's usage, counting their unfinished jobs as used summaries.
+// The caller must hold q.mutex.
+func (q *serverKeyQuota) statusLocked(userID string) services.QuotaStatus {
+	period, resetsAt := q.period(q.now())
+	status := services.QuotaStatus{
+		Window:        q.window,
+		SummaryLimit:  q.summaryLimit,
+		SummariesUsed: counterStore.GetKey(counterServerKeySummaries+period, userID),
+		TokenLimit:    q.tokenLimit,
+		TokensUsed:    counterStore.GetKey(counterServerKeyTokens+period, userID),
+		ResetsAt:      resetsAt,
+	}
+	for _, reservedBy := range q.reserved {
+		if reservedBy == userID {
+			status.SummariesInProgress++
+		}
+	}
+	if q.summaryLimit > 0 {
+		remaining := max(q.summaryLimit-status.SummariesUsed-status.SummariesInProgress, 0)
+		status.SummariesRemaining = &remaining
+	}
+	if q.tokenLimit > 0 {
+		remaining := max(q.tokenLimit-status.TokensUsed, 0)
+		status.TokensRemaining = &remaining
+	}
+	return status
+}
+
+// chargesServerKey reports whether a job's usage counts against its requester's quota
+func chargesServerKey(job SummarizationJob) bool {
+	return job.APIKey == "" && !job.Reprocess && job.UserID != ""
+}
+
+// reserveServerKeySummary admits a new job that is about to be queued or run: a job using the server key
+// takes one summary of its requester's quota until it finishes. It returns false, reserving nothing, if
+// the quota is used up.
+func reserveServerKeySummary(job SummarizationJob) bool {
+	quota := activeServerKeyQuota
+	if quota == nil || !chargesServerKey(job) {
+		return true
+	}
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+	if quota.statusLocked(job.UserID).Exhausted() {
+		return false
+	}
+	quota.reserved[job.key()] = job.UserID
+	return true
+}
+
+// releaseServerKeySummary gives back the summary reserved for a job that finished or was dropped.
+// Completed summaries are counted by recordServerKeyUsage instead.
+func releaseServerKeySummary(key string) {
+	quota := activeServerKeyQuota
+	if quota == nil {
+		return
+	}
+	quota.mutex.Lock()
+	delete(quota.reserved, key)
+	quota.mutex.Unlock()
+}
+
+// recordServerKeyUsage counts a summary generated with the server key against the requester's quota.
+// Interrupted summaries only count their tokens. Background reprocessing isn't charged to anyone.
+func recordServerKeyUsage(job SummarizationJob, usage services.TokenUsage, partial bool) {
+	quota := activeServerKeyQuota
+	if quota == nil || !chargesServerKey(job) {
+		return
+	}
+	period, _ := quota.period(quota.now())
+	if !partial {
+		counterStore.IncrementKey(counterServerKeySummaries+period, job.UserID, 1)
+	}
+	counterStore.IncrementKey(counterServerKeyTokens+period, job.UserID, int64(usage.TotalTokens()))
+}
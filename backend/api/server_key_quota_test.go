@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerKeyQuota(t *testing.T) {
+	setupCounters(t)
+	t.Setenv("SERVER_KEY_QUOTA_SUMMARIES", "2")
+	t.Setenv("SERVER_KEY_QUOTA_TOKENS", "1000")
+	t.Setenv("SERVER_KEY_QUOTA_WINDOW", "month")
+	initServerKeyQuota()
+	t.Cleanup(func() {
+		activeServerKeyQuota = nil
+		services.GetAPIKeyPolicy().SetServerKeyQuota(nil)
+	})
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	activeServerKeyQuota.now = func() time.Time { return now }
+	policy := services.GetAPIKeyPolicy()
+
+	job := SummarizationJob{VideoID: testVideoID, UserID: "alice"}
+	usage := services.TokenUsage{PromptTokens: 100, CompletionTokens: 50}
+	recordSummaryUsage(job, "ko", "model", usage, false)
+	recordSummaryUsage(SummarizationJob{VideoID: testVideoID, UserID: "alice", APIKey: "sk-own"}, "ko", "model", usage, false)
+	recordSummaryUsage(SummarizationJob{VideoID: testVideoID, UserID: "alice", Reprocess: true}, "ko", "model", usage, false)
+	recordSummaryUsage(job, "ko", "model", usage, true) // Interrupted: only the tokens count
+
+	status, ok := policy.QuotaStatus("alice")
+	assert.True(t, ok)
+	summariesLeft, tokensLeft := int64(1), int64(700)
+	assert.Equal(t, services.QuotaStatus{
+		Window:             "month",
+		SummaryLimit:       2,
+		SummariesUsed:      1,
+		SummariesRemaining: &summariesLeft,
+		TokenLimit:         1000,
+		TokensUsed:         300,
+		TokensRemaining:    &tokensLeft,
+		ResetsAt:           time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+	}, status)
+	assert.True(t, policy.CanUseServerKey("alice"))
+
+	recordSummaryUsage(job, "ko", "model", usage, false)
+	assert.False(t, policy.CanUseServerKey("alice"), "both summaries of the month are used")
+	_, source := resolveAPIKey("", "alice")
+	assert.Equal(t, apiKeySourceNone, source)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/api/summary", nil)
+	c.Request.Header.Set("Accept-Language", "ko-KR")
+	response := apiKeyRequiredResponse(c, "alice")
+	assert.Contains(t, response, "serverKeyQuota")
+	body, err := json.Marshal(response["serverKeyQuota"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"summariesRemaining":0`, "a used up quota must be told apart from an unlimited one")
+	assert.Equal(t, i18n.T(i18n.MsgServerKeyQuotaExhausted, i18n.Korean), response["error"])
+	assert.True(t, policy.CanUseServerKey("bob"))
+
+	// Own keys are still accepted, and a new month resets the quota
+	_, source = resolveAPIKey("sk-own", "alice")
+	assert.Equal(t, apiKeySourceHeader, source)
+	now = now.Add(2 * time.Hour)
+	assert.True(t, policy.CanUseServerKey("alice"))
+}
+
+func TestServerKeyQuotaReservesAdmittedJobs(t *testing.T) {
+	setupWorkerTest(t)
+	setupCounters(t)
+	t.Setenv("SERVER_KEY_QUOTA_SUMMARIES", "2")
+	t.Setenv("SERVER_KEY_QUOTA_TOKENS", "")
+	initServerKeyQuota()
+	t.Cleanup(func() {
+		activeServerKeyQuota = nil
+		services.GetAPIKeyPolicy().SetServerKeyQuota(nil)
+	})
+	policy := services.GetAPIKeyPolicy()
+
+	// Jobs started at once can't take more summaries than the quota has left
+	first := SummarizationJob{VideoID: "aaaaaaaaaaa", UserID: "alice"}
+	second := SummarizationJob{VideoID: "bbbbbbbbbbb", UserID: "alice"}
+	assert.True(t, reserveServerKeySummary(first))
+	assert.True(t, reserveServerKeySummary(second))
+	assert.False(t, reserveServerKeySummary(SummarizationJob{VideoID: "ccccccccccc", UserID: "alice"}))
+	assert.False(t, policy.CanUseServerKey("alice"))
+	assert.True(t, reserveServerKeySummary(SummarizationJob{VideoID: "ccccccccccc", UserID: "alice", APIKey: "sk-own"}), "own keys are not limited")
+	assert.True(t, policy.CanUseServerKey("bob"))
+
+	status, _ := policy.QuotaStatus("alice")
+	assert.Equal(t, int64(2), status.SummariesInProgress)
+	assert.Nil(t, status.TokensRemaining, "no token limit is configured")
+
+	// A failed job gives its summary back; a completed one counts it as used instead
+	completeJob(first, nil, errors.New("failed"), "")
+	assert.True(t, policy.CanUseServerKey("alice"))
+	recordSummaryUsage(second, "ko", "model", services.TokenUsage{}, false)
+	completeJob(second, &SummaryResponse{VideoID: second.VideoID}, nil, "")
+	status, _ = policy.QuotaStatus("alice")
+	assert.Equal(t, int64(1), status.SummariesUsed)
+	assert.Equal(t, int64(0), status.SummariesInProgress)
+	assert.Equal(t, int64(1), *status.SummariesRemaining)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// defaultShutdownGraceSeconds is how long running summarization jobs may take to finish on shutdown
+const defaultShutdownGraceSeconds = 30
+
+var (
+	// jobQueueMutex guards sends on jobQueue against DrainWorkers closing it
+	jobQueueMutex  sync.RWMutex
+	jobQueueClosed bool
+)
+
+// defaultSSEReconnectDelayMs is how long SSE clients wait before reconnecting after a server_shutdown event
+const defaultSSEReconnectDelayMs = 3000
+
+// NotifySSEShutdown tells every connected SSE client that the server is going down and closes their
+// channels, which ends the streams so the server can shut down. The server_shutdown event carries an
+// SSE retry hint, so browsers reconnect to a healthy instance after SSE_RECONNECT_DELAY_MS.
+// Disabled with SSE_SHUTDOWN_NOTIFY=false, in which case streams are only closed.
+// It returns the number of clients that were notified.
+func NotifySSEShutdown() int {
+	notify := services.GetEnvBool("SSE_SHUTDOWN_NOTIFY", true)
+	delayMs := services.GetEnvInt("SSE_RECONNECT_DELAY_MS", defaultSSEReconnectDelayMs)
+	if delayMs < 0 {
+		delayMs = defaultSSEReconnectDelayMs
+	}
+
+	clientChannelsMutex.Lock()
+	defer clientChannelsMutex.Unlock()
+
+	notified := 0
+	for userID, ch := range clientChannels {
+		if notify {
+			data, _ := json.Marshal(map[string]any{
+				"message":          i18n.T(i18n.MsgServerShuttingDown, clientLanguages[userID]),
+				"reconnectAfterMs": delayMs,
+			})
+			message := []byte(fmt.Sprintf("event: server_shutdown\nretry: %d\ndata: %s\n\n", delayMs, data))
+			select {
+			case ch <- message:
+				notified++
+			default:
+				logWarn("SSE channel for UserID %s is full. Shutdown notification dropped.", userID)
+			}
+		}
+		// The handler writes the buffered event before it sees the channel closed
+		close(ch)
+		delete(clientChannels, userID)
+		delete(clientLanguages, userID)
+	}
+	logInfo("Notified %d SSE client(s) of server shutdown.", notified)
+	return notified
+}
+
+// ShutdownGracePeriod returns how long DrainWorkers should wait for running jobs, configured via SHUTDOWN_GRACE_SECONDS
+func ShutdownGracePeriod() time.Duration {
+	seconds := services.GetEnvInt("SHUTDOWN_GRACE_SECONDS", defaultShutdownGraceSeconds)
+	if seconds < 0 {
+		seconds = defaultShutdownGraceSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// DrainWorkers stops accepting new jobs and waits until the workers have finished the jobs they are
+// running, or until ctx is done. Jobs still waiting in the queue are not started: they stay in the job
+// journal and are re-queued on the next start. Without a journal they are processed before the workers stop.
+func DrainWorkers(ctx context.Context) error {
+	jobQueueMutex.Lock()
+	if !jobQueueClosed {
+		jobQueueClosed = true
+		close(jobQueue)
+	}
+	jobQueueMutex.Unlock()
+	logInfo("Stopped accepting summarization jobs. Waiting for running jobs to finish...")
+
+	pool := workers
+	done := make(chan struct{})
+	go func() {
+		if pool != nil {
+			pool.wg.Wait()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		logInfo("All summarization workers have stopped.")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDraining reports whether DrainWorkers was called
+func isDraining() bool {
+	jobQueueMutex.RLock()
+	defer jobQueueMutex.RUnlock()
+	return jobQueueClosed
+}
+
+// CloseSummaryModule flushes the usage counters and stops the background cache sweep
+func CloseSummaryModule() {
+	if counterStore != nil {
+		if err := counterStore.Close(); err != nil {
+			logWarn("Failed to flush counters on shutdown: %v", err)
+		}
+	}
+	if summaryCache != nil {
+		summaryCache.Close()
+	}
+}
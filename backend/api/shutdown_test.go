@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifySSEShutdownNotifiesAndClosesClients(t *testing.T) {
+	setupWorkerTest(t)
+	t.Setenv("SSE_RECONNECT_DELAY_MS", "5000")
+	ch := subscribe("user1", testVideoID)
+
+	assert.Equal(t, 1, NotifySSEShutdown())
+
+	message := receive(ch)
+	assert.Contains(t, message, "event: server_shutdown\n")
+	assert.Contains(t, message, "retry: 5000\n")
+	assert.Contains(t, message, `"reconnectAfterMs":5000`)
+
+	_, open := <-ch
+	assert.False(t, open, "the stream is closed after the notification")
+	clientChannelsMutex.RLock()
+	assert.Empty(t, clientChannels)
+	clientChannelsMutex.RUnlock()
+
+	// Jobs that complete afterwards must not send on the closed channel
+	completeJob(SummarizationJob{VideoID: testVideoID, UserID: "user1"}, &SummaryResponse{VideoID: testVideoID}, nil, "")
+}
+
+func TestNotifySSEShutdownWhileWorkersSendMessages(t *testing.T) {
+	setupWorkerTest(t)
+	t.Setenv("SSE_SHUTDOWN_NOTIFY", "false")
+
+	// Jobs still running after the grace period keep sending summary_progress events while the
+	// channels are closed; a send on a closed channel would panic
+	for round := 0; round < 10; round++ {
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		for _, userID := range []string{"user1", "user2", "user3"} {
+			ch := subscribe(userID, testVideoID)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						sendSSEMessage(userID, []byte("event: summary_progress\ndata: {}\n\n"))
+					}
+				}
+			}()
+			// Shut down only once every worker is sending
+			for len(ch) == 0 {
+				time.Sleep(time.Microsecond)
+			}
+		}
+
+		NotifySSEShutdown()
+		close(stop)
+		wg.Wait()
+	}
+
+	clientChannelsMutex.RLock()
+	assert.Empty(t, clientChannels)
+	clientChannelsMutex.RUnlock()
+}
+
+func TestNotifySSEShutdownCanBeDisabled(t *testing.T) {
+	setupWorkerTest(t)
+	t.Setenv("SSE_SHUTDOWN_NOTIFY", "false")
+	ch := subscribe("user1", testVideoID)
+
+	assert.Equal(t, 0, NotifySSEShutdown())
+	_, open := <-ch
+	assert.False(t, open)
+}
+
+func TestDrainWorkersFinishesRunningJobsOnly(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue, originalWorkers := jobQueue, workers
+	t.Cleanup(func() {
+		jobQueue, workers = originalQueue, originalWorkers
+		jobQueueClosed = false
+		jobJournal = nil
+	})
+	jobQueue = make(chan SummarizationJob, 2)
+	assert.NoError(t, initJobJournal(t.TempDir()))
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		started <- job.VideoID
+		<-release
+		return &SummaryResponse{VideoID: job.VideoID}, nil
+	}
+	workers = startWorkerPool(1, jobQueue)
+
+	running := SummarizationJob{VideoID: "aaaaaaaaaaa", UserID: "user1"}
+	queued := SummarizationJob{VideoID: "bbbbbbbbbbb", UserID: "user1"}
+	for _, job := range []SummarizationJob{running, queued} {
+		assert.True(t, subscribeToJob(job.key(), job.UserID, ""))
+		assert.True(t, tryEnqueueJob(job))
+	}
+	assert.Equal(t, running.VideoID, <-started)
+
+	// The grace period ends while a job is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, DrainWorkers(ctx), context.DeadlineExceeded)
+	assert.False(t, tryEnqueueJob(SummarizationJob{VideoID: "ccccccccccc", UserID: "user1"}), "no new jobs while shutting down")
+
+	close(release)
+	assert.NoError(t, DrainWorkers(context.Background()))
+	assert.Empty(t, started, "queued jobs are not started while draining")
+
+	// The queued job is left in the journal for the next start
+	entries := jobJournal.Entries()
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries, queued.key())
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// defaultPendingResultTTLSeconds is how long a result waits for its user to reconnect
+const defaultPendingResultTTLSeconds = 300
+
+// maxPendingResultsPerUser stays below the SSE channel buffer, so all of them fit when the user connects
+const maxPendingResultsPerUser = 5
+
+// maxPendingResults bounds the memory used by results of users who never come back
+const maxPendingResults = 1000
+
+// pendingResult is the final event of a job whose subscriber had no SSE connection when it completed
+type pendingResult struct {
+	videoID string
+	message []byte
+	expires time.Time
+}
+
+var (
+	// pendingResults holds undelivered results by user ID until their next SSE connection.
+	// Guarded by clientChannelsMutex.
+	pendingResults      = make(map[string][]pendingResult)
+	pendingResultsCount int
+)
+
+// pendingResultTTL returns how long undelivered results are kept, configured via PENDING_RESULT_TTL_SECONDS.
+// A value of 0 disables keeping them.
+func pendingResultTTL() time.Duration {
+	seconds := services.GetEnvInt("PENDING_RESULT_TTL_SECONDS", defaultPendingResultTTLSeconds)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// sendSSEResult sends the final event of a job to a subscriber. If the subscriber isn't connected, e.g. because
+// the job finished before the page opened its SSE connection, the event is kept and sent when they connect.
+func sendSSEResult(userID, videoID string, message []byte) {
+	ttl := pendingResultTTL()
+	clientChannelsMutex.Lock()
+	defer clientChannelsMutex.Unlock()
+
+	if clientChan, ok := clientChannels[userID]; ok || ttl == 0 {
+		if ok {
+			select {
+			case clientChan <- message:
+				logDebug("Sent result of VideoID %s to UserID %s.", videoID, userID)
+			default:
+				logWarn("SSE channel for UserID %s is full. Result of VideoID %s dropped.", userID, videoID)
+			}
+		}
+		return
+	}
+
+	now := time.Now()
+	pending := dropExpiredResultsLocked(userID, now)
+	for i, result := range pending {
+		if result.videoID == videoID {
+			pending = append(pending[:i], pending[i+1:]...)
+			pendingResultsCount--
+			break
+		}
+	}
+	if len(pending) >= maxPendingResultsPerUser {
+		pending = pending[1:] // Drop the oldest
+		pendingResultsCount--
+	}
+	if pendingResultsCount >= maxPendingResults {
+		for user := range pendingResults {
+			dropExpiredResultsLocked(user, now)
+		}
+	}
+	if pendingResultsCount >= maxPendingResults {
+		logWarn("Too many undelivered results. Result of VideoID %s for UserID %s dropped.", videoID, userID)
+		pendingResults[userID] = pending
+		return
+	}
+
+	pendingResults[userID] = append(pending, pendingResult{videoID: videoID, message: message, expires: now.Add(ttl)})
+	pendingResultsCount++
+	logDebug("No active SSE channel for UserID %s. Keeping result of VideoID %s until they connect.", userID, videoID)
+}
+
+// flushPendingResultsLocked hands a newly connected user the results they missed. clientChannelsMutex must be held.
+func flushPendingResultsLocked(userID string, clientChan chan []byte) {
+	pending := dropExpiredResultsLocked(userID, time.Now())
+	delete(pendingResults, userID)
+	pendingResultsCount -= len(pending)
+
+	for _, result := range pending {
+		select {
+		case clientChan <- result.message:
+			logInfo("Delivered missed result of VideoID %s to UserID %s on connect.", result.videoID, userID)
+		default:
+			logWarn("SSE channel for UserID %s is full. Missed result of VideoID %s dropped.", userID, result.videoID)
+		}
+	}
+}
+
+// dropExpiredResultsLocked removes a user's expired results and returns the rest. clientChannelsMutex must be held.
+func dropExpiredResultsLocked(userID string, now time.Time) []pendingResult {
+	pending := pendingResults[userID]
+	kept := pending[:0]
+	for _, result := range pending {
+		if now.Before(result.expires) {
+			kept = append(kept, result)
+		}
+	}
+	pendingResultsCount -= len(pending) - len(kept)
+	if len(kept) == 0 {
+		delete(pendingResults, userID)
+		return nil
+	}
+	pendingResults[userID] = kept
+	return kept
+}
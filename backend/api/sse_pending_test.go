@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// connect registers an SSE channel for userID the way HandleSummaryEvents does
+func connect(userID string) chan []byte {
+	ch := make(chan []byte, 10)
+	clientChannelsMutex.Lock()
+	clientChannels[userID] = ch
+	flushPendingResultsLocked(userID, ch)
+	clientChannelsMutex.Unlock()
+	return ch
+}
+
+func TestResultIsDeliveredWhenSubscriberConnects(t *testing.T) {
+	setupWorkerTest(t)
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return &SummaryResponse{VideoID: job.VideoID}, nil
+	}
+
+	// The job completes before the user's page opened its SSE connection
+	activeVideoJobs[testVideoID] = []string{"user1"}
+	handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+
+	ch := connect("user1")
+	assert.Contains(t, receive(ch), "summary_complete")
+	assert.Empty(t, receive(ch))
+	assert.Empty(t, receive(connect("user1")), "a result is only delivered once")
+}
+
+func TestPendingResultsAreBounded(t *testing.T) {
+	setupWorkerTest(t)
+
+	for i := 0; i < maxPendingResultsPerUser+2; i++ {
+		sendSSEResult("user1", fmt.Sprintf("video%04d", i), []byte(fmt.Sprintf("result %d\n\n", i)))
+	}
+	sendSSEResult("user1", "video0006", []byte("result 6 again\n\n")) // Replaces the earlier result of the video
+
+	ch := connect("user1")
+	var received []string
+	for msg := receive(ch); msg != ""; msg = receive(ch) {
+		received = append(received, msg)
+	}
+	assert.Equal(t, []string{"result 2\n\n", "result 3\n\n", "result 4\n\n", "result 5\n\n", "result 6 again\n\n"}, received)
+	assert.Equal(t, 0, pendingResultsCount)
+
+	t.Setenv("PENDING_RESULT_TTL_SECONDS", "0")
+	sendSSEResult("user2", testVideoID, []byte("result\n\n"))
+	assert.Empty(t, receive(connect("user2")))
+}
@@ -1,18 +1,25 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"regexp"
 	"strconv"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
 	"github.com/akirose/youtube-summarizer/models"
 
 	"github.com/akirose/youtube-summarizer/services"
@@ -23,18 +30,126 @@ import (
 var clientChannels = make(map[string]chan []byte)
 var clientChannelsMutex = &sync.RWMutex{}
 
-// Global map for active video summarization jobs (VideoID -> list of UserIDs)
+// clientLanguages holds the message language of each connected client's Accept-Language, guarded by clientChannelsMutex
+var clientLanguages = make(map[string]string)
+
+// Global map for active video summarization jobs (job key -> list of UserIDs)
 var activeVideoJobs = make(map[string][]string)
 var activeVideoJobsMutex = &sync.RWMutex{}
 
+// Jobs waiting in jobQueue for a worker (job key -> time queued). Guarded by activeVideoJobsMutex.
+var queuedJobs = make(map[string]time.Time)
+
 // SummarizationJob defines the structure for a video summarization job
 type SummarizationJob struct {
 	VideoID  string
 	UserID   string
 	APIKey   string // User's API key, if provided
 	URL      string // Original URL, mainly for context if needed later
-	IsSSE    bool   // Flag to indicate if this job is for SSE
-	ClientID string // SSE Client ID
+	Language string // Requested summary language; empty means defaultSummaryLanguage
+
+	// Languages requests one summary per language from a single transcript fetch; overrides Language
+	Languages []string
+	IsSSE     bool   // Flag to indicate if this job is for SSE
+	ClientID  string // SSE Client ID
+
+	// Transcript holds transcript chunks that were already fetched before queuing, if any
+	Transcript [][]services.TranscriptItem
+
+	// ResumeChunks holds the chunk summaries of a cached partial result to continue from, if any
+	ResumeChunks []services.ChunkSummary
+
+	// Reprocess regenerates a cached summary from a better transcript instead of returning it
+	Reprocess bool
+
+	// Regenerate was requested by a user to replace a bad summary; identical transcripts are not reused
+	Regenerate bool
+
+	// Pinned holds the cache keys that were pinned before a regeneration discarded them, so their new
+	// summaries are pinned again
+	Pinned map[string]bool
+
+	Model       string   // Model chosen by the requester; only used with their own API key
+	Temperature *float64 // Temperature chosen by the requester, if any
+	Style       string   // Summary style; empty means services.DefaultSummaryStyle
+
+	RequestID    string            // ID of the summary request that created the job, for tracing
+	TraceCarrier map[string]string // Serialized trace context of the request handler span
+	EnqueuedAt   time.Time         // When the job was handed to the queue
+
+	// MessageLanguage is the language of the requester's client (Accept-Language), used for the error
+	// messages of the job's summary_error events; empty means i18n.DefaultLanguage
+	MessageLanguage string
+}
+
+// jobKey identifies the summaries a job produces. Active jobs are deduplicated by this key, so
+// requests for the same video only share a job (and its SSE result) when they ask for the same
+// variant. A single language maps to the summary's cache key, i.e. the plain video ID by default.
+func jobKey(videoID string, languages ...string) string {
+	languages = normalizeLanguages(languages)
+	if len(languages) == 1 {
+		return models.CacheKey(videoID, languages[0])
+	}
+	return videoID + "." + strings.Join(languages, "+")
+}
+
+// normalizeLanguages lowercases language codes and removes duplicates, keeping the order.
+// Invalid codes are dropped; requests are validated before they get here. No languages means the default one.
+func normalizeLanguages(languages []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, language := range languages {
+		language, err := services.NormalizeLanguage(language)
+		if err != nil || seen[language] {
+			continue
+		}
+		seen[language] = true
+		normalized = append(normalized, language)
+	}
+	if len(normalized) == 0 {
+		normalized = []string{defaultSummaryLanguage}
+	}
+	return normalized
+}
+
+// languages returns the normalized languages the job summarizes the video in
+func (job SummarizationJob) languages() []string {
+	if len(job.Languages) > 0 {
+		return normalizeLanguages(job.Languages)
+	}
+	return normalizeLanguages([]string{job.Language})
+}
+
+// key returns the dedup key of the job
+func (job SummarizationJob) key() string {
+	key := jobKey(job.VideoID, job.languages()...)
+	if style := job.style(); style != services.DefaultSummaryStyle {
+		// Requests for another style must not be deduplicated into this job
+		key += "~" + style
+	}
+	return key
+}
+
+// cacheKey returns the key a single-language job's summary is cached under; the style is stored on the item
+func (job SummarizationJob) cacheKey() string {
+	return models.CacheKey(job.VideoID, job.languages()[0])
+}
+
+// style returns the normalized summary style of the job
+func (job SummarizationJob) style() string {
+	style, err := services.NormalizeSummaryStyle(job.Style)
+	if err != nil {
+		return services.DefaultSummaryStyle
+	}
+	return style
+}
+
+// cacheStyle returns the style recorded on the job's cache items, which is empty for the default style
+func (job SummarizationJob) cacheStyle() string {
+	if style := job.style(); style != services.DefaultSummaryStyle {
+		return style
+	}
+	return ""
 }
 
 // Global job queue
@@ -43,9 +158,144 @@ var jobQueue chan SummarizationJob
 const defaultNumWorkers = 3
 const jobQueueCapacity = 100
 
+// defaultSSEHeartbeatSeconds stays below the ~30-60s idle timeout of common load balancers and proxies
+const defaultSSEHeartbeatSeconds = 25
+
+// sseKeepalive is an SSE comment line; EventSource clients ignore it
+var sseKeepalive = []byte(": keepalive\n\n")
+
+// defaultTranscriptChunkSeconds is the length of the transcript chunks summarized one at a time
+// when TRANSCRIPT_CHUNK_SECONDS is not set
+const defaultTranscriptChunkSeconds = 400
+
+// transcriptChunkSeconds returns the chunk length configured via TRANSCRIPT_CHUNK_SECONDS.
+// Dense talks need shorter chunks to fit the model context; sparse videos need fewer calls with longer ones.
+func transcriptChunkSeconds() float64 {
+	if seconds := services.GetEnvInt("TRANSCRIPT_CHUNK_SECONDS", defaultTranscriptChunkSeconds); seconds > 0 {
+		return float64(seconds)
+	}
+	return defaultTranscriptChunkSeconds
+}
+
+// videoChapters returns the chapters a video's transcript is chunked along and its summary is
+// headed with, if USE_VIDEO_CHAPTERS is enabled. Videos without chapters are chunked by time.
+func videoChapters(videoInfo *services.VideoInfo) []services.Chapter {
+	if videoInfo == nil || !services.GetEnvBool("USE_VIDEO_CHAPTERS", false) {
+		return nil
+	}
+	return videoInfo.Chapters
+}
+
+// errJobQueueFull is reported to subscribers when a job could not be handed to the worker pool
+var errJobQueueFull error = i18n.NewError(i18n.MsgJobQueueFull)
+
 // SummaryRequest represents the request for a video summary
 type SummaryRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL         string `json:"url" binding:"required"`
+	CallbackURL string `json:"callbackUrl,omitempty"` // Optional URL that receives the result via POST when the job completes
+
+	// Partial decides what happens when only a partial summary is cached:
+	// "accept" returns it as is, "continue" summarizes the missing chunks, "regenerate" (default) starts over
+	Partial string `json:"partial,omitempty"`
+
+	// Language is the language the summary is written in, e.g. "en" (default "ko").
+	// Timestamps keep the [MM:SS] format in every language.
+	Language string `json:"language,omitempty"`
+
+	// Languages optionally requests summaries in several languages at once (at most MAX_SUMMARY_LANGUAGES).
+	// The transcript is fetched once and summarized once per language.
+	Languages []string `json:"languages,omitempty"`
+
+	// Model optionally picks the OpenAI model. Only honored for users summarizing with their own API key.
+	Model string `json:"model,omitempty"`
+	// Temperature optionally sets the sampling temperature (0-2, default 0.2)
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Style optionally picks the summary format: "timestamped" (default), "tldr", "bullets" or "detailed"
+	Style string `json:"style,omitempty"`
+}
+
+// Values of SummaryRequest.Partial
+const (
+	partialRegenerate = "regenerate"
+	partialAccept     = "accept"
+	partialContinue   = "continue"
+)
+
+// partialCachedError marks a job error whose incomplete summary was stored in the cache
+type partialCachedError struct {
+	err error
+}
+
+func (e *partialCachedError) Error() string {
+	return e.err.Error() + " (summary incomplete, partial result cached)"
+}
+
+func (e *partialCachedError) Unwrap() error {
+	return e.err
+}
+
+// noTranscriptErrorCode is the error of a summary_error for a video without captions
+const (
+	noTranscriptErrorCode = "no_transcript"
+)
+
+// Stages a job can fail in. They are sent as the code of summary_error events, so clients can tell
+// e.g. an unavailable video from missing captions or an exhausted OpenAI quota.
+const (
+	jobStageVideoInfo  = "video_info"
+	jobStageTranscript = "transcript"
+	jobStageSummarize  = "summarize"
+	jobStageCache      = "cache"
+)
+
+// jobStageError marks a job error with the stage it happened in
+type jobStageError struct {
+	stage string
+	err   error
+}
+
+func (e *jobStageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *jobStageError) Unwrap() error {
+	return e.err
+}
+
+// jobErrorStage returns the stage a job error happened in, or "" for errors outside of the stages
+// such as a full queue
+func jobErrorStage(err error) string {
+	var stageErr *jobStageError
+	if errors.As(err, &stageErr) {
+		return stageErr.stage
+	}
+	return ""
+}
+
+// jobErrorPayload builds the summary_error payload sent to SSE subscribers and callbacks
+// Videos without captions get the distinct error code no_transcript, and videos over
+// MAX_VIDEO_DURATION_SECONDS video_too_long, so clients can tell them apart from transient failures.
+// Videos YouTube kept refusing with a bot check after all retries get bot_check.
+// The stage the job failed in is sent as code, if known.
+func jobErrorPayload(videoID string, jobErr error, lang string) gin.H {
+	payload := gin.H{"videoId": videoID, "error": i18n.Message(jobErr, lang)}
+	var tooLong *videoTooLongError
+	if errors.Is(jobErr, services.ErrNoTranscript) {
+		payload = gin.H{"videoId": videoID, "error": noTranscriptErrorCode, "message": i18n.T(i18n.MsgNoTranscript, lang)}
+	} else if errors.As(jobErr, &tooLong) {
+		payload = gin.H{"videoId": videoID, "error": videoTooLongErrorCode, "message": tooLong.Localize(lang)}
+	} else if errors.Is(jobErr, services.ErrBotCheck) {
+		payload = gin.H{"videoId": videoID, "error": botCheckErrorCode, "message": i18n.T(i18n.MsgBotCheck, lang)}
+	}
+	if stage := jobErrorStage(jobErr); stage != "" {
+		payload["code"] = stage
+	}
+	var partialErr *partialCachedError
+	if errors.As(jobErr, &partialErr) {
+		payload["partial"] = true
+	}
+	return payload
 }
 
 // SummaryResponse represents the response with the video summary
@@ -55,38 +305,239 @@ type SummaryResponse struct {
 	Summary    string                    `json:"summary"`
 	Timestamps []models.Timestamp        `json:"timestamps"`
 	Transcript []services.TranscriptItem `json:"transcript,omitempty"`
+	Chunks     []services.ChunkSummary   `json:"chunks,omitempty"` // Only set when STRUCTURED_OUTPUT is enabled
 	Cached     bool                      `json:"cached"`
+	Partial    bool                      `json:"partial,omitempty"`  // The summary is incomplete because generation was interrupted
+	Language   string                    `json:"language,omitempty"` // Language of the summary, if not the default one
+	Style      string                    `json:"style,omitempty"`    // Style of the summary, if not the default one
+	Model      string                    `json:"model,omitempty"`    // Model that generated the summary
+	Category   string                    `json:"category,omitempty"` // Topic the video was classified into (ENABLE_CATEGORIZATION)
+
+	// Video metadata from yt-dlp, for listings. Empty for summaries cached before it was stored.
+	Channel    string `json:"channel,omitempty"`
+	UploadDate string `json:"uploadDate,omitempty"` // YYYYMMDD
+	Duration   int    `json:"duration,omitempty"`   // Seconds
+	Thumbnail  string `json:"thumbnail,omitempty"`  // Thumbnail image URL
+
+	// Reprocessed is set when the summary replaces one generated from incomplete captions (REPROCESS_LOW_COVERAGE)
+	Reprocessed bool `json:"reprocessed,omitempty"`
+
+	// Summaries maps each language to its summary when several languages were requested.
+	// Summary then holds the summary in the first requested language.
+	Summaries map[string]string `json:"summaries,omitempty"`
+
+	// Coverage is the percentage of the video covered by the transcript the summary is based on
+	Coverage    float64 `json:"coverage,omitempty"`
+	LowCoverage bool    `json:"lowCoverage,omitempty"` // Coverage is below LOW_COVERAGE_THRESHOLD
+
+	// Usage and CostUSD report what generating the summary cost, unless EXPOSE_SUMMARY_COST is disabled.
+	// CostUSD is left out for models without an OPENAI_MODEL_PRICING entry.
+	Usage   *services.TokenUsage `json:"usage,omitempty"`
+	CostUSD float64              `json:"costUsd,omitempty"`
+}
+
+// setVideoMetadata copies the video metadata stored with a summary to the response
+func (r *SummaryResponse) setVideoMetadata(item *models.CacheItem) {
+	r.Channel = item.Channel
+	r.UploadDate = item.UploadDate
+	r.Duration = item.Duration
+	r.Thumbnail = item.Thumbnail
+}
+
+// defaultLowCoverageThreshold is the coverage percentage below which a summary is flagged as based on incomplete captions
+const defaultLowCoverageThreshold = 60
+
+// setCoverage reports the transcript coverage on a response unless REPORT_TRANSCRIPT_COVERAGE is disabled.
+func (r *SummaryResponse) setCoverage(coverage float64) {
+	if coverage <= 0 || !services.GetEnvBool("REPORT_TRANSCRIPT_COVERAGE", true) {
+		return
+	}
+	r.Coverage = coverage
+	r.LowCoverage = isLowCoverage(coverage)
+}
+
+// setCost reports a cached item's token usage and estimated cost on a response unless EXPOSE_SUMMARY_COST is disabled.
+func (r *SummaryResponse) setCost(item *models.CacheItem) {
+	if !services.GetEnvBool("EXPOSE_SUMMARY_COST", true) {
+		return
+	}
+	r.Usage = item.Usage
+	r.CostUSD = item.CostUSD
+}
+
+// isLowCoverage reports whether a known coverage percentage is below LOW_COVERAGE_THRESHOLD.
+func isLowCoverage(coverage float64) bool {
+	return coverage > 0 && coverage < float64(services.GetEnvInt("LOW_COVERAGE_THRESHOLD", defaultLowCoverageThreshold))
+}
+
+// structuredOutputEnabled reports whether per-chunk summaries should be kept and returned.
+func structuredOutputEnabled() bool {
+	return services.GetEnvBool("STRUCTURED_OUTPUT", false)
+}
+
+// newCachedSummaryResponse builds the response for a summary served from the cache.
+func newCachedSummaryResponse(item *models.CacheItem, transcript []services.TranscriptItem) *SummaryResponse {
+	resp := &SummaryResponse{
+		VideoID:    item.VideoID,
+		Title:      item.Title,
+		Summary:    item.Summary,
+		Timestamps: item.Timestamps,
+		Transcript: MergeTranscript(transcript),
+		Cached:     true,
+		Partial:    item.Partial,
+		Language:   item.Language,
+		Style:      item.Style,
+		Model:      item.Model,
+		Category:   item.Category,
+	}
+	if resp.Timestamps == nil {
+		resp.Timestamps = summaryTimestamps(item.Summary) // Cached before timestamps were stored
+	}
+	resp.setVideoMetadata(item)
+	if structuredOutputEnabled() {
+		resp.Chunks = item.Chunks
+	}
+	resp.setCoverage(item.Coverage)
+	resp.setCost(item)
+	return resp
 }
 
 // Global cache instance
 var summaryCache *models.SummaryCache
 
+// defaultCacheSweepMinutes is how often expired cache items are removed when CACHE_SWEEP_INTERVAL_MINUTES is not set
+const defaultCacheSweepMinutes = 60
+
+// cacheDirectory returns CACHE_DIR, or the "cache" directory in the current working directory
+func cacheDirectory() (string, error) {
+	if cacheDir := os.Getenv("CACHE_DIR"); cacheDir != "" {
+		return cacheDir, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, "cache"), nil
+}
+
 // InitCache initializes the summary cache
 func InitCache() error {
 	// Get cache directory
-	cacheDir := os.Getenv("CACHE_DIR")
-	if cacheDir == "" {
-		// Default to "cache" directory in the current working directory
-		cwd, err := os.Getwd()
+	cacheDir, err := cacheDirectory()
+	if err != nil {
+		return err
+	}
+
+	var opts models.CacheOptions
+	if hours := services.GetEnvInt("CACHE_TTL_HOURS", 0); hours > 0 {
+		opts.TTL = time.Duration(hours) * time.Hour
+		logInfo("Cached summaries expire after %d hour(s).", hours)
+	}
+	opts.SweepInterval = time.Duration(services.GetEnvInt("CACHE_SWEEP_INTERVAL_MINUTES", defaultCacheSweepMinutes)) * time.Minute
+	if overrides := os.Getenv("CHANNEL_TTL_OVERRIDES"); overrides != "" {
+		channelTTLs, err := models.ParseChannelTTLOverrides(overrides)
 		if err != nil {
-			return err
+			logWarn("Ignoring CHANNEL_TTL_OVERRIDES: %v", err)
+		} else {
+			opts.ChannelTTLs = channelTTLs
+			logInfo("Loaded cache TTL overrides for %d channel(s).", len(channelTTLs))
 		}
-		cacheDir = filepath.Join(cwd, "cache")
+	}
+
+	if maxBytes := services.GetEnvInt("CACHE_MAX_MEMORY_BYTES", 0); maxBytes > 0 {
+		opts.MaxMemoryBytes = int64(maxBytes)
+		logInfo("Limiting in-memory cache to %d bytes; evicted items are reloaded from disk.", maxBytes)
+	}
+	if maxEntries := services.GetEnvInt("CACHE_MAX_ENTRIES", 0); maxEntries > 0 {
+		opts.MaxEntries = maxEntries
+		logInfo("Limiting cache to %d entries; least recently accessed summaries are deleted.", maxEntries)
+	}
+
+	if opts.LazyLoad = services.GetEnvBool("CACHE_LAZY_LOAD", false); opts.LazyLoad {
+		logInfo("Reading cached summaries from disk on first access.")
+	}
+	if opts.Sharded = services.GetEnvBool("CACHE_SHARDING", false); opts.Sharded {
+		logInfo("Storing cached summaries in subdirectories by video ID prefix.")
 	}
 
 	// Create cache
+	summaryCache, err = models.NewSummaryCacheWithOptions(cacheDir, opts)
+	return err
+}
+
+// counterStore persists usage counters such as the number of generated summaries
+var counterStore *models.CounterStore
+
+// Counter names in counterStore
+const counterSummariesTotal = "summaries_total"
+
+// defaultCounterFlushSeconds is how often counters are written to disk when COUNTER_FLUSH_INTERVAL_SECONDS is not set
+const defaultCounterFlushSeconds = 30
+
+// InitCounters loads the persisted counters from COUNTERS_FILE
+func InitCounters() error {
+	path := os.Getenv("COUNTERS_FILE")
+	if path == "" {
+		path = filepath.Join("stats", "counters.json")
+	}
+	interval := time.Duration(services.GetEnvInt("COUNTER_FLUSH_INTERVAL_SECONDS", defaultCounterFlushSeconds)) * time.Second
+
 	var err error
-	summaryCache, err = models.NewSummaryCache(cacheDir)
+	counterStore, err = models.NewCounterStore(path, interval)
 	return err
 }
 
+// incrementCounter adds one to a named counter, if counters are initialized
+func incrementCounter(name string) {
+	if counterStore != nil {
+		counterStore.Increment(name, 1)
+	}
+}
+
+// redactProxyURL hides the password of a proxy URL for logging
+func redactProxyURL(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
+
 // InitSummaryModule은 요약 기능과 관련된 모든 초기화 작업을 수행합니다.
 func InitSummaryModule() error {
+	// 자막 목록 조회 속도 제한 설정
+	initCaptionsLimiter()
+
+	// yt-dlp 추출 전략 확인용 로그
+	if extractorArgs := services.YtDlpExtractorArgs(); extractorArgs != "" {
+		logInfo("yt-dlp extractor args: %s", extractorArgs)
+	} else {
+		logInfo("yt-dlp extractor args: default")
+	}
+	// 연령 제한/지역 제한 영상용 프록시와 쿠키 파일 확인
+	if err := services.ValidateYtDlpOptions(); err != nil {
+		return err
+	}
+	if proxy := services.YtDlpProxy(); proxy != "" {
+		logInfo("yt-dlp proxy: %s", redactProxyURL(proxy))
+	}
+	if cookiesFile := services.YtDlpCookiesFile(); cookiesFile != "" {
+		logInfo("yt-dlp cookies file: %s", cookiesFile)
+	}
+
 	// 캐시 초기화
 	if err := InitCache(); err != nil {
 		return err
 	}
 
+	// 사용량 카운터 초기화
+	if err := InitCounters(); err != nil {
+		return err
+	}
+
+	// 사용자별 서버 키 사용량 한도 설정 (카운터에 기록)
+	initServerKeyQuota()
+
 	// 사용자 요약 디렉토리 초기화
 	if err := models.InitUserSummaryDirectory(); err != nil {
 		return err
@@ -95,142 +546,242 @@ func InitSummaryModule() error {
 	// Initialize job queue
 	jobQueue = make(chan SummarizationJob, jobQueueCapacity)
 
+	// Prometheus 메트릭 등록
+	registerMetrics()
+
 	// Initialize SSE client channels map
 	clientChannels = make(map[string]chan []byte)
+	clientLanguages = make(map[string]string)
+	pendingResults = make(map[string][]pendingResult)
+	pendingResultsCount = 0
 
 	// Initialize active video jobs map
 	activeVideoJobs = make(map[string][]string)
+	queuedJobs = make(map[string]time.Time)
+	jobCallbacks = make(map[string][]jobCallback)
+	journaledJobs = make(map[string]SummarizationJob)
+	runningJobs = make(map[string]*runningJob)
+	abandonedJobs = make(map[abandonedJob]struct{})
 
 	// Start worker pool
 	numWorkersStr := os.Getenv("NUM_SUMMARY_WORKERS")
 	numWorkers, err := strconv.Atoi(numWorkersStr)
 	if err != nil || numWorkers <= 0 {
-		log.Printf("Warning: Invalid or missing NUM_SUMMARY_WORKERS environment variable ('%s'). Defaulting to %d workers.", numWorkersStr, defaultNumWorkers)
+		logWarn("Invalid or missing NUM_SUMMARY_WORKERS environment variable ('%s'). Defaulting to %d workers.", numWorkersStr, defaultNumWorkers)
 		numWorkers = defaultNumWorkers
 	}
-	startWorkerPool(numWorkers, jobQueue) // Assuming startWorkerPool has its own "Worker X starting" logs
-	log.Printf("Info: Summarization worker pool configured with %d workers. Job queue capacity: %d.", numWorkers, jobQueueCapacity)
+	workers = startWorkerPool(numWorkers, jobQueue)
+	logInfo("Summarization worker pool configured with %d workers. Job queue capacity: %d.", numWorkers, jobQueueCapacity)
+
+	// 재시작 전에 처리되지 않은 작업 복구
+	cacheDir, err := cacheDirectory()
+	if err != nil {
+		return err
+	}
+	if err := initJobJournal(cacheDir); err != nil {
+		return err
+	}
+
+	// 자막 커버리지가 낮았던 요약 재처리
+	startCoverageReprocessing()
 
 	return nil
 }
 
-// startWorkerPool launches worker goroutines.
-func startWorkerPool(numWorkers int, queue chan SummarizationJob) {
-	for i := 0; i < numWorkers; i++ {
-		go func(workerID int) {
-			log.Printf("Info: Worker %d starting.", workerID)
-			// Outer defer for the worker goroutine itself
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("Error: Worker %d encountered a critical panic: %v. Worker is stopping.", workerID, r)
-					// In a production system, consider metrics/alerting for this.
-				} else {
-					log.Printf("Info: Worker %d stopping.", workerID)
-				}
-			}()
-
-			for job := range queue {
-				// Inner func and defer/recover for per-job panic safety
-				func(currentJob SummarizationJob) {
-					defer func() {
-						if r := recover(); r != nil {
-							log.Printf("Error: Worker %d: Panic during processing of VideoID: %s, UserID: %s. Panic: %v", workerID, currentJob.VideoID, currentJob.UserID, r)
-							// Notify subscribers of the error due to panic
-							errorData := gin.H{"videoId": currentJob.VideoID, "error": "Server error during summarization."}
-							jsonData, _ := json.Marshal(errorData) // Error here is unlikely
-							sseMessage := []byte(fmt.Sprintf("event: summary_error\ndata: %s\n\n", string(jsonData)))
-
-							activeVideoJobsMutex.Lock()
-							subscribers, ok := activeVideoJobs[currentJob.VideoID]
-							if ok {
-								log.Printf("DebugWorkerPanic: Worker %d: Deleting activeVideoJobs[%s] in panic recovery. Subscribers count: %d.", workerID, currentJob.VideoID, len(subscribers)) // New Log
-								delete(activeVideoJobs, currentJob.VideoID)                                                                                                                       // Clean up active job
-							}
-							activeVideoJobsMutex.Unlock()
-
-							for _, subscriberUserID := range subscribers {
-								sendSSEMessage(subscriberUserID, sseMessage)
-							}
-						}
-					}()
-
-					log.Printf("Info: Worker %d: Picked up job for VideoID: %s (Original UserID: %s)", workerID, currentJob.VideoID, currentJob.UserID)
-					summaryResp, err := processSummarizationJob(currentJob)
-
-					// After processing, get all subscribed users for this videoID
-					activeVideoJobsMutex.Lock()
-					subscribers, ok := activeVideoJobs[job.VideoID]
-					if ok {
-						delete(activeVideoJobs, job.VideoID) // Remove job from active list
-					}
-					activeVideoJobsMutex.Unlock()
-
-					// activeVideoJobsMutex.Lock()
-					// subscribers, subscribersFound := activeVideoJobs[currentJob.VideoID]
-					// if subscribersFound {
-					// 	log.Printf("DebugWorkerNormal: Worker %d: Deleting activeVideoJobs[%s]. Subscribers count: %d.", workerID, currentJob.VideoID, len(subscribers)) // New Log
-					// 	delete(activeVideoJobs, currentJob.VideoID)
-					// }
-					// activeVideoJobsMutex.Unlock()
-
-					if !ok && err == nil {
-						log.Printf("Warning: Worker %d: No subscribers found for VideoID: %s (Original UserID: %s) after processing. This might indicate a state issue or race condition if the job was meant to have subscribers.", workerID, currentJob.VideoID, currentJob.UserID)
-					}
+// processJob runs a summarization job. Tests replace it to control the outcome of handleJob.
+var processJob = processSummarizationJob
 
-					for _, subscriberUserID := range subscribers {
-						if err != nil {
-							log.Printf("Info: Worker %d: Notifying subscriber %s of error for VideoID %s. Error: %v", workerID, subscriberUserID, currentJob.VideoID, err)
-							errorData := gin.H{"videoId": currentJob.VideoID, "error": err.Error()}
-							jsonData, _ := json.Marshal(errorData)
-							sseMessage := []byte(fmt.Sprintf("event: summary_error\ndata: %s\n\n", string(jsonData)))
-							sendSSEMessage(subscriberUserID, sseMessage)
-						} else if summaryResp != nil {
-							log.Printf("Info: Worker %d: Notifying subscriber %s of success for VideoID %s.", workerID, subscriberUserID, currentJob.VideoID)
-							jsonData, jsonErr := json.Marshal(summaryResp)
-							if jsonErr != nil {
-								log.Printf("Error: Worker %d: Failed to marshal summary response for SSE (Subscriber: %s, VideoID: %s): %v", workerID, subscriberUserID, currentJob.VideoID, jsonErr)
-								errorData := gin.H{"videoId": currentJob.VideoID, "error": "Internal server error: Failed to serialize summary data."}
-								errorJson, _ := json.Marshal(errorData)
-								sseMessage := []byte(fmt.Sprintf("event: summary_error\ndata: %s\n\n", string(errorJson)))
-								sendSSEMessage(subscriberUserID, sseMessage)
-							} else {
-								sseMessage := []byte(fmt.Sprintf("event: summary_complete\ndata: %s\n\n", string(jsonData)))
-								sendSSEMessage(subscriberUserID, sseMessage)
-							}
-						}
-					}
-					if err != nil {
-						log.Printf("Info: Worker %d: Finished job for VideoID: %s (Original UserID: %s) with error: %v", workerID, currentJob.VideoID, currentJob.UserID, err)
-					} else {
-						log.Printf("Info: Worker %d: Finished job successfully for VideoID: %s (Original UserID: %s)", workerID, currentJob.VideoID, currentJob.UserID)
-					}
-				}(job) // Pass job as an argument to the inner func
-			}
-		}(i + 1)
+// handleJob processes a single job picked up by a worker and notifies its subscribers.
+// A panic while processing is recovered and reported to the subscribers as an error, so one bad
+// job neither kills the worker nor leaves the video stuck in activeVideoJobs.
+// It returns the subscribers that were notified.
+func handleJob(workerID int, job SummarizationJob) (notified []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logError("Worker %d: Panic during processing of VideoID: %s, UserID: %s. Panic: %v", workerID, job.VideoID, job.UserID, r)
+			// Notify subscribers of the error due to panic and clean up the active job
+			notified, _ = completeJob(job, nil, i18n.NewError(i18n.MsgSummarizationFailed), "")
+		}
+	}()
+
+	logDebug("Worker %d: Picked up job for VideoID: %s (Original UserID: %s)", workerID, job.VideoID, job.UserID)
+	ctx, finish, ok := startRunningJob(jobContext(job), job)
+	if !ok {
+		logInfo("Worker %d: Skipping job for VideoID: %s (Original UserID: %s), nobody is waiting for it anymore.", workerID, job.VideoID, job.UserID)
+		return nil
+	}
+	defer finish()
+	recordQueueWait(ctx, job)
+	ctx, span := services.StartSpan(ctx, "process job", services.VideoIDAttr(job.VideoID), services.RequestIDAttr(job.RequestID))
+	busyWorkers.Inc()
+	start := time.Now()
+	summaryResp, err := processJob(ctx, job)
+	recordJobDuration(start, err)
+	busyWorkers.Dec()
+	services.EndSpan(span, err)
+
+	if errors.Is(context.Cause(ctx), errJobAbandoned) {
+		// Already unregistered; a newer job for the same key may have subscribers that must not get this error
+		logInfo("Worker %d: Cancelled job for VideoID: %s (Original UserID: %s), all subscribers disconnected.", workerID, job.VideoID, job.UserID)
+		return nil
+	}
+
+	notified, found := completeJob(job, summaryResp, err, "")
+	if !found && err == nil {
+		logWarn("Worker %d: No subscribers found for VideoID: %s (Original UserID: %s) after processing. This might indicate a state issue or race condition if the job was meant to have subscribers.", workerID, job.VideoID, job.UserID)
+	}
+
+	if err != nil {
+		logInfo("Worker %d: Finished job for VideoID: %s (Original UserID: %s) with error: %v", workerID, job.VideoID, job.UserID, err)
+	} else {
+		logDebug("Worker %d: Finished job successfully for VideoID: %s (Original UserID: %s)", workerID, job.VideoID, job.UserID)
+	}
+	return notified
+}
+
+// completeJob removes a finished job from activeVideoJobs and notifies every subscriber of the
+// result via SSE. skipUserID, if set, is left out of the notifications because it already
+// received the result directly (e.g. in a synchronous HTTP response).
+// It returns the subscribers that were registered and whether the job was still active.
+func completeJob(job SummarizationJob, summaryResp *SummaryResponse, jobErr error, skipUserID string) ([]string, bool) {
+	key := job.key()
+	activeVideoJobsMutex.Lock()
+	subscribers, ok := activeVideoJobs[key]
+	if ok {
+		delete(activeVideoJobs, key) // Remove job from active list
+	}
+	callbacks := jobCallbacks[key]
+	delete(jobCallbacks, key)
+	activeVideoJobsMutex.Unlock()
+	unjournalJob(key)
+	releaseServerKeySummary(key)
+
+	var pendingCallbacks []jobCallback
+	for _, cb := range callbacks {
+		if cb.UserID != skipUserID {
+			pendingCallbacks = append(pendingCallbacks, cb)
+		}
+	}
+	deliverCallbacks(pendingCallbacks, job.VideoID, summaryResp, jobErr)
+
+	var sseMessage []byte
+	if jobErr != nil {
+		errorData := jobErrorPayload(job.VideoID, jobErr, job.MessageLanguage)
+		jsonData, _ := json.Marshal(errorData) // Error here is unlikely
+		sseMessage = []byte(fmt.Sprintf("event: summary_error\ndata: %s\n\n", string(jsonData)))
+	} else if summaryResp != nil {
+		jsonData, jsonErr := json.Marshal(summaryResp)
+		if jsonErr != nil {
+			logError("Failed to marshal summary response for SSE (VideoID: %s): %v", job.VideoID, jsonErr)
+			errorData := gin.H{"videoId": job.VideoID, "error": i18n.T(i18n.MsgSerializeFailed, job.MessageLanguage)}
+			errorJson, _ := json.Marshal(errorData)
+			sseMessage = []byte(fmt.Sprintf("event: summary_error\ndata: %s\n\n", string(errorJson)))
+		} else {
+			sseMessage = []byte(fmt.Sprintf("event: summary_complete\ndata: %s\n\n", string(jsonData)))
+		}
+	}
+
+	if sseMessage == nil {
+		return subscribers, ok
+	}
+
+	for _, subscriberUserID := range subscribers {
+		if subscriberUserID == skipUserID {
+			continue
+		}
+		if jobErr != nil {
+			logDebug("Notifying subscriber %s of error for VideoID %s. Error: %v", subscriberUserID, job.VideoID, jobErr)
+		} else {
+			logDebug("Notifying subscriber %s of success for VideoID %s.", subscriberUserID, job.VideoID)
+		}
+		sendSSEResult(subscriberUserID, job.VideoID, sseMessage)
 	}
+
+	return subscribers, ok
 }
 
 // sendSSEMessage sends a message to a specific user's SSE channel if it exists.
-// It is non-blocking to prevent workers from getting stuck.
+// It is non-blocking to prevent workers from getting stuck. The send happens under clientChannelsMutex,
+// since channels are closed under its write lock (reconnects, NotifySSEShutdown).
 func sendSSEMessage(userID string, message []byte) {
-	clientChannelsMutex.RLock()
-	clientChan, ok := clientChannels[userID]
-	clientChannelsMutex.RUnlock()
-
 	msgPreview := string(message)
 	if len(msgPreview) > 100 { // Limit preview length
 		msgPreview = msgPreview[:100] + "..."
 	}
 
+	clientChannelsMutex.RLock()
+	defer clientChannelsMutex.RUnlock()
+
+	clientChan, ok := clientChannels[userID]
 	if ok {
 		select {
 		case clientChan <- message:
-			log.Printf("Info: Sent SSE message to UserID %s (preview: %s)", userID, msgPreview)
+			logDebug("Sent SSE message to UserID %s (preview: %s)", userID, msgPreview)
 		default:
-			log.Printf("Warning: SSE channel for UserID %s is full. Message dropped (preview: %s)", userID, msgPreview)
+			logWarn("SSE channel for UserID %s is full. Message dropped (preview: %s)", userID, msgPreview)
 		}
 	} else {
-		log.Printf("Info: No active SSE channel for UserID %s. Message not sent (preview: %s)", userID, msgPreview)
+		logDebug("No active SSE channel for UserID %s. Message not sent (preview: %s)", userID, msgPreview)
+	}
+}
+
+// summarizeOptions returns the options a job's summary in language is generated with.
+// progressLanguage is reported in summary_progress events (see jobProgressNotifier).
+func summarizeOptions(job SummarizationJob, language, progressLanguage string) services.SummarizeOptions {
+	opts := services.SummarizeOptions{
+		Language:    language,
+		Style:       job.style(),
+		Temperature: job.Temperature,
+		OnChunk:     jobProgressNotifier(job, progressLanguage),
+	}
+	if job.APIKey != "" {
+		// Checked again here because restored jobs lose their API key and would fall back to the server key
+		opts.Model = job.Model
+	}
+	return opts
+}
+
+// summaryProgressEvent is the data of a summary_progress SSE event
+type summaryProgressEvent struct {
+	VideoID     string  `json:"videoId"`
+	Language    string  `json:"language,omitempty"`
+	Chunk       int     `json:"chunk"`       // 1-based number of the chunk just summarized
+	TotalChunks int     `json:"totalChunks"` // Number of chunks of the video
+	Progress    float64 `json:"progress"`    // Chunk / TotalChunks
+	Text        string  `json:"text"`        // Summary of the chunk
+	Summary     string  `json:"summary"`     // Summary of all chunks so far
+}
+
+// jobProgressNotifier returns a services.SummarizeOptions.OnChunk callback that sends a
+// summary_progress event with the summary so far to every current subscriber of the job.
+// language is set for jobs summarizing several languages, so clients can tell the summaries apart.
+func jobProgressNotifier(job SummarizationJob, language string) func(services.ChunkProgress) {
+	key := job.key()
+	return func(progress services.ChunkProgress) {
+		activeVideoJobsMutex.RLock()
+		subscribers := append([]string(nil), activeVideoJobs[key]...)
+		activeVideoJobsMutex.RUnlock()
+		if len(subscribers) == 0 {
+			return
+		}
+
+		jsonData, err := json.Marshal(summaryProgressEvent{
+			VideoID:     job.VideoID,
+			Language:    language,
+			Chunk:       progress.Chunk,
+			TotalChunks: progress.Total,
+			Progress:    float64(progress.Chunk) / float64(progress.Total),
+			Text:        progress.Text,
+			Summary:     progress.Summary,
+		})
+		if err != nil {
+			logWarn("Failed to marshal summary progress for SSE (VideoID: %s): %v", job.VideoID, err)
+			return
+		}
+		message := []byte(fmt.Sprintf("event: summary_progress\ndata: %s\n\n", string(jsonData)))
+		for _, userID := range subscribers {
+			sendSSEMessage(userID, message)
+		}
 	}
 }
 
@@ -242,291 +793,1224 @@ func min(a, b int) int {
 }
 
 // processSummarizationJob handles the actual video summarization.
-func processSummarizationJob(job SummarizationJob) (*SummaryResponse, error) {
-	log.Printf("Info: Worker: Processing job for VideoID: %s (Original UserID: %s)", job.VideoID, job.UserID)
+func processSummarizationJob(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+	logDebug("Worker: Processing job for VideoID: %s (Original UserID: %s)", job.VideoID, job.UserID)
+
+	languages := job.languages()
+	if len(languages) > 1 {
+		return processMultiLanguageJob(ctx, job, languages)
+	}
+	language := languages[0]
 
 	// This initial cache check can be useful if a job was queued, but by the time a worker picks it up,
 	// another worker (or a direct request for the same video) has already populated the cache.
+	var previous *models.CacheItem
 	if summaryCache != nil {
-		if cachedItem, found := summaryCache.Get(job.VideoID); found {
-			log.Printf("Info: Worker: VideoID %s (Original UserID: %s) found in cache by worker. Ensuring user summary and returning.", job.VideoID, job.UserID)
+		cachedItem, found := summaryCache.Get(job.cacheKey())
+		if job.Reprocess {
+			previous, cachedItem, found = cachedItem, nil, false
+		} else if found && cachedItem.SummaryStyle() != job.style() {
+			found = false // Summarized in another style; regenerated and replaced below
+		}
+		recordCacheLookup(cacheSourceWorker, found && !cachedItem.Partial)
+		if found && !cachedItem.Partial {
+			logDebug("Worker: VideoID %s (Original UserID: %s) found in cache by worker. Ensuring user summary and returning.", job.VideoID, job.UserID)
 			// Ensure user summary is recorded for the *original* requester of this job.
 			if err := models.AddUserSummary(job.UserID, job.VideoID, cachedItem.Title); err != nil {
-				log.Printf("Warning: Worker: VideoID %s, UserID %s: Error adding user summary in worker (cache hit scenario): %v", job.VideoID, job.UserID, err)
+				logWarn("Worker: VideoID %s, UserID %s: Error adding user summary in worker (cache hit scenario): %v", job.VideoID, job.UserID, err)
 			}
 
 			var transcriptToReturn []services.TranscriptItem = cachedItem.Transcript
 			if len(transcriptToReturn) == 0 {
-				freshChunks, errTr := services.GetTranscript(job.VideoID, 0)
+				freshChunks, errTr := services.GetTranscript(ctx, job.VideoID, 0)
 				if errTr == nil && len(freshChunks) > 0 {
 					transcriptToReturn = freshChunks[0]
-					if cacheErr := summaryCache.Set(job.VideoID, cachedItem.Title, cachedItem.Summary, cachedItem.Timestamps, transcriptToReturn); cacheErr != nil {
-						log.Printf("Warning: Worker: VideoID %s: Failed to update cache with transcript (worker cache hit): %v", job.VideoID, cacheErr)
+					updatedItem := *cachedItem
+					updatedItem.Transcript = transcriptToReturn
+					if cacheErr := summaryCache.SetItem(&updatedItem); cacheErr != nil {
+						logWarn("Worker: VideoID %s: Failed to update cache with transcript (worker cache hit): %v", job.VideoID, cacheErr)
 					}
 				} else if errTr != nil {
-					log.Printf("Warning: Worker: VideoID %s: Failed to fetch transcript in worker (cache hit, transcript miss): %v", job.VideoID, errTr)
+					logWarn("Worker: VideoID %s: Failed to fetch transcript in worker (cache hit, transcript miss): %v", job.VideoID, errTr)
 				}
 			}
-			return &SummaryResponse{
-				VideoID:    job.VideoID,
-				Title:      cachedItem.Title,
-				Summary:    cachedItem.Summary,
-				Timestamps: cachedItem.Timestamps,
-				Transcript: MergeTranscript(transcriptToReturn),
-				Cached:     true, // Indicate it was served from cache by the worker.
-			}, nil
+			// Indicate it was served from cache by the worker.
+			return newCachedSummaryResponse(cachedItem, transcriptToReturn), nil
 		}
 	}
 
-	videoInfo, err := services.GetVideoInfo(job.VideoID)
+	videoInfo, chunks, transcriptItems, err := fetchJobTranscript(ctx, job)
 	if err != nil {
-		log.Printf("Error: Worker: VideoID %s, UserID %s: Failed to get video info: %v", job.VideoID, job.UserID, err)
-		return nil, fmt.Errorf("failed to get video info for VideoID %s: %w", job.VideoID, err)
+		return nil, err
 	}
 
-	chunks, err := services.GetTranscript(job.VideoID, 400.0)
-	if err != nil {
-		log.Printf("Error: Worker: VideoID %s, UserID %s: Failed to get video transcript: %v", job.VideoID, job.UserID, err)
-		return nil, fmt.Errorf("failed to get transcript for VideoID %s: %w", job.VideoID, err)
+	if reused := reuseSummaryByTranscript(job, videoInfo, transcriptItems, language); reused != nil {
+		return newCachedSummaryResponse(reused, transcriptItems), nil
 	}
 
-	summaryText, err := services.SummarizeChunks(chunks, job.APIKey, job.UserID)
+	opts := summarizeOptions(job, language, "")
+	opts.Chapters = videoChapters(videoInfo)
+	summaryResult, err := services.SummarizeChunksFrom(ctx, chunks, job.ResumeChunks, opts, job.APIKey, job.UserID)
 	if err != nil {
-		log.Printf("Error: Worker: VideoID %s, UserID %s: Failed to summarize transcript chunks: %v", job.VideoID, job.UserID, err)
-		return nil, fmt.Errorf("failed to summarize transcript for VideoID %s: %w", job.VideoID, err)
-	}
+		logError("Worker: VideoID %s, UserID %s: Failed to summarize transcript chunks: %v", job.VideoID, job.UserID, err)
+		err = &jobStageError{stage: jobStageSummarize, err: fmt.Errorf("failed to summarize transcript for VideoID %s: %w", job.VideoID, err)}
 
-	var transcriptItems []services.TranscriptItem
-	if len(chunks) > 0 {
-		for _, chunk := range chunks {
-			transcriptItems = append(transcriptItems, chunk...)
+		var partialErr *services.PartialSummaryError
+		if errors.As(err, &partialErr) {
+			recordSummaryUsage(job, language, partialErr.Partial.Model, partialErr.Partial.Usage, true)
 		}
-		services.SortTranscriptItemsByTime(transcriptItems)
+		if errors.As(err, &partialErr) && summaryCache != nil && services.GetEnvBool("CACHE_PARTIAL_ON_STREAM_ERROR", false) {
+			partialItem := newSummaryCacheItem(job.VideoID, videoInfo, partialErr.Partial, transcriptItems)
+			partialItem.Language = language
+			partialItem.Style = job.cacheStyle()
+			partialItem.Partial = true
+			partialItem.Chunks = partialErr.Partial.Chunks // Always kept so the summary can be continued later
+			if cacheErr := summaryCache.SetItem(partialItem); cacheErr != nil {
+				logWarn("Worker: VideoID %s: Failed to cache partial summary: %v", job.VideoID, cacheErr)
+			} else {
+				logInfo("Worker: VideoID %s: Cached partial summary of %d/%d chunks.", job.VideoID, len(partialItem.Chunks), len(chunks))
+				err = &partialCachedError{err: err}
+			}
+		}
+		return nil, err
 	}
 
-	if summaryCache != nil {
-		// job.UserID is the initial requester. AddUserSummaryToCache also adds to their list.
-		if err := summaryCache.AddUserSummaryToCache(job.UserID, job.VideoID, videoInfo.Title, summaryText, nil, transcriptItems); err != nil {
-			log.Printf("Warning: Worker: VideoID %s, UserID %s: Error saving summary to cache: %v. Processing continues, but result may not be cached.", job.VideoID, job.UserID, err)
+	category := categorizeSummary(ctx, job, videoInfo.Title, summaryResult)
+	cacheItem := newSummaryCacheItem(job.VideoID, videoInfo, summaryResult, transcriptItems)
+	cacheItem.Language = language
+	cacheItem.Style = job.cacheStyle()
+	cacheItem.Category = category
+	if isLowCoverage(cacheItem.Coverage) {
+		logWarn("Worker: VideoID %s: Transcript only covers %.1f%% of the video", job.VideoID, cacheItem.Coverage)
+	}
+	quality := summaryQuality{LowCoverage: isLowCoverage(cacheItem.Coverage)}
+	cacheItem.Summary = withQualityNote(cacheItem.Summary, quality, language)
+	cacheItem.RequestedBy = job.UserID
+	cacheItem.Pinned = job.Pinned[models.CacheKey(job.VideoID, language)]
+
+	if job.Reprocess && summaryCache != nil {
+		// Nobody asked for this summary now: keep the original requester and leave histories alone
+		if previous != nil {
+			cacheItem.RequestedBy = previous.RequestedBy
+		}
+		if err := summaryCache.SetItem(cacheItem); err != nil {
+			logWarn("Worker: VideoID %s: Error saving reprocessed summary to cache: %v", job.VideoID, err)
+		}
+	} else if summaryCache != nil {
+		// job.UserID is the initial requester. AddUserSummaryItemToCache also adds to their list.
+		if err := summaryCache.AddUserSummaryItemToCache(job.UserID, cacheItem); err != nil {
+			logWarn("Worker: VideoID %s, UserID %s: Error saving summary to cache: %v. Processing continues, but result may not be cached.", job.VideoID, job.UserID, err)
 			// Not returning an error here as summary was generated, just caching failed.
 		}
 	}
 
-	log.Printf("Info: Worker: Successfully processed and cached summary for VideoID %s (Original UserID: %s)", job.VideoID, job.UserID)
+	incrementCounter(counterSummariesTotal)
+	recordSummaryUsage(job, language, summaryResult.Model, summaryResult.Usage, false)
+	logInfo("Worker: Successfully processed and cached summary for VideoID %s (Original UserID: %s)", job.VideoID, job.UserID)
 
 	// This response is what would eventually be sent via SSE.
 	// For now, it's logged by the worker.
-	return &SummaryResponse{
+	resp := &SummaryResponse{
 		VideoID:    job.VideoID,
 		Title:      videoInfo.Title,
-		Summary:    summaryText,
-		Timestamps: nil, // Timestamps are not used in this new flow directly in response
+		Summary:    cacheItem.Summary,
+		Timestamps: cacheItem.Timestamps,
 		Transcript: MergeTranscript(transcriptItems),
+		Chunks:     cacheItem.Chunks,
 		Cached:     false, // It's newly generated
-	}, nil
-}
-
-// 사용자의 API 키를 Authorization 헤더에서 추출합니다
-func extractAPIKeyFromHeader(c *gin.Context) string {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		return ""
-	}
+		Language:   cacheItem.Language,
+		Style:      cacheItem.Style,
+		Model:      cacheItem.Model,
+		Category:   cacheItem.Category,
 
-	// "Bearer " 접두사 확인 및 제거
-	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return ""
+		Reprocessed: job.Reprocess,
 	}
-
-	return strings.TrimPrefix(authHeader, "Bearer ")
+	resp.setVideoMetadata(cacheItem)
+	resp.setCoverage(cacheItem.Coverage)
+	resp.setCost(cacheItem)
+	return resp, nil
 }
 
-// HandleSummaryRequest processes a request to summarize a YouTube video
-func HandleSummaryRequest(c *gin.Context) {
-	var request SummaryRequest
+// fetchJobTranscript looks up the video's metadata and transcript, unless the transcript was already
+// fetched before the job was queued. It returns the transcript as chunks and as one sorted list.
+func fetchJobTranscript(ctx context.Context, job SummarizationJob) (*services.VideoInfo, [][]services.TranscriptItem, []services.TranscriptItem, error) {
+	var videoInfo *services.VideoInfo
+	err := retryOnBotCheck(ctx, job, func() (err error) {
+		videoInfo, err = services.GetVideoInfoCached(ctx, job.VideoID)
+		return err
+	})
+	if err != nil {
+		logError("Worker: VideoID %s, UserID %s: Failed to get video info: %v", job.VideoID, job.UserID, err)
+		return nil, nil, nil, &jobStageError{stage: jobStageVideoInfo, err: fmt.Errorf("failed to get video info for VideoID %s: %w", job.VideoID, err)}
+	}
+	// Also covers jobs that were not checked before queuing, such as playlist and restored jobs
+	if err := checkVideoDuration(videoInfo); err != nil {
+		logInfo("Worker: VideoID %s, UserID %s: Skipping, %v", job.VideoID, job.UserID, err)
+		return nil, nil, nil, &jobStageError{stage: jobStageVideoInfo, err: err}
+	}
 
-	// Bind request body to struct
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
+	chunks := job.Transcript
+	if len(chunks) == 0 {
+		err = retryOnBotCheck(ctx, job, func() (err error) {
+			chunks, err = services.GetTranscript(ctx, job.VideoID, transcriptChunkSeconds())
+			return err
 		})
-		return
+		if errors.Is(err, services.ErrNoTranscript) && services.GetEnvBool("ENABLE_WHISPER_FALLBACK", false) {
+			logInfo("Worker: VideoID %s, UserID %s: Video has no captions, transcribing its audio instead.", job.VideoID, job.UserID)
+			chunks, err = services.TranscribeVideoAudio(ctx, job.VideoID, transcriptChunkSeconds(), job.APIKey, job.UserID)
+		}
+		if errors.Is(err, services.ErrNoTranscript) {
+			logInfo("Worker: VideoID %s, UserID %s: Video has no captions, skipping: %v", job.VideoID, job.UserID, err)
+			return nil, nil, nil, &jobStageError{stage: jobStageTranscript, err: fmt.Errorf("failed to get transcript for VideoID %s: %w", job.VideoID, err)}
+		}
+		if err != nil {
+			logError("Worker: VideoID %s, UserID %s: Failed to get video transcript: %v", job.VideoID, job.UserID, err)
+			return nil, nil, nil, &jobStageError{stage: jobStageTranscript, err: fmt.Errorf("failed to get transcript for VideoID %s: %w", job.VideoID, err)}
+		}
 	}
 
-	// auth 패키지의 GetSessionUser를 사용하여 사용자 정보 조회
-	userInfo, authenticated := auth.GetSessionUser(c)
-	if !authenticated || userInfo == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "인증된 사용자 정보를 찾을 수 없습니다.",
+	if chapters := videoChapters(videoInfo); len(chapters) > 0 {
+		chunks = services.ChunkTranscriptByChapters(chunks, chapters, transcriptChunkSeconds())
+	}
+
+	var transcriptItems []services.TranscriptItem
+	if len(chunks) > 0 {
+		transcriptItems = services.MergeTranscriptChunks(chunks)
+		services.SortTranscriptItemsByTime(transcriptItems)
+	}
+	return videoInfo, chunks, transcriptItems, nil
+}
+
+// processMultiLanguageJob summarizes a video in each of several languages. The transcript is
+// fetched once and summarized once per language; every summary is cached under its own key and
+// languages that are already cached are reused.
+func processMultiLanguageJob(ctx context.Context, job SummarizationJob, languages []string) (*SummaryResponse, error) {
+	items := make(map[string]*models.CacheItem, len(languages))
+	var missing []string
+	for _, language := range languages {
+		if summaryCache != nil {
+			if cachedItem, found := summaryCache.Get(models.CacheKey(job.VideoID, language)); found && !cachedItem.Partial && cachedItem.SummaryStyle() == job.style() {
+				items[language] = cachedItem
+				continue
+			}
+		}
+		missing = append(missing, language)
+	}
+
+	if len(missing) > 0 {
+		videoInfo, chunks, transcriptItems, err := fetchJobTranscript(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+
+		var category string
+		for _, language := range missing {
+			if reused := reuseSummaryByTranscript(job, videoInfo, transcriptItems, language); reused != nil {
+				items[language] = reused
+				continue
+			}
+
+			opts := summarizeOptions(job, language, language)
+			opts.Chapters = videoChapters(videoInfo)
+			summaryResult, err := services.SummarizeChunksFrom(ctx, chunks, nil, opts, job.APIKey, job.UserID)
+			if err != nil {
+				logError("Worker: VideoID %s, UserID %s: Failed to summarize transcript chunks in %s: %v", job.VideoID, job.UserID, language, err)
+				return nil, &jobStageError{stage: jobStageSummarize, err: fmt.Errorf("failed to summarize transcript for VideoID %s in %s: %w", job.VideoID, language, err)}
+			}
+
+			if category == "" {
+				// Every language describes the same video, so one classification is enough
+				category = categorizeSummary(ctx, job, videoInfo.Title, summaryResult)
+			}
+			cacheItem := newSummaryCacheItem(job.VideoID, videoInfo, summaryResult, transcriptItems)
+			cacheItem.Language = language
+			cacheItem.Style = job.cacheStyle()
+			cacheItem.Category = category
+			cacheItem.RequestedBy = job.UserID
+			cacheItem.Summary = withQualityNote(cacheItem.Summary, summaryQuality{LowCoverage: isLowCoverage(cacheItem.Coverage)}, language)
+			if summaryCache != nil {
+				if err := summaryCache.AddUserSummaryItemToCache(job.UserID, cacheItem); err != nil {
+					logWarn("Worker: VideoID %s, UserID %s: Error saving %s summary to cache: %v", job.VideoID, job.UserID, language, err)
+				}
+			}
+			incrementCounter(counterSummariesTotal)
+			recordSummaryUsage(job, language, summaryResult.Model, summaryResult.Usage, false)
+			items[language] = cacheItem
+		}
+		logInfo("Worker: Summarized VideoID %s in %d language(s) from one transcript fetch (Original UserID: %s)", job.VideoID, len(missing), job.UserID)
+	} else if err := models.AddUserSummary(job.UserID, job.VideoID, items[languages[0]].Title); err != nil {
+		logWarn("Worker: VideoID %s, UserID %s: Error adding user summary: %v", job.VideoID, job.UserID, err)
+	}
+
+	return newMultiLanguageResponse(languages, items, len(missing) == 0), nil
+}
+
+// categorizeSummary classifies a generated summary into one of services.SummaryCategories when
+// ENABLE_CATEGORIZATION is enabled. The tokens it uses are added to the summary's usage.
+// Failures are logged and leave the summary uncategorized.
+func categorizeSummary(ctx context.Context, job SummarizationJob, title string, summaryResult *services.ChunkedSummary) string {
+	if !services.GetEnvBool("ENABLE_CATEGORIZATION", false) {
+		return ""
+	}
+	category, usage, err := services.CategorizeSummary(ctx, title, summaryResult.Summary, job.APIKey, job.UserID)
+	summaryResult.Usage.Add(usage)
+	if err != nil {
+		logWarn("Worker: VideoID %s: %v", job.VideoID, err)
+		return ""
+	}
+	logDebug("Worker: VideoID %s categorized as %q", job.VideoID, category)
+	return category
+}
+
+// reuseSummaryByTranscript looks for a cached summary of another video with an identical transcript,
+// such as a re-upload or mirror, when DEDUP_BY_TRANSCRIPT_HASH is enabled. A match is copied to the
+// job's video, cached under its key and returned, so the transcript is not summarized again.
+// Regenerate jobs never reuse a summary, since the user asked for a fresh one.
+func reuseSummaryByTranscript(job SummarizationJob, videoInfo *services.VideoInfo, transcriptItems []services.TranscriptItem, language string) *models.CacheItem {
+	if job.Regenerate || summaryCache == nil || !services.GetEnvBool("DEDUP_BY_TRANSCRIPT_HASH", false) {
+		return nil
+	}
+	hash := services.TranscriptHash(transcriptItems)
+	source, found := summaryCache.FindByTranscriptHash(hash, language)
+	if !found || source.VideoID == job.VideoID || source.SummaryStyle() != job.style() {
+		return nil
+	}
+
+	item := &models.CacheItem{
+		VideoID:        job.VideoID,
+		Title:          videoInfo.Title,
+		Summary:        source.Summary,
+		Timestamps:     source.Timestamps,
+		Transcript:     transcriptItems,
+		Chunks:         source.Chunks,
+		RawSummary:     source.RawSummary,
+		Channel:        videoInfo.Channel,
+		ChannelID:      videoInfo.ChannelID,
+		Coverage:       services.TranscriptCoverage(transcriptItems, videoInfo.Duration),
+		Duration:       videoInfo.Duration,
+		UploadDate:     videoInfo.UploadDate,
+		Thumbnail:      videoInfo.Thumbnail,
+		Model:          source.Model,
+		Category:       source.Category,
+		Language:       language,
+		Style:          source.Style,
+		TranscriptHash: hash,
+		ReusedFrom:     source.VideoID,
+	}
+	if err := summaryCache.AddUserSummaryItemToCache(job.UserID, item); err != nil {
+		logWarn("Worker: VideoID %s, UserID %s: Error saving reused summary to cache: %v", job.VideoID, job.UserID, err)
+	}
+	logInfo("Worker: VideoID %s has the same transcript as VideoID %s; reused its summary", job.VideoID, source.VideoID)
+	return item
+}
+
+// newMultiLanguageResponse combines the summaries of several languages into one response.
+// The first language's item provides the title, transcript and summary.
+func newMultiLanguageResponse(languages []string, items map[string]*models.CacheItem, cached bool) *SummaryResponse {
+	first := items[languages[0]]
+	resp := &SummaryResponse{
+		VideoID:    first.VideoID,
+		Title:      first.Title,
+		Summary:    first.Summary,
+		Timestamps: first.Timestamps,
+		Transcript: MergeTranscript(first.Transcript),
+		Cached:     cached,
+		Style:      first.Style,
+		Model:      first.Model,
+		Category:   first.Category,
+		Summaries:  make(map[string]string, len(languages)),
+	}
+	for _, language := range languages {
+		resp.Summaries[language] = items[language].Summary
+	}
+	if structuredOutputEnabled() {
+		resp.Chunks = first.Chunks
+	}
+	resp.setVideoMetadata(first)
+	resp.setCoverage(first.Coverage)
+	resp.setCost(first)
+	return resp
+}
+
+// newSummaryCacheItem builds the cache item for a generated summary.
+func newSummaryCacheItem(videoID string, videoInfo *services.VideoInfo, summaryResult *services.ChunkedSummary, transcriptItems []services.TranscriptItem) *models.CacheItem {
+	cacheItem := &models.CacheItem{
+		VideoID:    videoID,
+		Title:      videoInfo.Title,
+		Summary:    summaryResult.Summary,
+		Timestamps: summaryTimestamps(summaryResult.Summary),
+		Transcript: transcriptItems,
+		Channel:    videoInfo.Channel,
+		ChannelID:  videoInfo.ChannelID,
+		Coverage:   services.TranscriptCoverage(transcriptItems, videoInfo.Duration),
+		Duration:   videoInfo.Duration,
+		UploadDate: videoInfo.UploadDate,
+		Thumbnail:  videoInfo.Thumbnail,
+		Model:      summaryResult.Model,
+
+		TranscriptHash: services.TranscriptHash(transcriptItems),
+	}
+	if usage := summaryResult.Usage; usage.TotalTokens() > 0 {
+		cacheItem.Usage = &usage
+		cacheItem.CostUSD, _ = services.EstimateCost(summaryResult.Model, usage)
+	}
+	if structuredOutputEnabled() {
+		cacheItem.Chunks = summaryResult.Chunks
+	}
+	if services.GetEnvBool("STORE_RAW_SUMMARY", false) {
+		cacheItem.RawSummary = summaryResult.RawSummary
+	}
+	return cacheItem
+}
+
+// summaryTimestamps returns the [MM:SS] markers of a summary in seconds, so clients can render seek links
+func summaryTimestamps(summary string) []models.Timestamp {
+	var timestamps []models.Timestamp
+	for _, ts := range services.ExtractTimestamps(summary) {
+		timestamps = append(timestamps, models.Timestamp{Time: ts.Time, Text: ts.Text})
+	}
+	return timestamps
+}
+
+// 사용자의 API 키를 Authorization 헤더에서 추출합니다
+func extractAPIKeyFromHeader(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return ""
+	}
+
+	// "Bearer " 접두사 확인 및 제거
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimPrefix(authHeader, "Bearer ")
+}
+
+// ValidateURLRequest represents the body of a URL validation request
+type ValidateURLRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// HandleValidateURL checks whether a URL points to a YouTube video and returns its canonical form.
+// It only parses the URL; no yt-dlp call is made and nothing is queued or cached.
+// Accepts either GET with a ?url= query parameter or POST with a JSON body.
+func HandleValidateURL(c *gin.Context) {
+	var request ValidateURLRequest
+	if c.Request.Method == http.MethodGet {
+		request.URL = c.Query("url")
+	} else if status, code, message := decodeJSONBody(c, &request); status != 0 {
+		c.JSON(status, gin.H{
+			"valid": false,
+			"code":  code,
+			"error": message,
+		})
+		return
+	}
+
+	videoURL := strings.TrimSpace(request.URL)
+	if videoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"valid": false,
+			"code":  "missing_url",
+			"error": localize(c, i18n.MsgURLRequired),
+		})
+		return
+	}
+
+	videoID, err := services.GetVideoID(videoURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"valid":  false,
+			"code":   "invalid_url",
+			"error":  localize(c, i18n.MsgInvalidYouTubeURL),
+			"reason": localizeError(c, err),
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"valid":        true,
+		"videoId":      videoID,
+		"canonicalUrl": services.CanonicalVideoURL(videoID),
+	})
+}
+
+// summaryJobRequest is a validated summary request together with the user, key and video it resolved to
+type summaryJobRequest struct {
+	SummaryRequest
+	userID     string
+	userAPIKey string
+	keySource  string
+	model      string // Model to use, cleared when the user has no API key of their own
+	style      string // Normalized summary style
+	videoID    string
+	languages  []string
+
+	messageLanguage string // Language of the client's messages, from Accept-Language
+}
+
+// bindSummaryRequest parses and validates the summary request of the authenticated user.
+// It writes the error response itself and returns false when the request can't be served.
+func bindSummaryRequest(c *gin.Context, handler string) (*summaryJobRequest, bool) {
+	var request SummaryRequest
+
+	// Bind request body to struct, reading at most MAX_REQUEST_BODY_BYTES
+	if !bindJSONBody(c, &request) {
+		return nil, false
+	}
+
+	// auth 패키지의 GetSessionUser를 사용하여 사용자 정보 조회
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": localize(c, i18n.MsgUserNotFound),
+		})
+		return nil, false
+	}
+
 	// 안전하게 사용자 ID 추출
 	userID := userInfo.ID
 
-	// Authorization 헤더에서 사용자 API 키 추출
-	userAPIKey := extractAPIKeyFromHeader(c)
-
-	// API 키 사용 가능 여부 확인
-	if userAPIKey == "" {
-		// 사용자가 API 키를 제공하지 않은 경우 서버 키 사용 가능한지 확인
-		policy := services.GetAPIKeyPolicy()
-		if !policy.CanUseServerKey(userID) {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "API 키가 필요합니다. 설정에서 OpenAI API 키를 설정해주세요.",
-			})
-			return
+	// 사용자 입력 필드 검증 (길이, 제어 문자, 허용 값)
+	if verr := validateSummaryRequest(&request); verr != nil {
+		c.JSON(http.StatusBadRequest, verr.response(i18n.RequestLanguage(c.Request)))
+		return nil, false
+	}
+
+	// 콜백 URL은 허용된 외부 호스트만 사용 가능 (SSRF 방지)
+	if request.CallbackURL != "" {
+		if err := validateCallbackURL(request.CallbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidCallbackURL, localizeError(c, err))})
+			return nil, false
 		}
 	}
 
+	// Authorization 헤더 키와 저장된 키 중 API_KEY_PRECEDENCE에 따라 사용할 키 결정
+	userAPIKey, keySource := resolveAPIKey(extractAPIKeyFromHeader(c), userID)
+
+	// API 키 사용 가능 여부 확인 (사용자 키가 없고 서버 키도 사용할 수 없는 경우)
+	if keySource == apiKeySourceNone {
+		c.JSON(http.StatusForbidden, apiKeyRequiredResponse(c, userID))
+		return nil, false
+	}
+	logDebug("%s: UserID %s uses API key source %q", handler, userID, keySource)
+
+	// 사용자가 고른 모델은 본인 API 키로 요약할 때만 사용 (서버 키 비용 통제)
+	model := request.Model
+	if model != "" && userAPIKey == "" {
+		logInfo("%s: Ignoring model %q requested by UserID %s without their own API key.", handler, model, userID)
+		model = ""
+	}
+
 	// Extract video ID from URL
 	videoID, err := services.GetVideoID(request.URL)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YouTube URL: " + err.Error()})
+		c.JSON(http.StatusBadRequest, videoURLErrorResponse(err, i18n.RequestLanguage(c.Request)))
+		return nil, false
+	}
+
+	languages := normalizeLanguages([]string{request.Language})
+	if len(request.Languages) > 0 {
+		languages = normalizeLanguages(request.Languages)
+	}
+	style, _ := services.NormalizeSummaryStyle(request.Style) // Validated above
+
+	return &summaryJobRequest{
+		SummaryRequest: request,
+		userID:         userID,
+		userAPIKey:     userAPIKey,
+		keySource:      keySource,
+		model:          model,
+		style:          style,
+		videoID:        videoID,
+		languages:      languages,
+
+		messageLanguage: i18n.RequestLanguage(c.Request),
+	}, true
+}
+
+// HandleSummaryRequest processes a request to summarize a YouTube video
+func HandleSummaryRequest(c *gin.Context) {
+	withIdempotencyKey(c, handleSummaryRequest)
+}
+
+func handleSummaryRequest(c *gin.Context) {
+	req, ok := bindSummaryRequest(c, "HandleSummaryRequest")
+	if !ok {
 		return
 	}
+	userID, videoID := req.userID, req.videoID
 
-	// Check cache first
-	if summaryCache != nil {
-		if cachedItem, found := summaryCache.Get(videoID); found {
-			log.Printf("Info: HandleSummaryRequest: Cache hit for VideoID: %s, requesting UserID: %s.", videoID, userID)
-			// Ensure this user has this summary in their list, even if it was cached by another user or system process
-			if err := models.AddUserSummary(userID, videoID, cachedItem.Title); err != nil {
-				log.Printf("Warning: HandleSummaryRequest (Cache Hit): UserID %s, VideoID %s: Failed to add user summary: %v", userID, videoID, err)
-			}
+	requestID := requestIDFor(c)
+	c.Header(requestIDHeader, requestID)
+	ctx, span := services.StartSpan(requestContext(c), "HandleSummaryRequest", services.VideoIDAttr(videoID), services.RequestIDAttr(requestID))
+	defer span.End()
 
-			var transcript []services.TranscriptItem = cachedItem.Transcript
-			if len(transcript) == 0 {
-				chunks, errTr := services.GetTranscript(videoID, 0)
-				if errTr == nil && len(chunks) > 0 {
-					transcript = chunks[0]
-					summaryCache.Set(videoID, cachedItem.Title, cachedItem.Summary, nil, transcript) // Update cache with transcript
-				} else if errTr != nil {
-					log.Printf("Error fetching transcript for cached item %s: %v", videoID, errTr)
-				}
-			}
+	// Check cache first
+	if hit, _ := lookupCachedSummary(req); hit != nil {
+		recordCacheLookup(cacheSourceRequest, true)
+		serveCachedSummary(ctx, c, req, hit)
+		return
+	}
 
-			c.JSON(http.StatusOK, SummaryResponse{
-				VideoID:    videoID,
-				Title:      cachedItem.Title,
-				Summary:    cachedItem.Summary,
-				Timestamps: cachedItem.Timestamps,
-				Transcript: MergeTranscript(transcript),
-				Cached:     true,
-			})
-			return
-		}
+	// 사용자 키는 작업을 큐에 넣기 전에 확인 (잘못된 키로 워커를 낭비하지 않도록)
+	if !checkUserAPIKey(ctx, c, req.userAPIKey, req.keySource) {
+		return
+	}
+	if !checkRequestVideoDuration(ctx, c, videoID) {
+		return
 	}
 
 	// Deduplication logic for active jobs
-	activeVideoJobsMutex.Lock()
-	subscribers, isJobActive := activeVideoJobs[videoID]
-	if isJobActive {
-		alreadySubscribed := false
-		for _, subUserID := range subscribers {
-			if subUserID == userID {
-				alreadySubscribed = true
-				break
-			}
-		}
-		if !alreadySubscribed {
-			activeVideoJobs[videoID] = append(subscribers, userID)
-			log.Printf("Info: HandleSummaryRequest: VideoID %s already being processed/queued. Added UserID %s to subscribers list.", videoID, userID)
-		} else {
-			log.Printf("Info: HandleSummaryRequest: VideoID %s already being processed/queued. UserID %s is already a subscriber.", videoID, userID)
-		}
-		activeVideoJobsMutex.Unlock()
+	job := newSummarizationJob(ctx, req, requestID)
+	hit, isNewJob := subscribeUnlessCached(req, &job)
+	recordCacheLookup(cacheSourceRequest, hit != nil)
+	if hit != nil {
+		serveCachedSummary(ctx, c, req, hit)
+		return
+	}
+	if !isNewJob {
 		c.JSON(http.StatusAccepted, gin.H{
-			"message":  "Summarization for this video is already in progress or queued. You will be notified upon completion.",
+			"message":  localize(c, i18n.MsgSummaryInProgress),
 			"video_id": videoID,
 		})
 		return
 	}
+	logInfo("HandleSummaryRequest: New summarization request for VideoID %s by UserID %s. Registered and attempting to queue.", videoID, userID)
+	submitSummaryJob(c, job)
+}
 
-	activeVideoJobs[videoID] = []string{userID} // Register new job with this user as the first subscriber
-	activeVideoJobsMutex.Unlock()
-	log.Printf("Info: HandleSummaryRequest: New summarization request for VideoID %s by UserID %s. Registered and attempting to queue.", videoID, userID)
-	job := SummarizationJob{
-		VideoID:  videoID,
-		UserID:   userID, // UserID here is the initial requester. Worker will use VideoID to get all subscribers.
-		APIKey:   userAPIKey,
-		URL:      request.URL,
+// cachedSummaryHit is a cached summary that answers a summary request
+type cachedSummaryHit struct {
+	item     *models.CacheItem // Single-language requests
+	combined *SummaryResponse  // Multi-language requests, with all the languages
+}
+
+// lookupCachedSummary returns the cached summary for the request, or nil if there is none that is complete
+// and in the requested style. When only a partial summary is cached and the client wants it continued,
+// its chunks are returned to resume from.
+func lookupCachedSummary(req *summaryJobRequest) (*cachedSummaryHit, []services.ChunkSummary) {
+	if summaryCache == nil {
+		return nil, nil
+	}
+	videoID, languages := req.videoID, req.languages
+	if len(languages) > 1 {
+		if resp, found := cachedMultiLanguageResponse(videoID, languages, req.style); found {
+			return &cachedSummaryHit{combined: resp}, nil
+		}
+		return nil, nil
+	}
+
+	cachedItem, found := summaryCache.Get(models.CacheKey(videoID, languages[0]))
+	if !found {
+		return nil, nil
+	}
+	if cachedItem.SummaryStyle() != req.style {
+		// The cached summary is regenerated in the requested style and replaced
+		logInfo("HandleSummaryRequest: VideoID %s is cached in style %q, %q requested.", videoID, cachedItem.SummaryStyle(), req.style)
+		return nil, nil
+	}
+	if cachedItem.Partial && req.Partial != partialAccept {
+		// Never serve an incomplete summary unless the client explicitly accepts it
+		logInfo("HandleSummaryRequest: Only a partial summary is cached for VideoID %s (%q requested).", videoID, req.Partial)
+		if req.Partial == partialContinue {
+			return nil, cachedItem.Chunks
+		}
+		return nil, nil
+	}
+	return &cachedSummaryHit{item: cachedItem}, nil
+}
+
+// serveCachedSummary answers a summary request from the cache and adds the summary to the user's list,
+// even if it was cached by another user or system process
+func serveCachedSummary(ctx context.Context, c *gin.Context, req *summaryJobRequest, hit *cachedSummaryHit) {
+	userID, videoID := req.userID, req.videoID
+	if hit.combined != nil {
+		logInfo("HandleSummaryRequest: Cache hit for VideoID %s in all %d requested languages, requesting UserID: %s.", videoID, len(req.languages), userID)
+		if err := models.AddUserSummary(userID, videoID, hit.combined.Title); err != nil {
+			logWarn("HandleSummaryRequest (Cache Hit): UserID %s, VideoID %s: Failed to add user summary: %v", userID, videoID, err)
+		}
+		c.JSON(http.StatusOK, hit.combined)
+		return
+	}
+
+	cachedItem := hit.item
+	logInfo("HandleSummaryRequest: Cache hit for VideoID: %s, requesting UserID: %s.", videoID, userID)
+	if err := models.AddUserSummary(userID, videoID, cachedItem.Title); err != nil {
+		logWarn("HandleSummaryRequest (Cache Hit): UserID %s, VideoID %s: Failed to add user summary: %v", userID, videoID, err)
+	}
+
+	var transcript []services.TranscriptItem = cachedItem.Transcript
+	if len(transcript) == 0 {
+		chunks, errTr := services.GetTranscript(ctx, videoID, 0)
+		if errTr == nil && len(chunks) > 0 {
+			transcript = chunks[0]
+			updatedItem := *cachedItem
+			updatedItem.Transcript = transcript
+			summaryCache.SetItem(&updatedItem) // Update cache with transcript
+		} else if errTr != nil {
+			logError("Failed to fetch transcript for cached item %s: %v", videoID, errTr)
+		}
+	}
+
+	c.JSON(http.StatusOK, newCachedSummaryResponse(cachedItem, transcript))
+}
+
+// subscribeUnlessCached subscribes the requesting user to the job like subscribeToJob, unless the summary
+// was cached since the request's first lookup. Both happen under activeVideoJobsMutex: workers cache a
+// summary before completeJob unregisters its job, so two simultaneous requests can't both miss the cache
+// and start a job each, e.g. when the second one arrives just as the first one's job finishes.
+func subscribeUnlessCached(req *summaryJobRequest, job *SummarizationJob) (*cachedSummaryHit, bool) {
+	activeVideoJobsMutex.Lock()
+	defer activeVideoJobsMutex.Unlock()
+
+	hit, resumeChunks := lookupCachedSummary(req)
+	if hit != nil {
+		return hit, false
+	}
+	job.ResumeChunks = resumeChunks
+	return nil, subscribeToJobLocked(job.key(), req.userID, req.CallbackURL)
+}
+
+// newSummarizationJob builds the job for a validated summary request
+func newSummarizationJob(ctx context.Context, req *summaryJobRequest, requestID string) SummarizationJob {
+	return SummarizationJob{
+		VideoID:  req.videoID,
+		UserID:   req.userID, // UserID here is the initial requester. Worker will use the job key to get all subscribers.
+		APIKey:   req.userAPIKey,
+		URL:      req.URL,
 		IsSSE:    true,
 		ClientID: "",
+
+		Languages:    req.languages,
+		Model:        req.model,
+		Temperature:  req.Temperature,
+		Style:        req.style,
+		RequestID:    requestID,
+		TraceCarrier: injectTraceContext(ctx),
+
+		MessageLanguage: req.messageLanguage,
 	}
+}
 
-	select {
-	case jobQueue <- job:
-		log.Printf("Job queued for VideoID: %s by UserID: %s", videoID, userID)
-		c.JSON(http.StatusAccepted, gin.H{
-			"message":  "Summarization request received and queued. You will be notified upon completion.",
-			"video_id": videoID,
-		})
-	default:
-		// If queue is full, unregister the job from activeVideoJobs as it won't be processed now.
-		activeVideoJobsMutex.Lock()
-		log.Printf("DebugHandleSummaryRequest: Deleting activeVideoJobs[%s] due to full queue. UserID: %s", videoID, userID) // New Log
-		delete(activeVideoJobs, videoID)                                                                                     // Clean up: remove from active jobs as it won't be queued
-		activeVideoJobsMutex.Unlock()
-		log.Printf("Warning: HandleSummaryRequest: Job queue full for VideoID: %s, UserID: %s. Rejected job and removed from active jobs list.", videoID, userID)
+// submitSummaryJob runs or queues a job the requesting user was just registered for and answers the request.
+// The job is unregistered again when the queue is full or the user's server key quota is used up.
+func submitSummaryJob(c *gin.Context, job SummarizationJob) {
+	if !reserveServerKeySummary(job) {
+		// Other jobs of the user were admitted since the request was checked
+		completeJob(job, nil, errServerKeyQuotaExhausted, job.UserID)
+		c.JSON(http.StatusForbidden, apiKeyRequiredResponse(c, job.UserID))
+		return
+	}
+	if syncSmallJobsEnabled() {
+		// Short videos may be answered directly; long ones are handed to the worker pool from there.
+		handleJobSynchronously(c, job)
+		return
+	}
+
+	if !tryEnqueueJob(job) {
+		// The job won't be processed now: unregister it and tell anyone who subscribed in the meantime.
+		completeJob(job, nil, errJobQueueFull, job.UserID)
+		logWarn("submitSummaryJob: Job queue full for VideoID: %s, UserID: %s. Rejected job and removed from active jobs list.", job.VideoID, job.UserID)
 		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":    "Server busy, job queue full. Please try again later.",
-			"video_id": videoID,
+			"error":    localize(c, i18n.MsgJobQueueFull),
+			"video_id": job.VideoID,
 		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  localize(c, i18n.MsgSummaryQueued),
+		"video_id": job.VideoID,
+	})
+}
+
+// cachedMultiLanguageResponse returns the combined response for a video when summaries in all
+// the languages are cached, complete and in the requested style.
+func cachedMultiLanguageResponse(videoID string, languages []string, style string) (*SummaryResponse, bool) {
+	items := make(map[string]*models.CacheItem, len(languages))
+	for _, language := range languages {
+		item, found := summaryCache.Get(models.CacheKey(videoID, language))
+		if !found || item.Partial || item.SummaryStyle() != style {
+			return nil, false
+		}
+		items[language] = item
 	}
+	return newMultiLanguageResponse(languages, items, true), true
 }
 
+// subscribeToJob adds userID as a subscriber of the active job with the given key.
+// It returns true if no such job was active, in which case a new job is registered with userID
+// as its first subscriber and the caller must start it.
+func subscribeToJob(key, userID, callbackURL string) bool {
+	activeVideoJobsMutex.Lock()
+	defer activeVideoJobsMutex.Unlock()
+	return subscribeToJobLocked(key, userID, callbackURL)
+}
+
+// subscribeToJobLocked is subscribeToJob without locking.
+// The caller must hold activeVideoJobsMutex.
+func subscribeToJobLocked(key, userID, callbackURL string) bool {
+	registerJobCallback(key, userID, callbackURL)
+	subscribers, isJobActive := activeVideoJobs[key]
+	if !isJobActive {
+		activeVideoJobs[key] = []string{userID} // Register new job with this user as the first subscriber
+		return true
+	}
+
+	for _, subUserID := range subscribers {
+		if subUserID == userID {
+			logInfo("HandleSummaryRequest: Job %s already being processed/queued. UserID %s is already a subscriber.", key, userID)
+			rejournalJobLocked(key) // The callback may have changed
+			return false
+		}
+	}
+	activeVideoJobs[key] = append(subscribers, userID)
+	rejournalJobLocked(key)
+	logInfo("HandleSummaryRequest: Job %s already being processed/queued. Added UserID %s to subscribers list.", key, userID)
+	return false
+}
+
+// tryEnqueueJob hands a job to the worker pool without blocking.
+// It returns false if the queue is full or the server is shutting down.
+func tryEnqueueJob(job SummarizationJob) bool {
+	job.EnqueuedAt = time.Now()
+	key := job.key()
+	// Before queuing, so a fast worker can't complete the job first
+	journalJob(job)
+	activeVideoJobsMutex.Lock()
+	queuedJobs[key] = job.EnqueuedAt
+	activeVideoJobsMutex.Unlock()
+
+	jobQueueMutex.RLock()
+	queued := false
+	if !jobQueueClosed {
+		select {
+		case jobQueue <- job:
+			queued = true
+		default:
+		}
+	}
+	jobQueueMutex.RUnlock()
+
+	if queued {
+		logInfo("Job queued for VideoID: %s by UserID: %s", job.VideoID, job.UserID)
+		return true
+	}
+	activeVideoJobsMutex.Lock()
+	delete(queuedJobs, key)
+	activeVideoJobsMutex.Unlock()
+	unjournalJob(key)
+	return false
+}
+
+// MergeTranscript merges transcript segments into one item per TRANSCRIPT_MERGE_INTERVAL_SECONDS for display
 func MergeTranscript(transcript []services.TranscriptItem) []services.TranscriptItem {
+	return mergeTranscriptItems(transcript, transcriptMergeInterval())
+}
+
+const defaultTranscriptMergeIntervalSeconds = 15
+
+// transcriptMergeInterval returns the interval configured via TRANSCRIPT_MERGE_INTERVAL_SECONDS.
+// Longer intervals give fewer, longer paragraphs; 0 keeps the original caption segments.
+func transcriptMergeInterval() float64 {
+	if seconds := services.GetEnvInt("TRANSCRIPT_MERGE_INTERVAL_SECONDS", defaultTranscriptMergeIntervalSeconds); seconds >= 0 {
+		return float64(seconds)
+	}
+	return defaultTranscriptMergeIntervalSeconds
+}
+
+// mergeTranscriptItems merges each segment starting less than intervalSeconds after the start of the
+// current item into it. The texts are joined with a space so words at the boundaries don't run together,
+// and the texts of the merged items are trimmed.
+func mergeTranscriptItems(transcript []services.TranscriptItem, intervalSeconds float64) []services.TranscriptItem {
 	if len(transcript) == 0 {
 		return transcript
 	}
 
 	var result []services.TranscriptItem
 	var currentItem services.TranscriptItem
-	const intervalSeconds float64 = 15.0
 
 	// Initialize with the first item
 	currentItem = transcript[0]
 
 	for i := 1; i < len(transcript); i++ {
-		// If the next item starts within 15 seconds of the current item's start time
+		// If the next item starts within the interval of the current item's start time
 		if transcript[i].Start-currentItem.Start < intervalSeconds {
 			// Append text to the current item
-			currentItem.Text += transcript[i].Text
+			currentItem.Text = joinTranscriptText(currentItem.Text, transcript[i].Text)
 			// Keep the duration updating to the last item's end time
 			currentItem.Duration = transcript[i].Start + transcript[i].Duration - currentItem.Start
 		} else {
 			// Current interval is complete, add to result and start a new interval
+			currentItem.Text = strings.TrimSpace(currentItem.Text)
 			result = append(result, currentItem)
 			currentItem = transcript[i]
 		}
 	}
 
 	// Add the last item
+	currentItem.Text = strings.TrimSpace(currentItem.Text)
 	result = append(result, currentItem)
 
 	return result
 }
 
-// GetRecentSummariesHandler handles requests to fetch the last 10 video summaries
+// joinTranscriptText appends next to text, separated by a single space. No space is put before closing
+// punctuation, so segments split before a comma or full stop read naturally.
+func joinTranscriptText(text, next string) string {
+	text = strings.TrimRightFunc(text, unicode.IsSpace)
+	next = strings.TrimLeftFunc(next, unicode.IsSpace)
+	if text == "" || next == "" || strings.ContainsAny(next[:1], ".,!?;:)") {
+		return text + next
+	}
+	return text + " " + next
+}
+
+// GetRawSummaryHandler returns the stored pre-cleanup model output next to the cleaned summary
+// so the two can be compared when post-processing mangled a summary (?language= for summaries in
+// other languages). Admin only.
+func GetRawSummaryHandler(c *gin.Context) {
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+	language, err := services.NormalizeLanguage(c.Query("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidLanguage), "language": c.Query("language")})
+		return
+	}
+	key := models.CacheKey(videoID, language)
+
+	if summaryCache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+
+	cachedItem, found := summaryCache.Get(key)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+	if cachedItem.RawSummary == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgRawSummaryNotStored), "videoId": videoID})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"videoId":    cachedItem.VideoID,
+		"title":      cachedItem.Title,
+		"summary":    cachedItem.Summary,
+		"rawSummary": cachedItem.RawSummary,
+		"createdAt":  cachedItem.CreatedAt,
+	})
+}
+
+// exportFormats are the formats ExportSummaryHandler accepts for ?format=, with their content types
+var exportFormats = map[string]string{
+	"md":   "text/markdown; charset=utf-8",
+	"txt":  "text/plain; charset=utf-8",
+	"json": "application/json; charset=utf-8",
+}
+
+// ExportSummaryHandler returns a cached summary as a downloadable document for note-taking apps.
+// ?format= selects md (default, Markdown with clickable timestamps), txt (title and summary text)
+// or json (the full cache item including timestamps and transcript). ?language= selects a summary
+// in another language than the default one.
+func ExportSummaryHandler(c *gin.Context) {
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+	language, err := services.NormalizeLanguage(c.Query("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidLanguage), "language": c.Query("language")})
+		return
+	}
+	key := models.CacheKey(videoID, language)
+	format := c.DefaultQuery("format", "md")
+	contentType, supported := exportFormats[format]
+	if !supported {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgUnsupportedFormat), "supportedFormats": []string{"md", "txt", "json"}})
+		return
+	}
+
+	if summaryCache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+	cachedItem, found := summaryCache.Get(key)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+
+	var body []byte
+	switch format {
+	case "md":
+		body = []byte(renderSummaryMarkdown(cachedItem))
+	case "txt":
+		body = []byte(renderSummaryText(cachedItem))
+	case "json":
+		exported := *cachedItem
+		exported.RawSummary = ""  // Admin-only debugging data (GetRawSummaryHandler)
+		exported.RequestedBy = "" // Another user's ID
+		if body, err = json.MarshalIndent(&exported, "", "  "); err != nil {
+			logError("ExportSummaryHandler: Failed to encode cached summary %s: %v", key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgExportFailed)})
+			return
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, key, format))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// renderSummaryText renders a cached summary as plain text: the title followed by the summary
+func renderSummaryText(item *models.CacheItem) string {
+	return fmt.Sprintf("%s\n\n%s\n", item.Title, strings.TrimSpace(item.Summary))
+}
+
+// summaryTimestampPattern matches the [MM:SS] and [HH:MM:SS] markers in summaries, like services.ExtractTimestamps
+var summaryTimestampPattern = regexp.MustCompile(`\[(\d{1,2}):(\d{2})(?::(\d{2}))?\]`)
+
+// renderSummaryMarkdown renders a cached summary as Markdown: the title as H1, the video link,
+// and the summary with every timestamp linked to that point in the video
+func renderSummaryMarkdown(item *models.CacheItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", item.Title)
+	fmt.Fprintf(&b, "%s\n\n", services.CanonicalVideoURL(item.VideoID))
+	b.WriteString(linkSummaryTimestamps(strings.TrimSpace(item.Summary), item.VideoID))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// linkSummaryTimestamps turns each [MM:SS] marker into a Markdown link to https://youtu.be/ID?t=SECONDS
+func linkSummaryTimestamps(summary, videoID string) string {
+	return summaryTimestampPattern.ReplaceAllStringFunc(summary, func(marker string) string {
+		parts := summaryTimestampPattern.FindStringSubmatch(marker)
+		first, _ := strconv.Atoi(parts[1])
+		second, _ := strconv.Atoi(parts[2])
+		seconds := first*60 + second
+		if parts[3] != "" {
+			third, _ := strconv.Atoi(parts[3])
+			seconds = first*3600 + second*60 + third
+		}
+		return fmt.Sprintf("%s(https://youtu.be/%s?t=%d)", marker, videoID, seconds)
+	})
+}
+
+// GetRecentSummariesHandler handles requests to fetch the last 15 video summaries
 func GetRecentSummariesHandler(c *gin.Context) {
 	c.Header("Content-Type", "application/json")
 
-	// Fetch the recent 10 video summaries
-	summaries := models.GetRecentVideoSummaries()
+	// Fetch the recent 15 video summaries
+	summaries := []models.VideoSummary{}
+	if summaryCache != nil {
+		summaries = summaryCache.RecentVideoSummaries()
+	}
 
 	// Respond with the summaries in JSON format
 	c.JSON(http.StatusOK, summaries)
 }
 
-// GetUserRecentSummariesHandler는 사용자의 최근 15개 요약을 가져오는 API 핸들러입니다.
+// defaultRecentPageSize is the page size of recent summary lists when ?limit= is not given
+const defaultRecentPageSize = 15
+
+// GetRecentSummariesPageHandler is the paginated variant of GetRecentSummariesHandler.
+// It supports ?limit= and ?offset= and reports the total number of cached summaries.
+func GetRecentSummariesPageHandler(c *gin.Context) {
+	offset, limit, err := parsePagination(c, defaultRecentPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	summaries, total := []models.VideoSummary{}, 0
+	if summaryCache != nil {
+		summaries, total = summaryCache.RecentVideoSummariesPage(offset, limit)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"summaries": summaries,
+		"total":     total,
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// maxPageSize is the largest page a list endpoint returns
+const maxPageSize = 100
+
+// defaultChannelPageSize is the page size of channel summary lists when ?limit= is not given
+const defaultChannelPageSize = 20
+
+// localize returns the message of an i18n code in the language the request prefers (Accept-Language)
+func localize(c *gin.Context, code string, args ...any) string {
+	return i18n.T(code, i18n.RequestLanguage(c.Request), args...)
+}
+
+// localizeError returns the client-facing message of err in the language the request prefers (see i18n.Message)
+func localizeError(c *gin.Context, err error) string {
+	return i18n.Message(err, i18n.RequestLanguage(c.Request))
+}
+
+// parsePagination reads ?limit= and ?offset= from the query. limit defaults to defaultLimit
+// and is clamped to maxPageSize; negative or non-numeric values are rejected.
+func parsePagination(c *gin.Context, defaultLimit int) (int, int, error) {
+	limit := defaultLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, i18n.NewError(i18n.MsgPositiveInteger, "limit")
+		}
+		limit = parsed
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	offset := 0
+	if value := c.Query("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, i18n.NewError(i18n.MsgNonNegativeInteger, "offset")
+		}
+		offset = parsed
+	}
+	return offset, limit, nil
+}
+
+// Job states reported by GetSummaryStatusHandler
+const (
+	jobStateQueued  = "queued"  // Waiting in the queue for a worker
+	jobStateActive  = "active"  // Being processed
+	jobStateCached  = "cached"  // Finished; the summary is cached
+	jobStateUnknown = "unknown" // Neither running nor cached
+)
+
+// GetSummaryStatusHandler reports the state of a video's summary for clients that poll instead of
+// listening for SSE events: queued or active while a job runs, then cached. ?language= selects the
+// summary's language like in DeleteSummaryHandler.
+func GetSummaryStatusHandler(c *gin.Context) {
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+	language, err := services.NormalizeLanguage(c.Query("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidLanguage), "language": c.Query("language")})
+		return
+	}
+	style, err := services.NormalizeSummaryStyle(c.Query("style"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidStyle), "style": c.Query("style")})
+		return
+	}
+	job := SummarizationJob{VideoID: videoID, Language: language, Style: style}
+	key := job.key()
+
+	activeVideoJobsMutex.RLock()
+	subscribers, active := activeVideoJobs[key]
+	_, queued := queuedJobs[key]
+	activeVideoJobsMutex.RUnlock()
+
+	state := jobStateUnknown
+	switch {
+	case queued:
+		state = jobStateQueued
+	case active:
+		state = jobStateActive
+	case summaryCache != nil:
+		if item, found := summaryCache.Get(job.cacheKey()); found && !item.Partial && item.SummaryStyle() == style {
+			state = jobStateCached
+		}
+	}
+
+	response := gin.H{
+		"videoId":     videoID,
+		"state":       state,
+		"subscribers": len(subscribers),
+	}
+	if language != services.DefaultSummaryLanguage {
+		response["language"] = language
+	}
+	if style != services.DefaultSummaryStyle {
+		response["style"] = style
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetChannelSummariesHandler lists the cached summaries of a channel's videos, newest first.
+// Channels without cached summaries return an empty list.
+func GetChannelSummariesHandler(c *gin.Context) {
+	channelID := strings.TrimSpace(c.Param("channelId"))
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgChannelIDRequired)})
+		return
+	}
+
+	offset, limit, err := parsePagination(c, services.GetEnvInt("CHANNEL_SUMMARIES_PAGE_SIZE", defaultChannelPageSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	summaries, total := []models.ChannelSummary{}, 0
+	if summaryCache != nil {
+		summaries, total = summaryCache.ListByChannel(channelID, offset, limit)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"channelId": channelID,
+		"summaries": summaries,
+		"total":     total,
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// GetSummariesHandler lists cached summaries classified into ?category=, newest first,
+// paginated like GetChannelSummariesHandler. The category must be one of SUMMARY_CATEGORIES.
+func GetSummariesHandler(c *gin.Context) {
+	category := strings.ToLower(strings.TrimSpace(c.Query("category")))
+	categories := services.SummaryCategories()
+	if !slices.Contains(categories, category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidCategory, strings.Join(categories, ", ")), "categories": categories})
+		return
+	}
+
+	offset, limit, err := parsePagination(c, services.GetEnvInt("CHANNEL_SUMMARIES_PAGE_SIZE", defaultChannelPageSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
+	summaries, total := []models.ChannelSummary{}, 0
+	if summaryCache != nil {
+		summaries, total = summaryCache.ListByCategory(category, offset, limit)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category":  category,
+		"summaries": summaries,
+		"total":     total,
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// GetUserRecentSummariesHandler는 사용자의 최근 요약을 최신순으로 가져오는 API 핸들러입니다.
+// ?limit=(기본 15, 최대 100)와 ?offset=으로 페이지를 선택하며, 전체 항목 수를 total로 함께 반환합니다.
 func GetUserRecentSummariesHandler(c *gin.Context) {
 	// auth 패키지의 GetSessionUser를 사용하여 사용자 정보 조회
 	userInfo, authenticated := auth.GetSessionUser(c)
 	if !authenticated || userInfo == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "인증된 사용자 정보를 찾을 수 없습니다.",
+			"error": localize(c, i18n.MsgUserNotFound),
 		})
 		return
 	}
@@ -534,17 +2018,124 @@ func GetUserRecentSummariesHandler(c *gin.Context) {
 	// 안전하게 사용자 ID 추출
 	userID := userInfo.ID
 
+	offset, limit, err := parsePagination(c, defaultRecentPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localizeError(c, err)})
+		return
+	}
+
 	// 사용자의 최근 요약을 가져옵니다.
-	summaries, err := models.GetRecentUserSummaries(userID)
+	summaries, total, err := models.GetUserSummariesPage(userID, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "사용자 요약을 가져오는데 실패했습니다: " + err.Error(),
+			"error": localize(c, i18n.MsgUserSummariesFailed, err),
 		})
 		return
 	}
 
 	// 응답 반환
-	c.JSON(http.StatusOK, summaries)
+	c.JSON(http.StatusOK, gin.H{
+		"summaries": summaries,
+		"total":     total,
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// maxSearchQueryLength bounds the ?q= of summary searches
+const maxSearchQueryLength = 200
+
+// SearchSummariesHandler searches the requesting user's summary history by title, case-insensitively.
+// With ?body=true the cached summary texts are searched too; title matches are listed first.
+func SearchSummariesHandler(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgUserNotFound)})
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgQueryRequired)})
+		return
+	}
+	if len(query) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgQueryTooLong, maxSearchQueryLength)})
+		return
+	}
+
+	var cache *models.SummaryCache
+	if c.Query("body") == "true" {
+		cache = summaryCache
+	}
+	results, err := models.SearchUserSummaries(userInfo.ID, query, cache)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgSearchFailed, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":     query,
+		"summaries": results,
+		"total":     len(results),
+	})
+}
+
+// DeleteSummaryHandler removes a video from the requesting user's summary history. Admins (ADMIN_USERS)
+// also delete the globally cached summary, since other users rely on it; ?language= selects which
+// language's summary. Returns 404 if the video is neither cached nor in the user's history.
+func DeleteSummaryHandler(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgUserNotFound)})
+		return
+	}
+	userID := userInfo.ID
+
+	videoID, err := services.NormalizeVideoID(c.Param("videoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidVideoID), "videoId": c.Param("videoId")})
+		return
+	}
+	language, err := services.NormalizeLanguage(c.Query("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidLanguage), "language": c.Query("language")})
+		return
+	}
+	key := models.CacheKey(videoID, language)
+
+	cached := false
+	if summaryCache != nil {
+		_, cached = summaryCache.Get(key)
+	}
+
+	removedFromHistory, err := models.RemoveUserSummary(userID, videoID)
+	if err != nil {
+		logError("DeleteSummaryHandler: UserID %s, VideoID %s: Failed to remove user summary: %v", userID, videoID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgHistoryRemoveFailed), "videoId": videoID})
+		return
+	}
+	if !cached && !removedFromHistory {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, i18n.MsgSummaryNotFound), "videoId": videoID})
+		return
+	}
+
+	deletedFromCache := false
+	if cached && auth.IsAdminUser(userID) {
+		if err := summaryCache.Delete(key); err != nil {
+			logError("DeleteSummaryHandler: UserID %s, VideoID %s: Failed to delete cached summary: %v", userID, videoID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgCacheDeleteFailed), "videoId": videoID})
+			return
+		}
+		deletedFromCache = true
+		logInfo("DeleteSummaryHandler: Admin %s deleted cached summary %s", userID, key)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"videoId":            videoID,
+		"removedFromHistory": removedFromHistory,
+		"deletedFromCache":   deletedFromCache,
+	})
 }
 
 // HandleSummaryEvents sets up an SSE connection for a client.
@@ -552,7 +2143,7 @@ func HandleSummaryEvents(c *gin.Context) {
 	// Authenticate user
 	userInfo, authenticated := auth.GetSessionUser(c)
 	if !authenticated || userInfo == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "인증된 사용자 정보를 찾을 수 없습니다."})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgUserNotFound)})
 		return
 	}
 	userID := userInfo.ID
@@ -561,39 +2152,15 @@ func HandleSummaryEvents(c *gin.Context) {
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
-	// c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // Consider security implications and set to specific frontend URL if possible
+	// Cross-origin access is handled by CORSMiddleware
 
-	// Create a channel for this client
-	messageChan := make(chan []byte, 10) // Buffered channel (e.g., 10 messages)
+	messageChan := registerClientChannel(userID, "SSE", i18n.RequestLanguage(c.Request))
+	defer unregisterClientChannel(userID, messageChan, "SSE")
 
-	// Register client channel
-	clientChannelsMutex.Lock()
-	// If there's an existing channel for this user, close it before creating a new one.
-	if oldChan, exists := clientChannels[userID]; exists {
-		log.Printf("Info: HandleSummaryEvents: UserID %s reconnected to SSE. Closing previous channel.", userID)
-		close(oldChan) // Close the old channel; its goroutine will terminate.
-	}
-	clientChannels[userID] = messageChan
-	clientChannelsMutex.Unlock()
-	log.Printf("Info: HandleSummaryEvents: SSE client connected: UserID %s. Channel registered.", userID)
-
-	defer func() {
-		clientChannelsMutex.Lock()
-		// Only delete and close if the current channel in the map is the one this goroutine is managing.
-		if currentChan, ok := clientChannels[userID]; ok && currentChan == messageChan {
-			delete(clientChannels, userID)
-			close(messageChan)
-			log.Printf("Info: HandleSummaryEvents: SSE client disconnected: UserID %s. Channel deregistered and closed.", userID)
-		} else {
-			// This means the channel was already replaced by a newer connection or closed by another part of the code.
-			log.Printf("Info: HandleSummaryEvents: SSE client disconnected: UserID %s. This handler's specific channel instance is no longer the active one in the global map (or was already closed). Cleanup likely handled by a newer connection or this channel instance was already superseded.", userID)
-		}
-		clientChannelsMutex.Unlock()
-	}()
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
 		// This should ideally not happen with modern HTTP servers supporting http.Flusher
-		log.Printf("Streaming unsupported for UserID %s!", userID)
+		logError("HandleSummaryEvents: Streaming unsupported for UserID %s!", userID)
 		// Cannot send JSON error as headers might be partially written.
 		// Just return to trigger the defer.
 		return
@@ -602,26 +2169,97 @@ func HandleSummaryEvents(c *gin.Context) {
 	// Send an initial connection confirmation event (optional, but good for client to know it's connected)
 	// connectMsg := []byte("event: connected\ndata: {\"message\":\"SSE connection established\"}\n\n")
 	// if _, err := c.Writer.Write(connectMsg); err != nil {
-	// 	log.Printf("Error sending connection confirmation to UserID %s: %v", userID, err)
+	// 	logError("Failed to send connection confirmation to UserID %s: %v", userID, err)
 	// 	return // Trigger defer for cleanup
 	// }
 	// flusher.Flush()
 
+	streamSSEMessages(c, flusher, userID, messageChan, sseHeartbeatInterval())
+}
+
+// registerClientChannel creates the channel that job results for userID are sent on while the user's SSE
+// or WebSocket connection is open. A previous connection of the user is closed: each user has one
+// channel, whichever transport it is for. Messages sent without a job, such as the shutdown notice, are
+// given in lang.
+func registerClientChannel(userID, transport, lang string) chan []byte {
+	// Create a channel for this client
+	messageChan := make(chan []byte, 10) // Buffered channel (e.g., 10 messages)
+
+	// Register client channel
+	clientChannelsMutex.Lock()
+	// If there's an existing channel for this user, close it before creating a new one.
+	if oldChan, exists := clientChannels[userID]; exists {
+		logInfo("UserID %s reconnected to %s. Closing previous channel.", userID, transport)
+		close(oldChan) // Close the old channel; its goroutine will terminate.
+	}
+	clientChannels[userID] = messageChan
+	clientLanguages[userID] = lang
+	// Results of jobs that completed while the user wasn't connected, e.g. during a page reload
+	flushPendingResultsLocked(userID, messageChan)
+	clientChannelsMutex.Unlock()
+	logInfo("%s client connected: UserID %s. Channel registered.", transport, userID)
+	return messageChan
+}
+
+// unregisterClientChannel removes the channel of a closed connection, unless a newer connection replaced it
+func unregisterClientChannel(userID string, messageChan chan []byte, transport string) {
+	clientChannelsMutex.Lock()
+	// Only delete and close if the current channel in the map is the one this goroutine is managing.
+	if currentChan, ok := clientChannels[userID]; ok && currentChan == messageChan {
+		delete(clientChannels, userID)
+		delete(clientLanguages, userID)
+		close(messageChan)
+		logInfo("%s client disconnected: UserID %s. Channel deregistered and closed.", transport, userID)
+		// Stop working on videos only this user was waiting for, unless they come back shortly
+		defer abandonJobsAfterDisconnect(userID)
+	} else {
+		// This means the channel was already replaced by a newer connection or closed by another part of the code.
+		logInfo("%s client disconnected: UserID %s. This handler's specific channel instance is no longer the active one in the global map (or was already closed). Cleanup likely handled by a newer connection or this channel instance was already superseded.", transport, userID)
+	}
+	clientChannelsMutex.Unlock()
+}
+
+// sseHeartbeatInterval returns how often idle SSE connections get a keepalive comment, configured via SSE_HEARTBEAT_SECONDS.
+// A value of 0 disables heartbeats.
+func sseHeartbeatInterval() time.Duration {
+	seconds := services.GetEnvInt("SSE_HEARTBEAT_SECONDS", defaultSSEHeartbeatSeconds)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// streamSSEMessages writes the messages sent to messageChan until the channel is closed, a write fails or
+// the client disconnects. A keepalive comment is written every heartbeat so proxies don't drop idle connections.
+func streamSSEMessages(c *gin.Context, flusher http.Flusher, userID string, messageChan chan []byte, heartbeat time.Duration) {
+	var heartbeats <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
 	for {
 		select {
 		case message, open := <-messageChan:
 			if !open { // True if messageChan was closed by the sender side
-				log.Printf("Info: HandleSummaryEvents: SSE message channel for UserID %s closed by sender. Terminating stream.", userID)
+				logInfo("HandleSummaryEvents: SSE message channel for UserID %s closed by sender. Terminating stream.", userID)
 				return
 			}
 			_, err := c.Writer.Write(message) // message should be pre-formatted SSE event string
 			if err != nil {
-				log.Printf("Warning: HandleSummaryEvents: Error writing to SSE client UserID %s: %v. Terminating stream.", userID, err)
+				logWarn("HandleSummaryEvents: Error writing to SSE client UserID %s: %v. Terminating stream.", userID, err)
 				return // Error writing, client likely disconnected. Defer will clean up.
 			}
 			flusher.Flush()
+		case <-heartbeats:
+			if _, err := c.Writer.Write(sseKeepalive); err != nil {
+				logDebug("HandleSummaryEvents: Error writing keepalive to SSE client UserID %s: %v. Terminating stream.", userID, err)
+				return
+			}
+			flusher.Flush()
 		case <-c.Request.Context().Done(): // Client disconnected
-			log.Printf("Info: HandleSummaryEvents: Client UserID %s context done (disconnected). Terminating SSE stream.", userID)
+			logInfo("HandleSummaryEvents: Client UserID %s context done (disconnected). Terminating SSE stream.", userID)
 			return // Defer will clean up.
 		}
 	}
@@ -0,0 +1,933 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testVideoID = "dQw4w9WgXcQ"
+
+// setupWorkerTest gives each test empty job/subscriber state and a temporary cache
+func setupWorkerTest(t *testing.T) {
+	t.Helper()
+
+	activeVideoJobsMutex.Lock()
+	activeVideoJobs = make(map[string][]string)
+	queuedJobs = make(map[string]time.Time)
+	jobCallbacks = make(map[string][]jobCallback)
+	journaledJobs = make(map[string]SummarizationJob)
+	runningJobs = make(map[string]*runningJob)
+	abandonedJobs = make(map[abandonedJob]struct{})
+	activeVideoJobsMutex.Unlock()
+
+	clientChannelsMutex.Lock()
+	clientChannels = make(map[string]chan []byte)
+	clientLanguages = make(map[string]string)
+	pendingResults = make(map[string][]pendingResult)
+	pendingResultsCount = 0
+	clientChannelsMutex.Unlock()
+
+	cache, err := models.NewSummaryCache(t.TempDir())
+	assert.NoError(t, err)
+	originalCache := summaryCache
+	summaryCache = cache
+
+	models.SetUserSummaryDirectory(t.TempDir())
+
+	originalProcessJob := processJob
+	t.Cleanup(func() {
+		processJob = originalProcessJob
+		summaryCache = originalCache
+		models.SetUserSummaryDirectory("users")
+	})
+}
+
+// subscribe registers an SSE channel for userID and adds the user as a subscriber of the video's job
+func subscribe(userID, videoID string) chan []byte {
+	ch := make(chan []byte, 10)
+	clientChannelsMutex.Lock()
+	clientChannels[userID] = ch
+	clientChannelsMutex.Unlock()
+
+	activeVideoJobsMutex.Lock()
+	activeVideoJobs[videoID] = append(activeVideoJobs[videoID], userID)
+	activeVideoJobsMutex.Unlock()
+	return ch
+}
+
+// receive returns the SSE message sent to a subscriber, or an empty string if there is none
+func receive(ch chan []byte) string {
+	select {
+	case msg := <-ch:
+		return string(msg)
+	default:
+		return ""
+	}
+}
+
+func TestHandleJobSuccessNotifiesAllSubscribers(t *testing.T) {
+	setupWorkerTest(t)
+	first := subscribe("user1", testVideoID)
+	second := subscribe("user2", testVideoID)
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return &SummaryResponse{VideoID: job.VideoID, Title: "Title", Summary: "Summary"}, nil
+	}
+
+	notified := handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+
+	assert.ElementsMatch(t, []string{"user1", "user2"}, notified)
+	for _, ch := range []chan []byte{first, second} {
+		msg := receive(ch)
+		assert.True(t, strings.HasPrefix(msg, "event: summary_complete\n"), msg)
+		assert.Contains(t, msg, `"summary":"Summary"`)
+	}
+	assert.False(t, isJobActive(testVideoID))
+}
+
+func TestCompleteJobRemovesJobExactlyOnce(t *testing.T) {
+	setupWorkerTest(t)
+	first := subscribe("user1", testVideoID)
+	second := subscribe("user2", testVideoID)
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1"}
+	resp := &SummaryResponse{VideoID: testVideoID, Summary: "Summary"}
+
+	// Several completions of the same job race for its entry; only one may find and notify it
+	const completions = 8
+	var wg sync.WaitGroup
+	var found atomic.Int32
+	start := make(chan struct{})
+	for i := 0; i < completions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			subscribers, ok := completeJob(job, resp, nil, "")
+			if ok {
+				found.Add(1)
+				assert.ElementsMatch(t, []string{"user1", "user2"}, subscribers)
+			} else {
+				assert.Empty(t, subscribers)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), found.Load(), "the job entry is removed exactly once")
+	assert.False(t, isJobActive(testVideoID))
+	for _, ch := range []chan []byte{first, second} {
+		assert.True(t, strings.HasPrefix(receive(ch), "event: summary_complete\n"))
+		assert.Empty(t, receive(ch), "each subscriber is notified once")
+	}
+
+	// A later completion of the same key, e.g. after handleJob, reports it as not found
+	subscribers, ok := completeJob(job, resp, nil, "")
+	assert.False(t, ok)
+	assert.Empty(t, subscribers)
+	assert.Empty(t, receive(first))
+}
+
+func TestHandleJobErrorNotifiesSubscribers(t *testing.T) {
+	setupWorkerTest(t)
+	ch := subscribe("user1", testVideoID)
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return nil, errors.New("transcript unavailable")
+	}
+
+	handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+
+	msg := receive(ch)
+	assert.True(t, strings.HasPrefix(msg, "event: summary_error\n"), msg)
+	assert.Contains(t, msg, "transcript unavailable")
+	assert.False(t, isJobActive(testVideoID))
+}
+
+func TestHandleJobReportsVideoWithoutCaptions(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Silent video", "duration": 60}`) // Downloads no subtitle files
+	processJob = processSummarizationJob
+	ch := subscribe("user1", testVideoID)
+
+	handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test"})
+
+	msg := receive(ch)
+	assert.True(t, strings.HasPrefix(msg, "event: summary_error\n"), msg)
+	assert.Contains(t, msg, `"error":"no_transcript"`)
+	assert.Contains(t, msg, `"message":"This video has no captions available."`)
+	assert.Contains(t, msg, `"code":"transcript"`)
+	assert.False(t, isJobActive(testVideoID))
+}
+
+func TestProcessSummarizationJobErrorsReportStage(t *testing.T) {
+	setupWorkerTest(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	fakeYtDlp(t, `not json`)
+	_, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test"})
+	assert.Equal(t, jobStageVideoInfo, jobErrorPayload(testVideoID, err, i18n.English)["code"])
+
+	fakeYtDlp(t, `{"title": "Video", "duration": 5}`)
+	transcript := [][]services.TranscriptItem{{{Text: "Hello", Start: 0, Duration: 5}}}
+	_, err = processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript})
+	payload := jobErrorPayload(testVideoID, err, i18n.English)
+	assert.Equal(t, jobStageSummarize, payload["code"])
+	assert.Contains(t, payload["error"], "failed to summarize transcript", "the message is kept")
+
+	_, hasCode := jobErrorPayload(testVideoID, errJobQueueFull, i18n.English)["code"]
+	assert.False(t, hasCode)
+}
+
+func TestJobErrorsUseTheJobMessageLanguage(t *testing.T) {
+	setupWorkerTest(t)
+	ch := subscribe("user1", testVideoID)
+
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1", MessageLanguage: i18n.Korean}
+	completeJob(job, nil, fmt.Errorf("failed to get transcript: %w", services.ErrNoTranscript), "")
+
+	msg := receive(ch)
+	assert.Contains(t, msg, "event: summary_error\n")
+	assert.Contains(t, msg, i18n.T(i18n.MsgNoTranscript, i18n.Korean))
+	assert.Equal(t, i18n.T(i18n.MsgJobQueueFull, i18n.Korean), jobErrorPayload(testVideoID, errJobQueueFull, i18n.Korean)["error"])
+}
+
+func TestHandleJobRecoversFromPanic(t *testing.T) {
+	setupWorkerTest(t)
+	ch := subscribe("user1", testVideoID)
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		panic("boom")
+	}
+
+	assert.NotPanics(t, func() {
+		notified := handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+		assert.Equal(t, []string{"user1"}, notified)
+	})
+
+	msg := receive(ch)
+	assert.True(t, strings.HasPrefix(msg, "event: summary_error\n"), msg)
+	assert.Contains(t, msg, "Server error during summarization.")
+	assert.False(t, isJobActive(testVideoID), "a panicking job must not stay registered")
+}
+
+func TestHandleJobWithoutSubscribers(t *testing.T) {
+	setupWorkerTest(t)
+	ch := make(chan []byte, 10)
+	clientChannels["user1"] = ch // Connected, but not subscribed to this video
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return &SummaryResponse{VideoID: job.VideoID}, nil
+	}
+
+	notified := handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+
+	assert.Empty(t, notified)
+	assert.Empty(t, receive(ch))
+}
+
+func TestHandleJobServesCachedSummary(t *testing.T) {
+	setupWorkerTest(t)
+	ch := subscribe("user1", testVideoID)
+
+	// Uses the real processing path: a cache hit must not need yt-dlp or OpenAI
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+		VideoID:    testVideoID,
+		Title:      "Cached title",
+		Summary:    "Cached summary",
+		Transcript: []services.TranscriptItem{{Text: "hello", Start: 0, Duration: 1}},
+	}))
+
+	handleJob(1, SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+
+	msg := receive(ch)
+	assert.True(t, strings.HasPrefix(msg, "event: summary_complete\n"), msg)
+	assert.Contains(t, msg, `"summary":"Cached summary"`)
+	assert.Contains(t, msg, `"cached":true`)
+
+	summaries, err := models.GetUserSummaries("user1", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) {
+		assert.Equal(t, testVideoID, summaries[0].VideoID)
+	}
+}
+
+func TestJobsForDifferentLanguagesAreNotDeduplicated(t *testing.T) {
+	setupWorkerTest(t)
+	korean := make(chan []byte, 10)
+	english := make(chan []byte, 10)
+	clientChannels["user1"] = korean
+	clientChannels["user2"] = english
+
+	koJob := SummarizationJob{VideoID: testVideoID, UserID: "user1", Language: "ko"}
+	enJob := SummarizationJob{VideoID: testVideoID, UserID: "user2", Language: "en"}
+	assert.True(t, subscribeToJob(koJob.key(), "user1", ""))
+	assert.True(t, subscribeToJob(enJob.key(), "user2", ""), "a different language must start its own job")
+	assert.False(t, subscribeToJob(jobKey(testVideoID, "EN"), "user3", ""), "the same language must join the active job")
+
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		return &SummaryResponse{VideoID: job.VideoID, Summary: "summary in " + job.Language}, nil
+	}
+
+	notified := handleJob(1, enJob)
+
+	assert.ElementsMatch(t, []string{"user2", "user3"}, notified)
+	assert.Contains(t, receive(english), `"summary":"summary in en"`)
+	assert.Empty(t, receive(korean), "the Korean subscriber must not receive the English result")
+	assert.True(t, isJobActive(koJob.key()))
+	assert.False(t, isJobActive(enJob.key()))
+}
+
+func TestJobKeyDefaultsToVideoID(t *testing.T) {
+	assert.Equal(t, testVideoID, jobKey(testVideoID, ""))
+	assert.Equal(t, testVideoID, jobKey(testVideoID, defaultSummaryLanguage))
+	assert.Equal(t, testVideoID+".en", jobKey(testVideoID, " EN "))
+	assert.Equal(t, models.CacheKey(testVideoID, "en"), jobKey(testVideoID, "en"), "single-language jobs share the cache key")
+	assert.Equal(t, testVideoID+".ko+en", jobKey(testVideoID, "ko", "en", "EN"))
+}
+
+func TestProcessMultiLanguageJobServesCachedLanguages(t *testing.T) {
+	setupWorkerTest(t)
+	for language, summary := range map[string]string{"ko": "한국어 요약", "en": "English summary"} {
+		assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+			VideoID:    testVideoID,
+			Language:   language,
+			Title:      "Title",
+			Summary:    summary,
+			Transcript: []services.TranscriptItem{{Text: "hello", Start: 0, Duration: 1}},
+		}))
+	}
+
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", Languages: []string{"en", "ko"}})
+	assert.NoError(t, err)
+	assert.True(t, resp.Cached)
+	assert.Equal(t, "English summary", resp.Summary, "the first requested language is the main summary")
+	assert.Equal(t, map[string]string{"en": "English summary", "ko": "한국어 요약"}, resp.Summaries)
+}
+
+// fakeYtDlp puts a yt-dlp script on PATH that prints the given video info JSON
+func fakeYtDlp(t *testing.T, infoJSON string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat <<'EOF'\n" + infoJSON + "\nEOF\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "yt-dlp"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("VIDEOINFO_CACHE_TTL_SECONDS", "0")
+}
+
+func TestProcessSummarizationJobReusesSummaryOfIdenticalTranscript(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Mirror", "channel": "Channel", "duration": 5}`)
+	t.Setenv("DEDUP_BY_TRANSCRIPT_HASH", "true")
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Shared summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	transcript := func(text string) [][]services.TranscriptItem {
+		return [][]services.TranscriptItem{{{Text: text, Start: 0, Duration: 5}}}
+	}
+	const mirrorVideoID = "aaaaaaaaaaa"
+
+	first, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript("Hello  world")})
+	assert.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: mirrorVideoID, UserID: "user2", APIKey: "sk-test", Transcript: transcript("hello world")})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "the mirror's transcript must not be summarized again")
+	assert.Equal(t, mirrorVideoID, second.VideoID)
+	assert.Contains(t, second.Summary, "Shared summary")
+
+	item, found := summaryCache.Get(mirrorVideoID)
+	if assert.True(t, found, "the reused summary is cached under the new video ID") {
+		assert.Equal(t, testVideoID, item.ReusedFrom)
+	}
+
+	// Regenerating the mirror summarizes its transcript again
+	assert.NoError(t, summaryCache.Delete(mirrorVideoID))
+	third, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: mirrorVideoID, UserID: "user2", APIKey: "sk-test", Transcript: transcript("hello world"), Regenerate: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.False(t, third.Cached)
+}
+
+func TestProcessSummarizationJobReturnsTimestamps(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "duration": 4000}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:05] Intro.\n[01:02:03] Outro."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	transcript := [][]services.TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}}
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript})
+	assert.NoError(t, err)
+	expected := []models.Timestamp{{Time: 5, Text: "Intro."}, {Time: 3723, Text: "Outro."}}
+	assert.Equal(t, expected, resp.Timestamps)
+
+	item, _ := summaryCache.Get(testVideoID)
+	assert.Equal(t, expected, item.Timestamps)
+	assert.Equal(t, expected, newCachedSummaryResponse(item, nil).Timestamps)
+
+	// Summaries cached before timestamps were stored get them on the way out
+	item.Timestamps = nil
+	assert.Equal(t, expected, newCachedSummaryResponse(item, nil).Timestamps)
+}
+
+func TestProcessSummarizationJobChunksAlongChapters(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "duration": 300, "chapters": [{"start_time": 0, "end_time": 60, "title": "Intro"}, {"start_time": 60, "end_time": 300, "title": "Demo"}]}`)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Point."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("USE_VIDEO_CHAPTERS", "true")
+	t.Setenv("STRUCTURED_OUTPUT", "true")
+
+	// One time-based chunk covering both chapters
+	transcript := [][]services.TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}, {Text: "demo", Start: 70, Duration: 5}}}
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "one chunk per chapter")
+	assert.Contains(t, resp.Summary, "## Intro")
+	assert.Contains(t, resp.Summary, "## Demo")
+
+	if assert.Len(t, resp.Chunks, 2) {
+		assert.Equal(t, "Demo", resp.Chunks[1].Chapter)
+	}
+}
+
+func TestProcessSummarizationJobRegeneratesOtherStyle(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "duration": 5}`)
+	var systemPrompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request services.GPTRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		systemPrompts = append(systemPrompts, request.Messages[0].Content)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "A short paragraph."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	transcript := [][]services.TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}}
+	assert.NoError(t, summaryCache.Set(testVideoID, "Video", "[00:00] Timestamped summary", nil, transcript[0]))
+
+	// The same style is served from the cache
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", Style: services.StyleTimestamped})
+	assert.NoError(t, err)
+	assert.True(t, resp.Cached)
+
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript, Style: services.StyleTLDR}
+	assert.NotEqual(t, SummarizationJob{VideoID: testVideoID}.key(), job.key(), "other styles must not share a job")
+	resp, err = processSummarizationJob(context.Background(), job)
+	assert.NoError(t, err)
+	assert.False(t, resp.Cached)
+	assert.Equal(t, services.StyleTLDR, resp.Style)
+	if assert.Len(t, systemPrompts, 1) {
+		assert.Contains(t, systemPrompts[0], "TL;DR")
+	}
+
+	item, _ := summaryCache.Get(testVideoID)
+	assert.Equal(t, services.StyleTLDR, item.SummaryStyle())
+	assert.Contains(t, item.Summary, "A short paragraph.")
+}
+
+func TestSummaryResponsesReportCost(t *testing.T) {
+	item := &models.CacheItem{VideoID: testVideoID, Summary: "summary", Usage: &services.TokenUsage{PromptTokens: 1000, CompletionTokens: 200}, CostUSD: 0.0012}
+
+	body, err := json.Marshal(newCachedSummaryResponse(item, nil))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"usage":{"promptTokens":1000,"completionTokens":200}`)
+	assert.Contains(t, string(body), `"costUsd":0.0012`)
+
+	t.Setenv("EXPOSE_SUMMARY_COST", "false")
+	body, err = json.Marshal(newCachedSummaryResponse(item, nil))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "usage")
+	assert.NotContains(t, string(body), "costUsd")
+}
+
+func TestProcessSummarizationJobReturnsVideoMetadata(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "upload_date": "20240131", "duration": 300, "thumbnail": "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:05] Intro."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	transcript := [][]services.TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}}
+	resp, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript})
+	assert.NoError(t, err)
+
+	item, _ := summaryCache.Get(testVideoID)
+	for _, got := range []*SummaryResponse{resp, newCachedSummaryResponse(item, nil)} {
+		assert.Equal(t, "Channel", got.Channel)
+		assert.Equal(t, "20240131", got.UploadDate)
+		assert.Equal(t, 300, got.Duration)
+		assert.Equal(t, "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg", got.Thumbnail)
+	}
+
+	// Items cached before the metadata was stored still decode; the fields are just left out
+	var old models.CacheItem
+	assert.NoError(t, json.Unmarshal([]byte(`{"videoId": "dQw4w9WgXcQ", "title": "Video", "summary": "[00:05] Intro.", "createdAt": "2024-01-31T00:00:00Z"}`), &old))
+	body, err := json.Marshal(newCachedSummaryResponse(&old, nil))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "thumbnail")
+	assert.NotContains(t, string(body), "uploadDate")
+}
+
+func TestProcessSummarizationJobStreamsProgressToSubscribers(t *testing.T) {
+	setupWorkerTest(t)
+	fakeYtDlp(t, `{"title": "Video", "channel": "Channel", "duration": 805}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Part"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	ch := subscribe("user1", testVideoID)
+	transcript := [][]services.TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "second", Start: 400, Duration: 5}},
+	}
+	_, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1", APIKey: "sk-test", Transcript: transcript})
+	assert.NoError(t, err)
+
+	first := receive(ch)
+	assert.True(t, strings.HasPrefix(first, "event: summary_progress\n"), first)
+	assert.Contains(t, first, `"chunk":1,"totalChunks":2,"progress":0.5`)
+	second := receive(ch)
+	assert.Contains(t, second, `"chunk":2,"totalChunks":2,"progress":1`)
+	assert.Empty(t, receive(ch), "summary_complete is sent by completeJob")
+}
+
+func TestGetSummaryStatusHandler(t *testing.T) {
+	setupWorkerTest(t)
+	originalQueue := jobQueue
+	jobQueue = make(chan SummarizationJob, 1)
+	t.Cleanup(func() { jobQueue = originalQueue })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/summary/status/:videoId", GetSummaryStatusHandler)
+	status := func(path string) string {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	assert.JSONEq(t, `{"videoId": "`+testVideoID+`", "state": "unknown", "subscribers": 0}`, status("/api/summary/status/"+testVideoID))
+
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1"}
+	subscribe("user1", testVideoID)
+	subscribe("user2", testVideoID)
+	assert.True(t, tryEnqueueJob(job))
+	assert.JSONEq(t, `{"videoId": "`+testVideoID+`", "state": "queued", "subscribers": 2}`, status("/api/summary/status/"+testVideoID))
+
+	// A worker picks the job up
+	var picked SummarizationJob
+	processJob = func(ctx context.Context, job SummarizationJob) (*SummaryResponse, error) {
+		picked = job
+		assert.Contains(t, status("/api/summary/status/"+testVideoID), `"state":"active"`)
+		assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: job.VideoID, Summary: "done"}))
+		return &SummaryResponse{VideoID: job.VideoID}, nil
+	}
+	handleJob(1, <-jobQueue)
+	assert.Equal(t, testVideoID, picked.VideoID)
+	assert.JSONEq(t, `{"videoId": "`+testVideoID+`", "state": "cached", "subscribers": 0}`, status("/api/summary/status/"+testVideoID))
+
+	assert.JSONEq(t, `{"videoId": "`+testVideoID+`", "language": "en", "state": "unknown", "subscribers": 0}`, status("/api/summary/status/"+testVideoID+"?language=en"))
+	assert.Contains(t, status("/api/summary/status/invalid"), "Invalid video ID")
+
+	// Error messages follow the client's Accept-Language
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/summary/status/invalid", nil)
+	req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en;q=0.8")
+	router.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), "유효하지 않은 영상 ID입니다.")
+}
+
+func TestSummarizeOptionsOnlyUsesModelWithOwnAPIKey(t *testing.T) {
+	temperature := 0.7
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1", Model: "gpt-4o", Temperature: &temperature}
+
+	opts := summarizeOptions(job, "en", "")
+	assert.Empty(t, opts.Model, "a job on the server key (e.g. restored from the journal) uses the configured model")
+	assert.Equal(t, &temperature, opts.Temperature)
+	assert.Equal(t, "en", opts.Language)
+
+	job.APIKey = "sk-user"
+	assert.Equal(t, "gpt-4o", summarizeOptions(job, "en", "").Model)
+}
+
+func TestExportSummaryHandler(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/summary/:videoId/export", ExportSummaryHandler)
+	export := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, export("/api/summary/"+testVideoID+"/export?format=md").Code)
+
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+		VideoID:     testVideoID,
+		Title:       "Export title",
+		Summary:     "[00:05] Intro\n- First point\n[01:02:03] Outro",
+		Transcript:  []services.TranscriptItem{{Text: "Hello", Start: 5, Duration: 2}},
+		RequestedBy: "user1",
+	}))
+	rec := export("/api/summary/" + testVideoID + "/export?format=md")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "# Export title\n\n"+
+		"https://www.youtube.com/watch?v="+testVideoID+"\n\n"+
+		"[00:05](https://youtu.be/"+testVideoID+"?t=5) Intro\n"+
+		"- First point\n"+
+		"[01:02:03](https://youtu.be/"+testVideoID+"?t=3723) Outro\n", rec.Body.String())
+
+	assert.Equal(t, `attachment; filename="`+testVideoID+`.md"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, http.StatusOK, export("/api/summary/"+testVideoID+"/export").Code, "md is the default format")
+
+	rec = export("/api/summary/" + testVideoID + "/export?format=txt")
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="`+testVideoID+`.txt"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "Export title\n\n[00:05] Intro\n- First point\n[01:02:03] Outro\n", rec.Body.String())
+
+	rec = export("/api/summary/" + testVideoID + "/export?format=json")
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="`+testVideoID+`.json"`, rec.Header().Get("Content-Disposition"))
+	var exported models.CacheItem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &exported))
+	assert.Equal(t, "Export title", exported.Title)
+	assert.Len(t, exported.Transcript, 1)
+	assert.Empty(t, exported.RequestedBy, "the requester's ID is not exported")
+
+	rec = export("/api/summary/" + testVideoID + "/export?format=pdf")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"supportedFormats":["md","txt","json"]`)
+
+	// Summaries in other languages are exported with ?language=
+	assert.Equal(t, http.StatusNotFound, export("/api/summary/"+testVideoID+"/export?language=en").Code)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Language: "en", Title: "English title", Summary: "[00:05] Intro"}))
+	rec = export("/api/summary/" + testVideoID + "/export?format=txt&language=EN")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "English title\n\n[00:05] Intro\n", rec.Body.String())
+	assert.Equal(t, `attachment; filename="`+testVideoID+`.en.txt"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, http.StatusBadRequest, export("/api/summary/"+testVideoID+"/export?language=../en").Code)
+}
+
+func TestGetRawSummaryHandlerTakesLanguage(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/summary/:videoId/raw", GetRawSummaryHandler)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Language: "en", Summary: "clean", RawSummary: "raw"}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/summary/"+testVideoID+"/raw", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "only the English summary is cached")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/summary/"+testVideoID+"/raw?language=en", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"rawSummary":"raw"`)
+}
+
+func TestRecentSummariesUseConfiguredCacheDir(t *testing.T) {
+	originalCache := summaryCache
+	t.Cleanup(func() { summaryCache = originalCache })
+	t.Setenv("CACHE_DIR", t.TempDir())
+	t.Setenv("CACHE_SWEEP_INTERVAL_MINUTES", "0")
+	assert.NoError(t, InitCache())
+	assert.NoError(t, summaryCache.Set(testVideoID, "Cached elsewhere", "[00:00] Summary", nil, nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/recent", GetRecentSummariesHandler)
+	router.GET("/recent/page", GetRecentSummariesPageHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recent", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summaries []models.VideoSummary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	assert.Equal(t, []models.VideoSummary{{VideoTitle: "Cached elsewhere", VideoID: testVideoID}}, summaries)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recent/page?limit=5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	assert.Contains(t, w.Body.String(), `"video_title":"Cached elsewhere"`)
+}
+
+func TestStreamSSEMessagesWritesHeartbeats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/summary/events", nil).WithContext(ctx)
+
+	messageChan := make(chan []byte, 1)
+	messageChan <- []byte("event: summary_complete\ndata: {}\n\n")
+	done := make(chan struct{})
+	go func() {
+		streamSSEMessages(c, w, "user1", messageChan, 10*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "event: summary_complete"), body)
+	assert.Contains(t, body, ": keepalive\n\n")
+}
+
+// sessionStore restores fixed sessions, so that handlers find a logged-in user for the session_id cookie
+type sessionStore []*auth.Session
+
+func (s sessionStore) Save(*auth.Session) error          { return nil }
+func (s sessionStore) Delete(string) error               { return nil }
+func (s sessionStore) LoadAll() ([]*auth.Session, error) { return s, nil }
+
+// loginTestUser creates a session for userID whose session_id cookie is "session-" + userID
+func loginTestUser(t *testing.T, userID string) {
+	t.Helper()
+	auth.SetSessionStore(sessionStore{{
+		ID:        "session-" + userID,
+		UserInfo:  &auth.UserInfo{ID: userID},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}})
+	t.Cleanup(func() { auth.SetSessionStore(nil) })
+}
+
+// setupSummaryRequestTest logs in userID and returns a router serving POST /api/summary.
+// Queued jobs stay in the returned queue.
+func setupSummaryRequestTest(t *testing.T, userID string) (*gin.Engine, chan SummarizationJob) {
+	t.Helper()
+	setupWorkerTest(t)
+	loginTestUser(t, userID)
+	models.SetUserAPIKeyDirectory(t.TempDir())
+	policy := services.GetAPIKeyPolicy()
+	originalPolicy, originalQueue := policy.GetApiKeyPolicy(), jobQueue
+	policy.SetPolicy(services.PolicyAllUsers)
+	jobQueue = make(chan SummarizationJob, 10)
+	t.Cleanup(func() {
+		policy.SetPolicy(originalPolicy)
+		jobQueue = originalQueue
+		models.SetUserAPIKeyDirectory("users/keys")
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/summary", HandleSummaryRequest)
+	return router, jobQueue
+}
+
+// postSummary sends a summary request for testVideoID as the user logged in by setupSummaryRequestTest
+func postSummary(router *gin.Engine, userID string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/summary", strings.NewReader(`{"url": "https://www.youtube.com/watch?v=`+testVideoID+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-" + userID})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleSummaryRequestRejectsOversizedBody(t *testing.T) {
+	router, queue := setupSummaryRequestTest(t, "user1")
+	t.Setenv("MAX_REQUEST_BODY_BYTES", "1024")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/summary", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-user1"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"url": "https://www.youtube.com/watch?v=` + testVideoID + `", "padding": "` + strings.Repeat("a", 2048) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "at most 1024 bytes")
+	assert.Empty(t, queue)
+
+	// Bodies within the limit are still bound, and malformed ones are a 400
+	assert.Equal(t, http.StatusAccepted, post(`{"url": "https://www.youtube.com/watch?v=`+testVideoID+`"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"url": `).Code)
+}
+
+func TestHandleValidateURLRejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/validate-url", HandleValidateURL)
+	t.Setenv("MAX_REQUEST_BODY_BYTES", "1024")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/validate-url", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"url": "https://www.youtube.com/watch?v=` + testVideoID + `", "padding": "` + strings.Repeat("a", 2048) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"request_too_large"`)
+	assert.Contains(t, w.Body.String(), `"valid":false`)
+
+	assert.Equal(t, http.StatusOK, post(`{"url": "https://www.youtube.com/watch?v=`+testVideoID+`"}`).Code)
+	w = post(`{"url": `)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"invalid_request"`)
+}
+
+func TestHandleSummaryRequestRejectsNonYouTubeHosts(t *testing.T) {
+	router, queue := setupSummaryRequestTest(t, "user1")
+
+	for _, videoURL := range []string{
+		"https://evil.example.com/youtube.com/watch?v=" + testVideoID,
+		"https://youtube.com.evil.example.com/watch?v=" + testVideoID,
+		"ftp://youtu.be/" + testVideoID,
+	} {
+		body, _ := json.Marshal(map[string]string{"url": videoURL})
+		req := httptest.NewRequest(http.MethodPost, "/api/summary", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-user1"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, videoURL)
+		assert.Contains(t, w.Body.String(), `"field":"url"`, videoURL)
+		assert.Contains(t, w.Body.String(), "not a YouTube video URL", videoURL)
+	}
+	assert.Empty(t, queue)
+}
+
+func TestHandleSummaryRequestQueuesSimultaneousRequestsOnce(t *testing.T) {
+	router, queue := setupSummaryRequestTest(t, "user1")
+
+	const requests = 2
+	start := make(chan struct{})
+	responses := make(chan *httptest.ResponseRecorder, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			<-start
+			responses <- postSummary(router, "user1", nil)
+		}()
+	}
+	close(start)
+
+	var queuedMessages int
+	for i := 0; i < requests; i++ {
+		w := <-responses
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		if strings.Contains(w.Body.String(), "received and queued") {
+			queuedMessages++
+		}
+	}
+	assert.Equal(t, 1, queuedMessages)
+	assert.Len(t, queue, 1, "exactly one job is queued")
+}
+
+func TestSubscribeUnlessCachedFindsSummaryCachedInTheMeantime(t *testing.T) {
+	setupWorkerTest(t)
+	req := &summaryJobRequest{userID: "user1", videoID: testVideoID, languages: []string{services.DefaultSummaryLanguage}, style: services.DefaultSummaryStyle}
+	hit, _ := lookupCachedSummary(req)
+	assert.Nil(t, hit)
+
+	// The job of an earlier request finishes between the first lookup and the registration
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{VideoID: testVideoID, Summary: "summary"}))
+	job := SummarizationJob{VideoID: testVideoID, UserID: "user1", Languages: req.languages}
+	hit, isNewJob := subscribeUnlessCached(req, &job)
+	if assert.NotNil(t, hit) {
+		assert.Equal(t, "summary", hit.item.Summary)
+	}
+	assert.False(t, isNewJob)
+	activeVideoJobsMutex.RLock()
+	assert.Empty(t, activeVideoJobs)
+	activeVideoJobsMutex.RUnlock()
+}
+
+func TestMergeTranscriptInterval(t *testing.T) {
+	transcript := []services.TranscriptItem{
+		{Text: "one", Start: 0, Duration: 2},
+		{Text: "two", Start: 9.5, Duration: 2},
+		{Text: "three", Start: 10, Duration: 2}, // Exactly at the interval: starts a new item
+		{Text: "four", Start: 15, Duration: 1},
+	}
+
+	assert.Equal(t, []services.TranscriptItem{
+		{Text: "one two", Start: 0, Duration: 11.5},
+		{Text: "three four", Start: 10, Duration: 6},
+	}, mergeTranscriptItems(transcript, 10))
+	assert.Equal(t, transcript, mergeTranscriptItems(transcript, 0), "0 keeps the segments")
+
+	t.Setenv("TRANSCRIPT_MERGE_INTERVAL_SECONDS", "")
+	assert.Len(t, MergeTranscript(transcript), 2, "the default interval is 15 seconds")
+	t.Setenv("TRANSCRIPT_MERGE_INTERVAL_SECONDS", "60")
+	assert.Equal(t, []services.TranscriptItem{{Text: "one two three four", Start: 0, Duration: 16}}, MergeTranscript(transcript))
+}
+
+func TestMergeTranscriptSeparatesTexts(t *testing.T) {
+	merged := mergeTranscriptItems([]services.TranscriptItem{
+		{Text: " glued", Start: 0},
+		{Text: "words", Start: 1},
+		{Text: "trailing ", Start: 2},
+		{Text: " space", Start: 3},
+		{Text: "", Start: 4},
+		{Text: ", then", Start: 5},
+		{Text: "the end", Start: 6},
+		{Text: ". ", Start: 7},
+		{Text: "\nnext ", Start: 20},
+	}, 15)
+	assert.Equal(t, "glued words trailing space, then the end.", merged[0].Text)
+	assert.Equal(t, "next", merged[1].Text, "texts are trimmed")
+}
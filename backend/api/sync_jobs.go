@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultSyncMaxTranscriptChars is the largest transcript (in characters) summarized inside the HTTP request
+	defaultSyncMaxTranscriptChars = 5000
+	// defaultSyncJobTimeoutSeconds bounds how long the HTTP request is held open for a synchronous job
+	defaultSyncJobTimeoutSeconds = 30
+)
+
+// syncOutcome is handed from a synchronous job runner to the HTTP handler waiting for it.
+type syncOutcome struct {
+	resp      *SummaryResponse
+	err       error
+	queued    bool // The transcript was too large, so the job went to the worker pool instead
+	queueFull bool // The transcript was too large and the worker pool queue was full
+}
+
+// syncSmallJobsEnabled reports whether short videos should be summarized within the HTTP request.
+func syncSmallJobsEnabled() bool {
+	return services.GetEnvBool("SYNC_SMALL_JOBS", false)
+}
+
+// transcriptSize returns the total number of characters of transcript text across all chunks.
+func transcriptSize(chunks [][]services.TranscriptItem) int {
+	size := 0
+	for _, item := range services.MergeTranscriptChunks(chunks) {
+		size += len(item.Text)
+	}
+	return size
+}
+
+// handleJobSynchronously fetches the transcript of a newly registered job and, if it is small
+// enough, summarizes it right away and answers with 200 and the full SummaryResponse.
+// Larger transcripts are queued for the worker pool (202). If the work takes longer than
+// SYNC_JOB_TIMEOUT_SECONDS the handler answers 202 and the result is delivered via SSE instead.
+func handleJobSynchronously(c *gin.Context, job SummarizationJob) {
+	timeout := time.Duration(services.GetEnvInt("SYNC_JOB_TIMEOUT_SECONDS", defaultSyncJobTimeoutSeconds)) * time.Second
+
+	// Unbuffered on purpose: the runner can only hand over its outcome while we are still waiting.
+	outcomes := make(chan syncOutcome)
+	go runSmallJob(job, outcomes)
+
+	select {
+	case outcome := <-outcomes:
+		switch {
+		case outcome.queued:
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":  localize(c, i18n.MsgSummaryQueued),
+				"video_id": job.VideoID,
+			})
+		case outcome.queueFull:
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":    localizeError(c, errJobQueueFull),
+				"video_id": job.VideoID,
+			})
+		case errors.Is(outcome.err, services.ErrNoTranscript):
+			c.JSON(http.StatusUnprocessableEntity, jobErrorPayload(job.VideoID, outcome.err, job.MessageLanguage))
+		case outcome.err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":    localizeError(c, outcome.err),
+				"video_id": job.VideoID,
+			})
+		default:
+			c.JSON(http.StatusOK, outcome.resp)
+		}
+	case <-time.After(timeout):
+		logInfo("HandleSummaryRequest: Synchronous summarization for VideoID %s exceeded %s. Continuing in the background.", job.VideoID, timeout)
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  localize(c, i18n.MsgSummaryInBackground),
+			"video_id": job.VideoID,
+		})
+	}
+}
+
+// runSmallJob does the work behind handleJobSynchronously. It always finishes the job itself,
+// either by summarizing it or by queuing it, so nothing is lost if the handler stops waiting.
+func runSmallJob(job SummarizationJob, outcomes chan<- syncOutcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			logError("Panic during synchronous processing of VideoID: %s, UserID: %s. Panic: %v", job.VideoID, job.UserID, r)
+			err := i18n.NewError(i18n.MsgSummarizationFailed)
+			completeJob(job, nil, err, skipIfDelivered(outcomes, syncOutcome{err: err}, job.UserID))
+		}
+	}()
+
+	ctx, span := services.StartSpan(jobContext(job), "process job", services.VideoIDAttr(job.VideoID), services.RequestIDAttr(job.RequestID))
+	defer span.End()
+
+	chunks, err := services.GetTranscript(ctx, job.VideoID, transcriptChunkSeconds())
+	if err != nil {
+		logError("VideoID %s, UserID %s: Failed to get video transcript: %v", job.VideoID, job.UserID, err)
+		err = fmt.Errorf("failed to get transcript for VideoID %s: %w", job.VideoID, err)
+		completeJob(job, nil, err, skipIfDelivered(outcomes, syncOutcome{err: err}, job.UserID))
+		return
+	}
+	job.Transcript = chunks
+
+	maxChars := services.GetEnvInt("SYNC_MAX_TRANSCRIPT_CHARS", defaultSyncMaxTranscriptChars)
+	if size := transcriptSize(chunks); size > maxChars {
+		logInfo("VideoID %s transcript has %d characters (limit %d). Handing job to the worker pool.", job.VideoID, size, maxChars)
+		if tryEnqueueJob(job) {
+			skipIfDelivered(outcomes, syncOutcome{queued: true}, job.UserID)
+			return
+		}
+		completeJob(job, nil, errJobQueueFull, skipIfDelivered(outcomes, syncOutcome{queueFull: true}, job.UserID))
+		return
+	}
+
+	logInfo("Processing VideoID %s synchronously for UserID %s.", job.VideoID, job.UserID)
+	summaryResp, err := processJob(ctx, job)
+	completeJob(job, summaryResp, err, skipIfDelivered(outcomes, syncOutcome{resp: summaryResp, err: err}, job.UserID))
+}
+
+// skipIfDelivered tries to hand the outcome to the waiting handler. If it was delivered the
+// requester already has the result, so their user ID is returned to be skipped by completeJob.
+func skipIfDelivered(outcomes chan<- syncOutcome, outcome syncOutcome, requesterID string) string {
+	select {
+	case outcomes <- outcome:
+		return requesterID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDHeader carries the request ID in requests and responses
+const requestIDHeader = "X-Request-ID"
+
+// requestIDFor returns the request ID sent by the client, or a newly generated one
+func requestIDFor(c *gin.Context) string {
+	if id := c.GetHeader(requestIDHeader); id != "" && len(id) <= 128 {
+		return id
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// requestContext returns the request's context, continuing a trace the client may have started
+func requestContext(c *gin.Context) context.Context {
+	return otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+}
+
+// injectTraceContext serializes the span in ctx so it can travel with a job through the queue.
+// Channels don't carry contexts, and the request context is canceled once the handler returns.
+func injectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// jobContext restores the trace context a job was queued with
+func jobContext(job SummarizationJob) context.Context {
+	return otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(job.TraceCarrier))
+}
+
+// recordQueueWait adds a span covering the time the job spent waiting for a worker
+func recordQueueWait(ctx context.Context, job SummarizationJob) {
+	if job.EnqueuedAt.IsZero() {
+		return
+	}
+	_, span := services.Tracer().Start(ctx, "queue wait",
+		trace.WithTimestamp(job.EnqueuedAt),
+		trace.WithAttributes(services.VideoIDAttr(job.VideoID), services.RequestIDAttr(job.RequestID)),
+	)
+	span.End(trace.WithTimestamp(time.Now()))
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestJobContextCarriesTraceThroughQueue(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	originalProvider, originalPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(originalProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+	})
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "handler")
+	defer span.End()
+
+	job := SummarizationJob{VideoID: "dQw4w9WgXcQ", TraceCarrier: injectTraceContext(ctx)}
+	restored := trace.SpanContextFromContext(jobContext(job))
+
+	assert.True(t, restored.IsValid())
+	assert.Equal(t, span.SpanContext().TraceID(), restored.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), restored.SpanID())
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// HandleTranscript returns the transcript of a video without summarizing it. The transcript of a
+// cached summary is returned if there is one; otherwise it is fetched with yt-dlp, which counts
+// against the caption lookup rate limit. ?format=json|vtt|srt (default json) selects the format;
+// vtt and srt are returned as subtitle file downloads.
+func HandleTranscript(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgNotAuthenticated)})
+		return
+	}
+
+	videoURL := strings.TrimSpace(c.Query("url"))
+	if videoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgURLRequired)})
+		return
+	}
+	videoID, err := services.GetVideoID(videoURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, videoURLErrorResponse(err, i18n.RequestLanguage(c.Request)))
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	switch format {
+	case "json", "vtt", "srt":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgMustBeOneOf, "format", "json, vtt, srt")})
+		return
+	}
+
+	transcript, cached := cachedTranscript(videoID)
+	if !cached {
+		if !captionsLimiter.allow(userInfo.ID) {
+			c.Header("Retry-After", "60")
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": localize(c, i18n.MsgTooManyCaptionLookups)})
+			return
+		}
+
+		chunks, err := services.GetTranscript(requestContext(c), videoID, 0)
+		if errors.Is(err, services.ErrNoTranscript) {
+			c.JSON(http.StatusNotFound, gin.H{"error": noTranscriptErrorCode, "message": localize(c, i18n.MsgNoTranscript), "videoId": videoID})
+			return
+		}
+		if err != nil {
+			logError("HandleTranscript: VideoID %s: %v", videoID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": localize(c, i18n.MsgTranscriptFailed), "videoId": videoID})
+			return
+		}
+		transcript = services.MergeTranscriptChunks(chunks)
+		services.SortTranscriptItemsByTime(transcript)
+	}
+
+	writeTranscriptResponse(c, videoID, transcript, cached, format)
+}
+
+// cachedTranscript returns the transcript stored with a cached summary of the video, if any
+func cachedTranscript(videoID string) ([]services.TranscriptItem, bool) {
+	if summaryCache == nil {
+		return nil, false
+	}
+	item, found := summaryCache.Get(videoID)
+	if !found || len(item.Transcript) == 0 {
+		return nil, false
+	}
+	return item.Transcript, true
+}
+
+// writeTranscriptResponse writes a transcript in the requested format. JSON responses merge the
+// items into the same longer segments as summary responses; subtitle files keep the original cues.
+func writeTranscriptResponse(c *gin.Context, videoID string, transcript []services.TranscriptItem, cached bool, format string) {
+	var contentType, body string
+	switch format {
+	case "vtt":
+		contentType, body = "text/vtt; charset=utf-8", services.TranscriptToVTT(transcript)
+	case "srt":
+		contentType, body = "application/x-subrip; charset=utf-8", services.TranscriptToSRT(transcript)
+	default:
+		merged := MergeTranscript(transcript)
+		if merged == nil {
+			merged = []services.TranscriptItem{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"videoId":    videoID,
+			"cached":     cached,
+			"transcript": merged,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, videoID, format))
+	c.Data(http.StatusOK, contentType, []byte(body))
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTranscriptResponse(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	assert.NoError(t, summaryCache.SetItem(&models.CacheItem{
+		VideoID: testVideoID,
+		Transcript: []services.TranscriptItem{
+			{Text: "hello", Start: 1.5, Duration: 2},
+			{Text: "world", Start: 3.5, Duration: 3661},
+		},
+	}))
+	transcript, cached := cachedTranscript(testVideoID)
+	assert.True(t, cached)
+	_, cached = cachedTranscript("aaaaaaaaaaa")
+	assert.False(t, cached)
+
+	write := func(format string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		writeTranscriptResponse(c, testVideoID, transcript, true, format)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	rec := write("json")
+	assert.JSONEq(t, `{"videoId": "dQw4w9WgXcQ", "cached": true, "transcript": [{"text": "hello world", "start": 1.5, "duration": 3663}]}`, rec.Body.String())
+
+	rec = write("srt")
+	assert.Equal(t, "application/x-subrip; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="dQw4w9WgXcQ.srt"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "1\n00:00:01,500 --> 00:00:03,500\nhello\n\n2\n00:00:03,500 --> 01:01:04,500\nworld\n\n", rec.Body.String())
+
+	rec = write("vtt")
+	assert.Equal(t, "text/vtt; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "WEBVTT\n\n00:00:01.500 --> 00:00:03.500\nhello\n\n")
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// validationErrorCode is the error code of responses rejecting a request field
+const validationErrorCode = "VALIDATION_ERROR"
+
+// maxURLFieldLength bounds URL fields, well above any real YouTube or callback URL
+const maxURLFieldLength = 2048
+
+// defaultMaxRequestBodyBytes bounds JSON request bodies, well above any real summary request
+const defaultMaxRequestBodyBytes = 64 << 10
+
+// defaultMaxSummaryLanguages bounds how many languages a single request may ask for
+const defaultMaxSummaryLanguages = 3
+
+// defaultMaxPromptFieldLength bounds free-text fields that end up in the prompt
+const defaultMaxPromptFieldLength = 500
+
+// maxModelFieldLength bounds the model field, well above any real model ID
+const maxModelFieldLength = 100
+
+// modelPattern matches OpenAI model IDs such as "gpt-4o-mini" or "ft:gpt-4o-mini:org::id"
+var modelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/-]*$`)
+
+// validationError names the request field that failed validation and why. Reason is usually an
+// i18n.Localizer, so it can be given in the client's language.
+type validationError struct {
+	Field  string
+	Reason error
+}
+
+// newValidationError returns a *validationError with the reason of an i18n code
+func newValidationError(field, code string, args ...any) *validationError {
+	return &validationError{Field: field, Reason: i18n.NewError(code, args...)}
+}
+
+func (e *validationError) Error() string {
+	return e.Field + ": " + e.Reason.Error()
+}
+
+// response returns the body of the 400 response for the error in lang
+func (e *validationError) response(lang string) gin.H {
+	return gin.H{
+		"error": i18n.T(i18n.MsgInvalidField, lang, e.Field, i18n.Message(e.Reason, lang)),
+		"code":  validationErrorCode,
+		"field": e.Field,
+	}
+}
+
+// videoURLErrorResponse returns the 400 response for a url field rejected by services.GetVideoID,
+// whose *services.InvalidVideoURLError gives the reason
+func videoURLErrorResponse(err error, lang string) gin.H {
+	return (&validationError{Field: "url", Reason: err}).response(lang)
+}
+
+// maxRequestBodyBytes returns the request body size limit configured via MAX_REQUEST_BODY_BYTES
+func maxRequestBodyBytes() int64 {
+	if n := services.GetEnvInt("MAX_REQUEST_BODY_BYTES", defaultMaxRequestBodyBytes); n > 0 {
+		return int64(n)
+	}
+	return defaultMaxRequestBodyBytes
+}
+
+// bindJSONBody binds a JSON request body of at most MAX_REQUEST_BODY_BYTES into obj. Larger bodies are
+// rejected with 413 without being read further, other binding errors with 400.
+func bindJSONBody(c *gin.Context, obj any) bool {
+	status, _, message := decodeJSONBody(c, obj)
+	if status == 0 {
+		return true
+	}
+	c.JSON(status, gin.H{"error": message})
+	return false
+}
+
+// decodeJSONBody binds a JSON request body of at most MAX_REQUEST_BODY_BYTES into obj without writing a
+// response. On failure it returns the HTTP status, the i18n message code and the localized message.
+func decodeJSONBody(c *gin.Context, obj any) (int, string, string) {
+	limit := maxRequestBodyBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return 0, "", ""
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge, i18n.MsgRequestTooLarge, localize(c, i18n.MsgRequestTooLarge, limit)
+	}
+	return http.StatusBadRequest, i18n.MsgInvalidRequest, localize(c, i18n.MsgInvalidRequest, err.Error())
+}
+
+// maxPromptFieldLength returns the length limit of prompt fields configured via MAX_PROMPT_FIELD_LENGTH
+func maxPromptFieldLength() int {
+	if n := services.GetEnvInt("MAX_PROMPT_FIELD_LENGTH", defaultMaxPromptFieldLength); n > 0 {
+		return n
+	}
+	return defaultMaxPromptFieldLength
+}
+
+// validateTextField checks the length of a free-text field and rejects control characters.
+// Newlines and tabs are only allowed in multiline fields.
+func validateTextField(field, value string, maxLen int, multiline bool) *validationError {
+	if !utf8.ValidString(value) {
+		return newValidationError(field, i18n.MsgFieldInvalidUTF8)
+	}
+	if n := utf8.RuneCountInString(value); n > maxLen {
+		return newValidationError(field, i18n.MsgFieldTooLong, maxLen, n)
+	}
+	for _, r := range value {
+		if multiline && (r == '\n' || r == '\t') {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return newValidationError(field, i18n.MsgFieldControlChars)
+		}
+	}
+	return nil
+}
+
+// validateSummaryRequest is the single validation layer for user-controlled SummaryRequest fields.
+// Every new free-text field should be checked here.
+func validateSummaryRequest(request *SummaryRequest) *validationError {
+	if err := validateTextField("url", request.URL, maxURLFieldLength, false); err != nil {
+		return err
+	}
+	if err := validateTextField("callbackUrl", request.CallbackURL, maxURLFieldLength, false); err != nil {
+		return err
+	}
+
+	switch request.Partial {
+	case "", partialRegenerate, partialAccept, partialContinue:
+	default:
+		return newValidationError("partial", i18n.MsgFieldOneOf, "accept, continue, regenerate")
+	}
+
+	if err := validateTextField("language", request.Language, maxPromptFieldLength(), false); err != nil {
+		return err
+	}
+	if _, err := services.NormalizeLanguage(request.Language); err != nil {
+		return newValidationError("language", i18n.MsgFieldInvalidLanguage, request.Language)
+	}
+
+	if maxLanguages := services.GetEnvInt("MAX_SUMMARY_LANGUAGES", defaultMaxSummaryLanguages); len(request.Languages) > maxLanguages {
+		return newValidationError("languages", i18n.MsgFieldTooManyLanguages, maxLanguages)
+	}
+	for _, language := range request.Languages {
+		if _, err := services.NormalizeLanguage(language); err != nil || strings.TrimSpace(language) == "" {
+			return newValidationError("languages", i18n.MsgFieldInvalidLanguage, language)
+		}
+	}
+
+	if err := validateTextField("model", request.Model, maxModelFieldLength, false); err != nil {
+		return err
+	}
+	if request.Model != "" && !modelPattern.MatchString(request.Model) {
+		return newValidationError("model", i18n.MsgFieldInvalidModel, request.Model)
+	}
+	if t := request.Temperature; t != nil && (*t < 0 || *t > services.MaxTemperature) {
+		return newValidationError("temperature", i18n.MsgFieldTemperature, services.MaxTemperature)
+	}
+	if _, err := services.NormalizeSummaryStyle(request.Style); err != nil {
+		return newValidationError("style", i18n.MsgFieldOneOf, strings.Join(services.SummaryStyles(), ", "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+)
+
+// videoTooLongErrorCode is the error of responses and summary_error events rejecting a video over MAX_VIDEO_DURATION_SECONDS
+const videoTooLongErrorCode = "video_too_long"
+
+// videoTooLongError rejects a video longer than MAX_VIDEO_DURATION_SECONDS
+type videoTooLongError struct {
+	duration int // Seconds
+	limit    int // Seconds
+}
+
+func (e *videoTooLongError) Error() string {
+	return e.Localize(i18n.English)
+}
+
+// Localize returns the error message in lang
+func (e *videoTooLongError) Localize(lang string) string {
+	return i18n.T(i18n.MsgVideoTooLong, lang, services.FormatDuration(e.duration), services.FormatDuration(e.limit))
+}
+
+// maxVideoDuration returns the longest video in seconds that is summarized, configured via
+// MAX_VIDEO_DURATION_SECONDS. 0 means unlimited.
+func maxVideoDuration() int {
+	return max(services.GetEnvInt("MAX_VIDEO_DURATION_SECONDS", 0), 0)
+}
+
+// checkVideoDuration returns a *videoTooLongError if the video is longer than the limit.
+// Videos of unknown duration, such as live streams, are allowed.
+func checkVideoDuration(videoInfo *services.VideoInfo) error {
+	limit := maxVideoDuration()
+	if limit == 0 || videoInfo == nil || videoInfo.Duration <= limit {
+		return nil
+	}
+	return &videoTooLongError{duration: videoInfo.Duration, limit: limit}
+}
+
+// checkRequestVideoDuration rejects a summary request for a video over MAX_VIDEO_DURATION_SECONDS with 400
+// before a job is queued. The video info is looked up through the VideoInfo cache, so the worker
+// reuses it. If the lookup fails the request goes ahead and the worker reports the error.
+// It returns false if a response was sent.
+func checkRequestVideoDuration(ctx context.Context, c *gin.Context, videoID string) bool {
+	limit := maxVideoDuration()
+	if limit == 0 {
+		return true
+	}
+	videoInfo, err := services.GetVideoInfoCached(ctx, videoID)
+	if err != nil {
+		logWarn("VideoID %s: Failed to look up the duration before queuing: %v", videoID, err)
+		return true
+	}
+	err = checkVideoDuration(videoInfo)
+	if err == nil {
+		return true
+	}
+
+	logInfo("VideoID %s: Rejected, %d seconds long (limit %d).", videoID, videoInfo.Duration, limit)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":       videoTooLongErrorCode,
+		"message":     localizeError(c, err),
+		"video_id":    videoID,
+		"duration":    videoInfo.Duration,
+		"maxDuration": limit,
+	})
+	return false
+}
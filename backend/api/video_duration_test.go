@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideosOverMaxDurationAreRejected(t *testing.T) {
+	setupWorkerTest(t)
+	gin.SetMode(gin.TestMode)
+	fakeYtDlp(t, `{"title": "Stream", "duration": 7261}`)
+
+	check := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		if checkRequestVideoDuration(context.Background(), c, testVideoID) {
+			return nil
+		}
+		return rec
+	}
+	assert.Nil(t, check(), "unlimited by default")
+
+	t.Setenv("MAX_VIDEO_DURATION_SECONDS", "3600")
+	rec := check()
+	if assert.NotNil(t, rec) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{
+			"error": "video_too_long",
+			"message": "This video is 02:01:01 long. Only videos up to 01:00:00 can be summarized.",
+			"video_id": "dQw4w9WgXcQ",
+			"duration": 7261,
+			"maxDuration": 3600
+		}`, rec.Body.String())
+	}
+
+	// Jobs that were not checked before queuing are rejected by the worker
+	_, err := processSummarizationJob(context.Background(), SummarizationJob{VideoID: testVideoID, UserID: "user1"})
+	payload := jobErrorPayload(testVideoID, err, i18n.English)
+	assert.Equal(t, videoTooLongErrorCode, payload["error"])
+	assert.Equal(t, jobStageVideoInfo, payload["code"])
+
+	t.Setenv("MAX_VIDEO_DURATION_SECONDS", "7261")
+	assert.Nil(t, check())
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// webSocketWriteTimeout bounds a single write, so a stalled client can't block its connection forever
+const webSocketWriteTimeout = 10 * time.Second
+
+// maxWebSocketMessageBytes limits the size of messages clients may send
+const maxWebSocketMessageBytes = 4096
+
+// The upgrader keeps gorilla's default same-origin check: sessions are cookie-based, so other sites must
+// not be able to open a connection in the user's name.
+var webSocketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// webSocketEvent is a message sent to WebSocket clients: one SSE event with its name and JSON data
+type webSocketEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// webSocketRequest is a message sent by WebSocket clients
+type webSocketRequest struct {
+	Type    string `json:"type"` // "cancel"
+	VideoID string `json:"videoId"`
+}
+
+// HandleSummaryWebSocket is the WebSocket alternative to HandleSummaryEvents. The same events are sent as
+// JSON text messages {"event": "summary_complete", "data": {...}}; the connection replaces an SSE connection
+// of the same user like a reconnect does. Clients may send {"type": "cancel", "videoId": "..."} to stop
+// waiting for a video; the job is dropped unless other users wait for it too.
+func HandleSummaryWebSocket(c *gin.Context) {
+	userInfo, authenticated := auth.GetSessionUser(c)
+	if !authenticated || userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgUserNotFound)})
+		return
+	}
+	userID := userInfo.ID
+
+	conn, err := webSocketUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logWarn("HandleSummaryWebSocket: Upgrade failed for UserID %s: %v", userID, err)
+		return // The upgrader already answered with an HTTP error
+	}
+	defer conn.Close()
+
+	lang := i18n.RequestLanguage(c.Request)
+	messageChan := registerClientChannel(userID, "WebSocket", lang)
+	defer unregisterClientChannel(userID, messageChan, "WebSocket")
+
+	// Only this goroutine writes; the reader hands its replies over
+	replies := make(chan []byte, 10)
+	closed, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go readWebSocketRequests(conn, userID, lang, replies, closed, done)
+
+	streamWebSocketMessages(conn, userID, messageChan, replies, closed, sseHeartbeatInterval())
+}
+
+// readWebSocketRequests handles the messages of a client until the connection fails or is closed, then
+// closes closed. Replies are given up once done is closed because nobody writes them anymore. Errors are
+// given in lang, the language of the upgrade request.
+func readWebSocketRequests(conn *websocket.Conn, userID, lang string, replies chan<- []byte, closed chan<- struct{}, done <-chan struct{}) {
+	defer close(closed)
+	conn.SetReadLimit(maxWebSocketMessageBytes)
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			logDebug("HandleSummaryWebSocket: Connection of UserID %s closed: %v", userID, err)
+			return
+		}
+
+		var reply []byte
+		var request webSocketRequest
+		switch err := json.Unmarshal(message, &request); {
+		case err != nil:
+			reply = webSocketReply("request_error", gin.H{"error": i18n.T(i18n.MsgInvalidMessage, lang, err.Error())})
+		case request.Type != "cancel":
+			reply = webSocketReply("request_error", gin.H{"error": i18n.T(i18n.MsgUnknownMessageType, lang), "type": request.Type})
+		case !services.IsValidVideoID(request.VideoID):
+			reply = webSocketReply("request_error", gin.H{"error": i18n.T(i18n.MsgInvalidVideoIDField, lang), "type": request.Type})
+		default:
+			cancelled := unsubscribeFromVideo(userID, request.VideoID)
+			logInfo("HandleSummaryWebSocket: UserID %s cancelled VideoID %s (%d job(s)).", userID, request.VideoID, len(cancelled))
+			reply = webSocketReply("summary_cancelled", gin.H{"videoId": request.VideoID, "jobs": len(cancelled)})
+		}
+
+		select {
+		case replies <- reply:
+		case <-done:
+			return
+		}
+	}
+}
+
+// webSocketReply formats a reply to a client request like the SSE events on the message channel
+func webSocketReply(event string, data gin.H) []byte {
+	jsonData, _ := json.Marshal(data)
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, jsonData))
+}
+
+// streamWebSocketMessages writes the messages sent to messageChan and the replies to the client's requests
+// until messageChan is closed, a write fails or the client disconnects. A ping is sent every heartbeat so
+// proxies don't drop idle connections.
+func streamWebSocketMessages(conn *websocket.Conn, userID string, messageChan, replies <-chan []byte, closed <-chan struct{}, heartbeat time.Duration) {
+	var heartbeats <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	write := func(message []byte) bool {
+		event, ok := webSocketEventFromSSE(message)
+		if !ok {
+			return true
+		}
+		conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+		if err := conn.WriteJSON(event); err != nil {
+			logWarn("HandleSummaryWebSocket: Error writing to WebSocket client UserID %s: %v. Closing connection.", userID, err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		select {
+		case message, open := <-messageChan:
+			if !open {
+				// Replaced by a newer connection or the server is shutting down
+				logInfo("HandleSummaryWebSocket: Message channel for UserID %s closed by sender. Closing connection.", userID)
+				closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(webSocketWriteTimeout))
+				return
+			}
+			if !write(message) {
+				return
+			}
+		case reply := <-replies:
+			if !write(reply) {
+				return
+			}
+		case <-heartbeats:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout)); err != nil {
+				logDebug("HandleSummaryWebSocket: Error writing ping to UserID %s: %v. Closing connection.", userID, err)
+				return
+			}
+		case <-closed:
+			logInfo("HandleSummaryWebSocket: Client UserID %s disconnected.", userID)
+			return
+		}
+	}
+}
+
+// webSocketEventFromSSE converts a pre-formatted SSE event into a WebSocket message. SSE comments such as
+// keepalives and retry hints have no WebSocket equivalent and are dropped.
+func webSocketEventFromSSE(message []byte) (webSocketEvent, bool) {
+	var event webSocketEvent
+	for _, line := range bytes.Split(message, []byte("\n")) {
+		if name, found := bytes.CutPrefix(line, []byte("event: ")); found {
+			event.Event = string(name)
+		} else if data, found := bytes.CutPrefix(line, []byte("data: ")); found {
+			event.Data = json.RawMessage(data)
+		}
+	}
+	if event.Event == "" || !json.Valid(event.Data) {
+		return webSocketEvent{}, false
+	}
+	return event, true
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// isConnected reports whether userID has a registered SSE or WebSocket channel
+func isConnected(userID string) bool {
+	clientChannelsMutex.RLock()
+	defer clientChannelsMutex.RUnlock()
+	_, ok := clientChannels[userID]
+	return ok
+}
+
+func TestHandleSummaryWebSocket(t *testing.T) {
+	setupWorkerTest(t)
+	loginTestUser(t, "user1")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/summary/ws", HandleSummaryWebSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/summary/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	// Errors and notices are given in the language of the upgrade request
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Cookie": {"session_id=session-user1"}, "Accept-Language": {"ko-KR"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Eventually(t, func() bool { return isConnected("user1") }, time.Second, time.Millisecond)
+	receiveEvent := func() (string, map[string]any) {
+		t.Helper()
+		var event struct {
+			Event string         `json:"event"`
+			Data  map[string]any `json:"data"`
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		assert.NoError(t, conn.ReadJSON(&event))
+		return event.Event, event.Data
+	}
+
+	// Results arrive like on the SSE stream
+	activeVideoJobsMutex.Lock()
+	activeVideoJobs[testVideoID] = []string{"user1"}
+	activeVideoJobsMutex.Unlock()
+	completeJob(SummarizationJob{VideoID: testVideoID, UserID: "user1"}, &SummaryResponse{VideoID: testVideoID, Summary: "summary"}, nil, "")
+	event, data := receiveEvent()
+	assert.Equal(t, "summary_complete", event)
+	assert.Equal(t, "summary", data["summary"])
+
+	// Cancelling drops a job only the user waits for and keeps the others
+	activeVideoJobsMutex.Lock()
+	activeVideoJobs[testVideoID] = []string{"user1"}
+	activeVideoJobs[testVideoID+".en"] = []string{"user1", "user2"}
+	activeVideoJobs["aaaaaaaaaaa"] = []string{"user1"}
+	activeVideoJobsMutex.Unlock()
+	assert.NoError(t, conn.WriteJSON(webSocketRequest{Type: "cancel", VideoID: testVideoID}))
+	event, data = receiveEvent()
+	assert.Equal(t, "summary_cancelled", event)
+	assert.Equal(t, map[string]any{"videoId": testVideoID, "jobs": 2.0}, data)
+	activeVideoJobsMutex.RLock()
+	assert.Equal(t, map[string][]string{testVideoID + ".en": {"user2"}, "aaaaaaaaaaa": {"user1"}}, activeVideoJobs)
+	activeVideoJobsMutex.RUnlock()
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "pause"}`)))
+	event, data = receiveEvent()
+	assert.Equal(t, "request_error", event)
+	assert.Equal(t, i18n.T(i18n.MsgUnknownMessageType, i18n.Korean), data["error"])
+
+	// The shutdown notification is sent before the server closes the connection
+	NotifySSEShutdown()
+	event, data = receiveEvent()
+	assert.Equal(t, "server_shutdown", event)
+	assert.Contains(t, data, "reconnectAfterMs")
+	assert.Equal(t, i18n.T(i18n.MsgServerShuttingDown, i18n.Korean), data["message"])
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+}
+
+func TestWebSocketConnectionIsUnregisteredOnDisconnect(t *testing.T) {
+	setupWorkerTest(t)
+	loginTestUser(t, "user1")
+	t.Setenv("CANCEL_ABANDONED_JOBS", "false")
+	router := gin.New()
+	router.GET("/api/summary/ws", HandleSummaryWebSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/summary/ws", http.Header{"Cookie": {"session_id=session-user1"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Eventually(t, func() bool { return isConnected("user1") }, time.Second, time.Millisecond)
+	conn.Close()
+	assert.Eventually(t, func() bool { return !isConnected("user1") }, time.Second, time.Millisecond)
+}
+
+func TestWebSocketEventFromSSE(t *testing.T) {
+	event, ok := webSocketEventFromSSE([]byte("event: server_shutdown\nretry: 3000\ndata: {\"reconnectAfterMs\":3000}\n\n"))
+	assert.True(t, ok)
+	data, _ := json.Marshal(event)
+	assert.JSONEq(t, `{"event": "server_shutdown", "data": {"reconnectAfterMs": 3000}}`, string(data))
+
+	_, ok = webSocketEventFromSSE(sseKeepalive)
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package api
+
+import "sync"
+
+// workerPool keeps the configured number of workers running. A panic while processing a job is
+// recovered by handleJob, but if a worker goroutine dies anyway it is replaced, so the pool doesn't
+// silently lose capacity until queued jobs wait forever.
+type workerPool struct {
+	queue chan SummarizationJob
+	size  int
+
+	mu     sync.Mutex
+	live   int
+	nextID int
+	wg     sync.WaitGroup // Running workers, for DrainWorkers
+}
+
+// workers is the pool started by InitSummaryModule
+var workers *workerPool
+
+// handleWorkerJob processes a job taken from the queue. Tests replace it to make a worker die.
+var handleWorkerJob = func(workerID int, job SummarizationJob) { handleJob(workerID, job) }
+
+// startWorkerPool launches numWorkers workers that process the jobs sent on queue until it is closed
+func startWorkerPool(numWorkers int, queue chan SummarizationJob) *workerPool {
+	pool := &workerPool{queue: queue, size: numWorkers}
+	for i := 0; i < numWorkers; i++ {
+		pool.spawn()
+	}
+	return pool
+}
+
+// liveWorkers returns the number of running worker goroutines
+func (p *workerPool) liveWorkers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.live
+}
+
+// spawn starts a worker goroutine with a new worker ID
+func (p *workerPool) spawn() {
+	p.mu.Lock()
+	p.live++
+	p.nextID++
+	workerID := p.nextID
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go p.run(workerID)
+}
+
+// run processes jobs until the queue is closed. A worker that dies from a panic starts its replacement
+// before it leaves wg, so DrainWorkers keeps waiting for the replacement.
+func (p *workerPool) run(workerID int) {
+	logDebug("Worker %d starting.", workerID)
+	defer p.wg.Done()
+	defer func() {
+		p.mu.Lock()
+		p.live--
+		p.mu.Unlock()
+
+		r := recover()
+		if r == nil {
+			logDebug("Worker %d stopping.", workerID)
+			return
+		}
+		logError("Worker %d encountered a critical panic: %v. Starting a replacement (pool size %d).", workerID, r, p.size)
+		workerRestartsTotal.Inc()
+		p.spawn()
+	}()
+
+	for job := range p.queue {
+		if jobJournal != nil && isDraining() {
+			logInfo("Worker %d: Leaving job for VideoID: %s in the job journal for the next start.", workerID, job.VideoID)
+			continue
+		}
+		handleWorkerJob(workerID, job)
+	}
+}
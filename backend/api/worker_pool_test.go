@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPoolReplacesDeadWorkers(t *testing.T) {
+	setupWorkerTest(t)
+	originalHandleWorkerJob := handleWorkerJob
+	t.Cleanup(func() { handleWorkerJob = originalHandleWorkerJob })
+
+	processed := make(chan string, 10)
+	handleWorkerJob = func(workerID int, job SummarizationJob) {
+		if job.VideoID == "crash" {
+			panic("worker died")
+		}
+		processed <- job.VideoID
+	}
+
+	queue := make(chan SummarizationJob, 10)
+	pool := startWorkerPool(2, queue)
+	full := func() bool { return pool.liveWorkers() == 2 }
+	assert.Eventually(t, full, time.Second, time.Millisecond)
+
+	// Kill more workers than the pool has
+	for i := 0; i < 3; i++ {
+		queue <- SummarizationJob{VideoID: "crash"}
+	}
+	queue <- SummarizationJob{VideoID: "aaaaaaaaaaa"}
+	queue <- SummarizationJob{VideoID: "bbbbbbbbbbb"}
+
+	var videoIDs []string
+	for i := 0; i < 2; i++ {
+		select {
+		case videoID := <-processed:
+			videoIDs = append(videoIDs, videoID)
+		case <-time.After(time.Second):
+			t.Fatal("the queue is not drained after workers died")
+		}
+	}
+	assert.ElementsMatch(t, []string{"aaaaaaaaaaa", "bbbbbbbbbbb"}, videoIDs)
+	assert.Eventually(t, full, time.Second, time.Millisecond, "the pool is back to full strength")
+
+	close(queue)
+	assert.Eventually(t, func() bool { return pool.liveWorkers() == 0 }, time.Second, time.Millisecond, "workers stop without replacement when the queue is closed")
+}
@@ -1,14 +1,19 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
@@ -20,6 +25,11 @@ var (
 	// 세션 관리를 위한 맵과 뮤텍스
 	sessions     = make(map[string]*Session)
 	sessionMutex sync.RWMutex
+	// 세션 영구 저장소 (nil이면 메모리에만 보관)
+	sessionStore SessionStore
+	// 관리자 사용자 ID 목록 (ADMIN_USERS)
+	adminUsers      = make(map[string]bool)
+	adminUsersMutex sync.RWMutex
 )
 
 // UserInfo는 Google에서 반환된 사용자 정보를 저장하는 구조체
@@ -46,12 +56,15 @@ type Session struct {
 
 // InitAuth OAuth 설정을 초기화합니다
 func InitAuth() {
+	// 관리자 목록은 OAuth 설정과 무관하게 로드
+	SetAdminUsers(strings.Split(os.Getenv("ADMIN_USERS"), ","))
+
 	clientID := os.Getenv("GOOGLE_OAUTH_CLIENT_ID")
 	clientSecret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
 	redirectURL := os.Getenv("GOOGLE_OAUTH_REDIRECT_URI")
 
 	if clientID == "" || clientSecret == "" {
-		log.Println("Warning: Google OAuth credentials not set in environment variables")
+		services.LogWarn("Google OAuth credentials not set in environment variables")
 		return
 	}
 
@@ -67,32 +80,105 @@ func InitAuth() {
 		Endpoint:     google.Endpoint,
 	}
 
+	// 재시작 후에도 로그인이 유지되도록 저장된 세션 복원
+	sessionDir := os.Getenv("SESSION_DIR")
+	if sessionDir == "" {
+		sessionDir = "sessions"
+	}
+	secret := os.Getenv("SESSION_SECRET")
+	if secret == "" {
+		services.LogWarn("SESSION_SECRET not set, OAuth tokens in persisted sessions are protected by file permissions only")
+	}
+	store, err := NewFileSessionStore(sessionDir, secret)
+	if err != nil {
+		services.LogWarn("Session persistence disabled: %v", err)
+	} else {
+		SetSessionStore(store)
+	}
+
 	// 주기적으로 만료된 세션 정리
 	go cleanupExpiredSessions()
 }
 
+// SetSessionStore는 세션 영구 저장소를 설정하고 저장된 세션 중 만료되지 않은 세션을 복원합니다.
+// nil을 전달하면 세션은 메모리에만 보관됩니다.
+func SetSessionStore(store SessionStore) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	sessionStore = store
+	if store == nil {
+		return
+	}
+
+	loaded, err := store.LoadAll()
+	if err != nil {
+		services.LogWarn("Failed to load sessions: %v", err)
+		return
+	}
+	now := time.Now()
+	restored := 0
+	for _, session := range loaded {
+		if now.After(session.ExpiresAt) {
+			deleteStoredSession(session.ID)
+			continue
+		}
+		sessions[session.ID] = session
+		restored++
+	}
+	if restored > 0 {
+		services.LogInfo("Restored %d session(s)", restored)
+	}
+}
+
+// saveSession은 세션을 저장소에 기록합니다 (sessionMutex를 잡은 상태에서 호출)
+func saveSession(session *Session) {
+	if sessionStore == nil {
+		return
+	}
+	if err := sessionStore.Save(session); err != nil {
+		services.LogWarn("Failed to persist session %s: %v", session.ID, err)
+	}
+}
+
+// deleteStoredSession은 저장소에서 세션을 삭제합니다 (sessionMutex를 잡은 상태에서 호출)
+func deleteStoredSession(sessionID string) {
+	if sessionStore == nil {
+		return
+	}
+	if err := sessionStore.Delete(sessionID); err != nil {
+		services.LogWarn("Failed to delete stored session %s: %v", sessionID, err)
+	}
+}
+
 // 만료된 세션을 주기적으로 정리하는 함수
 func cleanupExpiredSessions() {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		sessionMutex.Lock()
-		now := time.Now()
-		for id, session := range sessions {
-			if now.After(session.ExpiresAt) {
-				delete(sessions, id)
-				log.Printf("Expired session cleaned up: %s", id)
-			}
+		removeExpiredSessions(time.Now())
+	}
+}
+
+// removeExpiredSessions는 now 기준으로 만료된 세션을 메모리와 저장소에서 삭제합니다
+func removeExpiredSessions(now time.Time) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	for id, session := range sessions {
+		if now.After(session.ExpiresAt) {
+			delete(sessions, id)
+			deleteStoredSession(id)
+			services.LogDebug("Expired session cleaned up: %s", id)
 		}
-		sessionMutex.Unlock()
 	}
 }
 
 // GoogleLoginHandler는 Google OAuth 로그인 프로세스를 시작합니다
 func GoogleLoginHandler(c *gin.Context) {
 	if googleOAuthConfig == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OAuth not configured"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgOAuthNotConfigured)})
 		return
 	}
 
@@ -106,14 +192,14 @@ func GoogleLoginHandler(c *gin.Context) {
 // GoogleCallbackHandler는 Google OAuth 콜백을 처리합니다
 func GoogleCallbackHandler(c *gin.Context) {
 	if googleOAuthConfig == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OAuth not configured"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgOAuthNotConfigured)})
 		return
 	}
 
 	// 인증 코드 획득
 	code := c.Query("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Code not provided"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgOAuthCodeMissing)})
 		return
 	}
 
@@ -121,21 +207,21 @@ func GoogleCallbackHandler(c *gin.Context) {
 	state := c.Query("state")
 	storedState, _ := c.Cookie("oauth_state")
 	if state == "" || state != storedState {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid state token"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, i18n.MsgInvalidStateToken)})
 		return
 	}
 
 	// 코드를 토큰으로 교환
 	token, err := googleOAuthConfig.Exchange(c.Request.Context(), code)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to exchange token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgTokenExchangeFailed)})
 		return
 	}
 
 	// Google API에서 사용자 정보 가져오기
 	userInfo, err := getUserInfo(token.AccessToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, i18n.MsgUserInfoFailed)})
 		return
 	}
 
@@ -152,6 +238,7 @@ func GoogleCallbackHandler(c *gin.Context) {
 	// 세션 저장
 	sessionMutex.Lock()
 	sessions[session.ID] = session
+	saveSession(session)
 	sessionMutex.Unlock()
 
 	// 세션 ID를 쿠키에 설정
@@ -206,13 +293,17 @@ func RefreshSession(c *gin.Context) bool {
 		}).Token()
 
 		if err != nil {
-			log.Printf("Failed to refresh token: %v", err)
+			services.LogError("Failed to refresh token: %v", err)
 			return false
 		}
 
 		// 새로운 정보로 세션 업데이트
 		session.AccessToken = token.AccessToken
 		session.ExpiresAt = token.Expiry
+		if token.RefreshToken != "" {
+			session.RefreshToken = token.RefreshToken
+		}
+		saveSession(session)
 
 		// 새 세션 정보로 쿠키 갱신
 		c.SetCookie("session_id", session.ID, 3600*24*7, "/", "", false, true)
@@ -226,7 +317,7 @@ func IsAuthenticated() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInfo, authenticated := GetSessionUser(c)
 		if !authenticated {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgAuthenticationRequired)})
 			c.Abort()
 			return
 		}
@@ -247,21 +338,135 @@ func IsAuthenticated() gin.HandlerFunc {
 	}
 }
 
+// SetAdminUsers는 관리자 사용자 ID 목록을 설정합니다
+func SetAdminUsers(userIDs []string) {
+	admins := make(map[string]bool)
+	for _, userID := range userIDs {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			admins[userID] = true
+		}
+	}
+
+	adminUsersMutex.Lock()
+	adminUsers = admins
+	adminUsersMutex.Unlock()
+}
+
+// IsAdminUser는 사용자가 관리자인지 확인합니다
+func IsAdminUser(userID string) bool {
+	adminUsersMutex.RLock()
+	defer adminUsersMutex.RUnlock()
+	return adminUsers[userID]
+}
+
+// RequireAdmin은 관리자만 접근할 수 있도록 제한합니다 (IsAuthenticated 이후에 사용)
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInfo, authenticated := GetSessionUser(c)
+		if !authenticated {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, i18n.MsgAuthenticationRequired)})
+			c.Abort()
+			return
+		}
+		if !IsAdminUser(userInfo.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": localize(c, i18n.MsgAdminRequired)})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // LogoutHandler는 사용자의 세션을 종료합니다
 func LogoutHandler(c *gin.Context) {
+	// 다른 사이트의 폼이나 스크립트가 쿠키만으로 로그아웃시키지 못하도록 확인
+	if !isSameOriginRequest(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, i18n.MsgCSRFHeaderRequired, csrfHeader)})
+		return
+	}
+
 	// 세션 ID 가져오기
+	var session *Session
 	sessionID, err := c.Cookie("session_id")
 	if err == nil {
 		// 세션 맵에서 제거
 		sessionMutex.Lock()
+		session = sessions[sessionID]
 		delete(sessions, sessionID)
+		deleteStoredSession(sessionID)
 		sessionMutex.Unlock()
 	}
 
+	// Google에 부여된 OAuth 권한 취소 (실패해도 로그아웃은 계속 진행)
+	if session != nil {
+		if token := revocableToken(session); token != "" {
+			if err := revokeToken(c.Request.Context(), token); err != nil {
+				services.LogWarn("Failed to revoke OAuth token of session %s: %v", sessionID, err)
+			}
+		}
+	}
+
 	// 쿠키 삭제
 	c.SetCookie("session_id", "", -1, "/", "", false, true)
 	c.SetCookie("oauth_state", "", -1, "/", "", false, true)
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully logged out"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, i18n.MsgLoggedOut)})
+}
+
+// csrfHeader는 로그아웃 요청에 필요한 헤더입니다. 다른 출처의 페이지는 CORS preflight 없이
+// 사용자 정의 헤더를 보낼 수 없으므로, 이 헤더가 있으면 같은 출처의 스크립트가 보낸 요청입니다.
+const csrfHeader = "X-Requested-With"
+
+// isSameOriginRequest는 요청에 csrfHeader가 있고, Origin 헤더가 있다면 서버와 같은 호스트인지 확인합니다
+func isSameOriginRequest(c *gin.Context) bool {
+	if c.GetHeader(csrfHeader) == "" {
+		return false
+	}
+	if origin := c.GetHeader("Origin"); origin != "" {
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Host != c.Request.Host {
+			return false
+		}
+	}
+	return true
+}
+
+// googleRevokeURL은 Google OAuth 토큰 취소 엔드포인트입니다 (테스트에서 교체)
+var googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// revokeTimeout은 로그아웃이 토큰 취소를 기다리는 최대 시간입니다
+const revokeTimeout = 5 * time.Second
+
+// revocableToken은 세션에서 취소할 토큰을 반환합니다. 리프레시 토큰을 취소하면 권한 부여 전체가 취소되므로 우선합니다.
+func revocableToken(session *Session) string {
+	if session.RefreshToken != "" {
+		return session.RefreshToken
+	}
+	return session.AccessToken
+}
+
+// revokeToken은 Google 토큰 취소 엔드포인트를 호출합니다
+func revokeToken(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, revokeTimeout)
+	defer cancel()
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleRevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("revocation failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
 }
 
 // OAuth 액세스 토큰을 사용하여 사용자 정보를 가져옵니다
@@ -284,3 +489,8 @@ func getUserInfo(accessToken string) (*UserInfo, error) {
 
 	return &userInfo, nil
 }
+
+// localize는 요청의 Accept-Language에 맞는 언어로 메시지를 반환합니다
+func localize(c *gin.Context, code string, args ...any) string {
+	return i18n.T(code, i18n.RequestLanguage(c.Request), args...)
+}
@@ -3,7 +3,11 @@ package auth
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +28,8 @@ func TestLogoutHandler(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
 	// 쿠키 추가 (테스트를 위한 세션 ID)
 	req.AddCookie(&http.Cookie{
 		Name:  "session_id",
@@ -73,3 +79,174 @@ func TestLogoutHandler(t *testing.T) {
 	assert.Less(t, sessionCookie.MaxAge, 0, "session_id cookie should be expired")
 	assert.Less(t, oauthStateCookie.MaxAge, 0, "oauth_state cookie should be expired")
 }
+
+// logoutRequest는 세션 쿠키와 헤더를 담은 로그아웃 요청을 보냅니다
+func logoutRequest(sessionID string, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/auth/logout", LogoutHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestLogoutRequiresCSRFHeader는 다른 출처에서 보낼 수 있는 요청으로는 로그아웃되지 않는지 테스트합니다.
+func TestLogoutRequiresCSRFHeader(t *testing.T) {
+	sessionMutex.Lock()
+	sessions["csrf-session"] = &Session{ID: "csrf-session"}
+	sessionMutex.Unlock()
+	t.Cleanup(func() {
+		sessionMutex.Lock()
+		delete(sessions, "csrf-session")
+		sessionMutex.Unlock()
+	})
+
+	for _, headers := range []map[string]string{
+		nil, // 일반 폼 전송
+		{"X-Requested-With": "XMLHttpRequest", "Origin": "https://evil.example"},
+	} {
+		w := logoutRequest("csrf-session", headers)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		sessionMutex.RLock()
+		_, exists := sessions["csrf-session"]
+		sessionMutex.RUnlock()
+		assert.True(t, exists, "a rejected logout must keep the session")
+	}
+
+	w := logoutRequest("csrf-session", map[string]string{"X-Requested-With": "XMLHttpRequest", "Origin": "http://example.com"})
+	assert.Equal(t, http.StatusOK, w.Code, "httptest requests are sent to example.com")
+}
+
+// TestLogoutRevokesOAuthToken은 로그아웃 시 Google 토큰이 취소되고, 취소에 실패해도 로그아웃되는지 테스트합니다.
+func TestLogoutRevokesOAuthToken(t *testing.T) {
+	var revoked []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revoked = append(revoked, r.FormValue("token"))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	originalURL := googleRevokeURL
+	googleRevokeURL = server.URL
+	t.Cleanup(func() { googleRevokeURL = originalURL })
+
+	headers := map[string]string{"X-Requested-With": "XMLHttpRequest"}
+	sessionMutex.Lock()
+	sessions["revoke-1"] = &Session{ID: "revoke-1", AccessToken: "access-1", RefreshToken: "refresh-1"}
+	sessions["revoke-2"] = &Session{ID: "revoke-2", AccessToken: "access-2"}
+	sessionMutex.Unlock()
+
+	assert.Equal(t, http.StatusOK, logoutRequest("revoke-1", headers).Code)
+	status = http.StatusBadRequest
+	assert.Equal(t, http.StatusOK, logoutRequest("revoke-2", headers).Code, "a failed revocation must not fail the logout")
+
+	assert.Equal(t, []string{"refresh-1", "access-2"}, revoked)
+	sessionMutex.RLock()
+	_, exists := sessions["revoke-2"]
+	sessionMutex.RUnlock()
+	assert.False(t, exists)
+}
+
+// useTestSessionStore는 테스트용 임시 디렉토리에 세션을 저장하도록 설정합니다
+func useTestSessionStore(t *testing.T, secret string) (*FileSessionStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := NewFileSessionStore(dir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionMutex.Lock()
+	originalSessions, originalStore := sessions, sessionStore
+	sessions = make(map[string]*Session)
+	sessionMutex.Unlock()
+	t.Cleanup(func() {
+		sessionMutex.Lock()
+		sessions, sessionStore = originalSessions, originalStore
+		sessionMutex.Unlock()
+	})
+
+	SetSessionStore(store)
+	return store, dir
+}
+
+// TestFileSessionStoreEncryptsTokens는 토큰이 암호화되어 소유자 전용 파일에 저장되는지 테스트합니다.
+func TestFileSessionStoreEncryptsTokens(t *testing.T) {
+	store, dir := useTestSessionStore(t, "test-secret")
+	session := &Session{
+		ID:           "0b6f7a53-9d55-4d2e-8f0c-3a1b2c4d5e6f",
+		UserInfo:     &UserInfo{ID: "user1", Email: "user1@example.com"},
+		AccessToken:  "access-token-value",
+		RefreshToken: "refresh-token-value",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, store.Save(session))
+
+	path := filepath.Join(dir, session.ID+".json")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, _ := os.ReadFile(path)
+	assert.False(t, strings.Contains(string(data), "refresh-token-value"), "refresh token must not be stored in plain text")
+	assert.False(t, strings.Contains(string(data), "access-token-value"))
+
+	loaded, err := store.LoadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, loaded, 1) {
+		assert.Equal(t, "refresh-token-value", loaded[0].RefreshToken)
+		assert.Equal(t, "user1", loaded[0].UserInfo.ID)
+	}
+
+	// 다른 secret으로는 복호화할 수 없으므로 세션 파일은 폐기됩니다
+	other, _ := NewFileSessionStore(dir, "other-secret")
+	loaded, err = other.LoadAll()
+	assert.NoError(t, err)
+	assert.Empty(t, loaded)
+	assert.NoFileExists(t, path)
+
+	assert.Error(t, store.Save(&Session{ID: "../escape", UserInfo: &UserInfo{}}))
+}
+
+// TestSessionsSurviveRestart는 저장된 세션이 복원되고 만료된 세션 파일이 정리되는지 테스트합니다.
+func TestSessionsSurviveRestart(t *testing.T) {
+	store, dir := useTestSessionStore(t, "")
+	active := &Session{ID: "11111111-1111-4111-8111-111111111111", UserInfo: &UserInfo{ID: "user1"}, ExpiresAt: time.Now().Add(2 * time.Hour)}
+	expiring := &Session{ID: "22222222-2222-4222-8222-222222222222", UserInfo: &UserInfo{ID: "user2"}, ExpiresAt: time.Now().Add(30 * time.Minute)}
+	expired := &Session{ID: "33333333-3333-4333-8333-333333333333", UserInfo: &UserInfo{ID: "user3"}, ExpiresAt: time.Now().Add(-time.Minute)}
+	for _, session := range []*Session{active, expiring, expired} {
+		assert.NoError(t, store.Save(session))
+	}
+
+	// 재시작: 메모리의 세션을 비우고 저장소에서 다시 읽기
+	sessionMutex.Lock()
+	sessions = make(map[string]*Session)
+	sessionMutex.Unlock()
+	SetSessionStore(store)
+
+	sessionMutex.RLock()
+	assert.Len(t, sessions, 2)
+	assert.Contains(t, sessions, active.ID)
+	sessionMutex.RUnlock()
+	assert.NoFileExists(t, filepath.Join(dir, expired.ID+".json"), "expired sessions are purged on load")
+
+	removeExpiredSessions(time.Now().Add(time.Hour))
+	sessionMutex.RLock()
+	assert.Len(t, sessions, 1)
+	sessionMutex.RUnlock()
+	assert.NoFileExists(t, filepath.Join(dir, expiring.ID+".json"))
+
+	// 로그아웃하면 세션 파일도 삭제됩니다
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	originalURL := googleRevokeURL
+	googleRevokeURL = server.URL
+	t.Cleanup(func() { googleRevokeURL = originalURL })
+	assert.Equal(t, http.StatusOK, logoutRequest(active.ID, map[string]string{"X-Requested-With": "XMLHttpRequest"}).Code)
+	assert.NoFileExists(t, filepath.Join(dir, active.ID+".json"))
+}
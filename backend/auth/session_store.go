@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/google/uuid"
+)
+
+// encryptedTokenPrefix는 암호화되어 저장된 토큰을 구분하는 접두사
+const encryptedTokenPrefix = "enc:"
+
+// SessionStore는 세션을 영구 저장소에 보관하는 인터페이스 (파일 외에 Redis 등으로 교체 가능)
+type SessionStore interface {
+	// Save는 세션을 생성하거나 갱신합니다
+	Save(session *Session) error
+	// Delete는 세션을 삭제합니다. 저장된 세션이 없어도 오류가 아닙니다
+	Delete(sessionID string) error
+	// LoadAll은 저장된 모든 세션을 반환합니다
+	LoadAll() ([]*Session, error)
+}
+
+// FileSessionStore는 세션마다 하나의 JSON 파일을 디렉토리에 저장합니다.
+// 파일은 소유자만 읽을 수 있도록 0600 권한으로 생성되며, secret이 설정된 경우
+// 액세스 토큰과 리프레시 토큰은 AES-GCM으로 암호화되어 저장됩니다.
+type FileSessionStore struct {
+	dir string
+	gcm cipher.AEAD
+}
+
+// NewFileSessionStore는 dir에 세션을 저장하는 FileSessionStore를 생성합니다.
+// secret이 비어 있으면 토큰은 암호화되지 않고 파일 권한으로만 보호됩니다.
+func NewFileSessionStore(dir, secret string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("세션 디렉토리 생성 실패: %w", err)
+	}
+
+	store := &FileSessionStore{dir: dir}
+	if secret != "" {
+		key := sha256.Sum256([]byte(secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		if store.gcm, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// sessionPath는 세션 ID에 해당하는 파일 경로를 반환합니다
+func (s *FileSessionStore) sessionPath(sessionID string) (string, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return "", errors.New("유효하지 않은 세션 ID입니다")
+	}
+	return filepath.Join(s.dir, sessionID+".json"), nil
+}
+
+// Save는 세션을 파일에 기록합니다. 쓰는 도중 중단되어도 기존 파일이 손상되지 않도록 임시 파일을 거쳐 교체합니다.
+func (s *FileSessionStore) Save(session *Session) error {
+	path, err := s.sessionPath(session.ID)
+	if err != nil {
+		return err
+	}
+
+	stored := *session
+	if stored.AccessToken, err = s.encrypt(session.AccessToken); err != nil {
+		return err
+	}
+	if stored.RefreshToken, err = s.encrypt(session.RefreshToken); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, session.ID+".json.tmp-*") // CreateTemp는 0600 권한으로 생성
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 이름이 바뀐 뒤에는 아무 동작도 하지 않음
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete는 세션 파일을 삭제합니다
+func (s *FileSessionStore) Delete(sessionID string) error {
+	path, err := s.sessionPath(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LoadAll은 디렉토리의 모든 세션 파일을 읽습니다.
+// 읽거나 복호화할 수 없는 파일(예: SESSION_SECRET 변경)은 건너뛰고 삭제합니다.
+func (s *FileSessionStore) LoadAll() ([]*Session, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []*Session
+	for _, file := range files {
+		session, err := s.load(file)
+		if err != nil {
+			services.LogWarn("Discarding unreadable session file %s: %v", filepath.Base(file), err)
+			os.Remove(file)
+			continue
+		}
+		loaded = append(loaded, session)
+	}
+	return loaded, nil
+}
+
+// load는 하나의 세션 파일을 읽고 토큰을 복호화합니다
+func (s *FileSessionStore) load(file string) (*Session, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if session.ID+".json" != filepath.Base(file) || session.UserInfo == nil {
+		return nil, errors.New("세션 파일 내용이 올바르지 않습니다")
+	}
+	if session.AccessToken, err = s.decrypt(session.AccessToken); err != nil {
+		return nil, err
+	}
+	if session.RefreshToken, err = s.decrypt(session.RefreshToken); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// encrypt는 secret이 설정된 경우 토큰을 암호화합니다
+func (s *FileSessionStore) encrypt(token string) (string, error) {
+	if s.gcm == nil || token == "" {
+		return token, nil
+	}
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.gcm.Seal(nonce, nonce, []byte(token), nil)
+	return encryptedTokenPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt는 암호화된 토큰을 복호화합니다. 암호화되지 않은 토큰은 그대로 반환합니다.
+func (s *FileSessionStore) decrypt(token string) (string, error) {
+	if !strings.HasPrefix(token, encryptedTokenPrefix) {
+		return token, nil
+	}
+	if s.gcm == nil {
+		return "", errors.New("암호화된 토큰이지만 SESSION_SECRET이 설정되지 않았습니다")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(token, encryptedTokenPrefix))
+	if err != nil {
+		return "", err
+	}
+	nonceSize := s.gcm.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("암호화된 토큰이 너무 짧습니다")
+	}
+	plain, err := s.gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("토큰 복호화 실패: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,118 @@
+// Package i18n localizes the messages returned to clients. Messages are looked up by code in the
+// language the client prefers according to its Accept-Language header; English is the fallback.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Korean  = "ko"
+
+	// DefaultLanguage is used when the client accepts none of the supported languages
+	DefaultLanguage = English
+)
+
+// T returns the message of code in lang, formatted with args. Messages missing in lang are returned in
+// DefaultLanguage; unknown codes are returned as they are.
+func T(code, lang string, args ...any) string {
+	messages, ok := catalog[code]
+	if !ok {
+		return code
+	}
+	format, ok := messages[lang]
+	if !ok {
+		format = messages[DefaultLanguage]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// RequestLanguage returns the supported language a request's Accept-Language header prefers
+func RequestLanguage(r *http.Request) string {
+	if r == nil {
+		return DefaultLanguage
+	}
+	return Language(r.Header.Get("Accept-Language"))
+}
+
+// Language returns the supported language an Accept-Language header value such as
+// "ko-KR,ko;q=0.9,en;q=0.8" prefers, or DefaultLanguage if it accepts none of them
+func Language(acceptLanguage string) string {
+	type preference struct {
+		lang    string
+		quality float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if primary != "" && quality > 0 {
+			preferences = append(preferences, preference{lang: primary, quality: quality})
+		}
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	for _, p := range preferences {
+		if p.lang == English || p.lang == Korean {
+			return p.lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// Error is an error whose client-facing message is looked up in the catalog. Error returns the
+// English message, for logs and clients that don't get a localized one.
+type Error struct {
+	Code string
+	Args []any
+}
+
+// NewError returns an *Error with the message of code formatted with args
+func NewError(code string, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(e.Code, English, e.Args...)
+}
+
+// Localize returns the message of the error in lang
+func (e *Error) Localize(lang string) string {
+	return T(e.Code, lang, e.Args...)
+}
+
+// Localizer is implemented by errors whose message can be given in every supported language
+type Localizer interface {
+	error
+	Localize(lang string) string
+}
+
+// Message returns the client-facing message of err in lang. If err is a Localizer, or wraps one, the
+// message of the outermost Localizer is returned; other errors are returned as they are.
+func Message(err error, lang string) string {
+	var localizer Localizer
+	if errors.As(err, &localizer) {
+		return localizer.Localize(lang)
+	}
+	return err.Error()
+}
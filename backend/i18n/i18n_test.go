@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCatalogHasEverySupportedLanguage(t *testing.T) {
+	for code, messages := range catalog {
+		for _, lang := range []string{English, Korean} {
+			if messages[lang] == "" {
+				t.Errorf("message %q has no %q text", code, lang)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(MsgInvalidVideoID, Korean); got != "유효하지 않은 영상 ID입니다." {
+		t.Errorf("T(ko) = %q", got)
+	}
+	if got := T(MsgInvalidVideoID, "fr"); got != "Invalid video ID" {
+		t.Errorf("unsupported language should fall back to English, got %q", got)
+	}
+	if got := T(MsgQueryTooLong, English, 200); got != "q must be at most 200 characters" {
+		t.Errorf("T with args = %q", got)
+	}
+	if got := T("no_such_code", Korean); got != "no_such_code" {
+		t.Errorf("unknown code should be returned as is, got %q", got)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"ko", Korean},
+		{"ko-KR,ko;q=0.9,en-US;q=0.8,en;q=0.7", Korean},
+		{"en-US,en;q=0.9,ko;q=0.8", English},
+		{"fr-FR,fr;q=0.9,ko;q=0.5", Korean},
+		{"en;q=0.3, KO-kr;q=0.8", Korean},
+		{"ko;q=0", English},
+		{"ko;q=abc,en", English},
+		{"fr, de", English},
+	}
+	for _, tt := range tests {
+		if got := Language(tt.header); got != tt.want {
+			t.Errorf("Language(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLanguage(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "ko-KR")
+	if got := RequestLanguage(r); got != Korean {
+		t.Errorf("RequestLanguage = %q, want %q", got, Korean)
+	}
+	if got := RequestLanguage(nil); got != DefaultLanguage {
+		t.Errorf("RequestLanguage(nil) = %q, want %q", got, DefaultLanguage)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", NewError(MsgQueryTooLong, 200))
+	if got := err.Error(); got != "wrapped: q must be at most 200 characters" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := Message(err, Korean); got != T(MsgQueryTooLong, Korean, 200) {
+		t.Errorf("Message(ko) = %q", got)
+	}
+	if got := Message(errors.New("plain"), Korean); got != "plain" {
+		t.Errorf("errors without a code should keep their text, got %q", got)
+	}
+}
@@ -0,0 +1,465 @@
+package i18n
+
+// Message codes
+const (
+	MsgUserNotFound           = "user_not_found"
+	MsgNotAuthenticated       = "not_authenticated"
+	MsgAuthenticationRequired = "authentication_required"
+	MsgAdminRequired          = "admin_required"
+	MsgOAuthNotConfigured     = "oauth_not_configured"
+	MsgOAuthCodeMissing       = "oauth_code_missing"
+	MsgInvalidStateToken      = "invalid_state_token"
+	MsgTokenExchangeFailed    = "token_exchange_failed"
+	MsgUserInfoFailed         = "user_info_failed"
+	MsgCSRFHeaderRequired     = "csrf_header_required" // Argument: header name
+	MsgLoggedOut              = "logged_out"
+
+	MsgAPIKeyRequired          = "api_key_required"
+	MsgServerKeyQuotaExhausted = "server_key_quota_exhausted"
+	MsgInvalidAPIKey           = "invalid_api_key"
+	MsgAPIKeyStoreFailed       = "api_key_store_failed"
+	MsgAPIKeyDeleteFailed      = "api_key_delete_failed"
+	MsgInvalidAPIKeyPolicy     = "invalid_api_key_policy"
+
+	MsgInvalidRequest      = "invalid_request"   // Argument: parse error
+	MsgRequestTooLarge     = "request_too_large" // Argument: limit in bytes
+	MsgURLRequired         = "url_required"
+	MsgInvalidYouTubeURL   = "invalid_youtube_url"
+	MsgInvalidCallbackURL  = "invalid_callback_url" // Argument: reason
+	MsgSummaryInProgress   = "summary_in_progress"
+	MsgSummaryQueued       = "summary_queued"
+	MsgSummaryInBackground = "summary_in_background"
+	MsgJobQueueFull        = "job_queue_full"
+	MsgSummarizationFailed = "summarization_failed"
+	MsgSerializeFailed     = "serialize_failed"
+	MsgNoTranscript        = "no_transcript"
+	MsgBotCheck            = "bot_check"
+	MsgVideoTooLong        = "video_too_long" // Arguments: video duration, limit (services.FormatDuration)
+	MsgServerShuttingDown  = "server_shutting_down"
+	MsgInvalidVideoID      = "invalid_video_id"
+	MsgInvalidLanguage     = "invalid_language"
+	MsgInvalidStyle        = "invalid_style"
+	MsgSummaryNotFound     = "summary_not_found"
+	MsgRawSummaryNotStored = "raw_summary_not_stored"
+	MsgUnsupportedFormat   = "unsupported_format"
+	MsgExportFailed        = "export_failed"
+	MsgChannelIDRequired   = "channel_id_required"
+	MsgInvalidCategory     = "invalid_category" // Argument: comma-separated categories
+	MsgUserSummariesFailed = "user_summaries_failed"
+	MsgSearchFailed        = "search_failed"
+	MsgQueryRequired       = "query_required"
+	MsgQueryTooLong        = "query_too_long" // Argument: maximum length
+	MsgHistoryRemoveFailed = "history_remove_failed"
+	MsgCacheDeleteFailed   = "cache_delete_failed"
+	MsgCacheUpdateFailed   = "cache_update_failed"
+
+	MsgMustBeOneOf           = "must_be_one_of"           // Arguments: parameter, comma-separated values
+	MsgPositiveInteger       = "positive_integer"         // Argument: parameter
+	MsgNonNegativeInteger    = "non_negative_integer"     // Argument: parameter
+	MsgIdempotencyKeyTooLong = "idempotency_key_too_long" // Arguments: header name, maximum length
+	MsgCountersUnavailable   = "counters_unavailable"
+	MsgTooManyCaptionLookups = "too_many_caption_lookups"
+	MsgCaptionListFailed     = "caption_list_failed"
+	MsgTranscriptFailed      = "transcript_failed"
+	MsgPlaylistListFailed    = "playlist_list_failed"
+	MsgPlaylistTooLarge      = "playlist_too_large" // Argument: maximum size
+	MsgPlaylistEmpty         = "playlist_empty"
+	MsgInvalidMessage        = "invalid_message" // Argument: parse error
+	MsgUnknownMessageType    = "unknown_message_type"
+	MsgInvalidVideoIDField   = "invalid_video_id_field"
+
+	// Request field validation; MsgInvalidField frames the reasons below
+	MsgInvalidField          = "invalid_field" // Arguments: field, reason
+	MsgFieldInvalidUTF8      = "field_invalid_utf8"
+	MsgFieldTooLong          = "field_too_long" // Arguments: maximum length, length
+	MsgFieldControlChars     = "field_control_chars"
+	MsgFieldOneOf            = "field_one_of"             // Argument: comma-separated values
+	MsgFieldInvalidLanguage  = "field_invalid_language"   // Argument: language code
+	MsgFieldTooManyLanguages = "field_too_many_languages" // Argument: maximum count
+	MsgFieldInvalidModel     = "field_invalid_model"      // Argument: model ID
+	MsgFieldTemperature      = "field_temperature"        // Argument: maximum temperature
+
+	// Reasons a video URL is rejected
+	MsgVideoURLEmpty       = "video_url_empty"
+	MsgVideoURLMalformed   = "video_url_malformed"
+	MsgVideoURLScheme      = "video_url_scheme" // Argument: scheme
+	MsgVideoURLCredentials = "video_url_credentials"
+	MsgVideoURLHost        = "video_url_host"     // Argument: host
+	MsgVideoURLVideoID     = "video_url_video_id" // Argument: video ID
+	MsgVideoURLNotVideo    = "video_url_not_video"
+
+	// Reasons a playlist URL is rejected
+	MsgPlaylistURLInvalid    = "playlist_url_invalid"
+	MsgPlaylistURLNotYouTube = "playlist_url_not_youtube"
+	MsgPlaylistURLNoList     = "playlist_url_no_list"
+	MsgPlaylistURLListID     = "playlist_url_list_id"
+
+	// Reasons a callback URL is rejected
+	MsgCallbackURLMalformed   = "callback_url_malformed" // Argument: parse error
+	MsgCallbackURLScheme      = "callback_url_scheme"
+	MsgCallbackURLCredentials = "callback_url_credentials"
+	MsgCallbacksDisabled      = "callbacks_disabled"
+	MsgCallbackHostNotAllowed = "callback_host_not_allowed" // Argument: host
+	MsgCallbackHostUnresolved = "callback_host_unresolved"  // Arguments: host, resolver error
+	MsgCallbackHostPrivate    = "callback_host_private"
+)
+
+// catalog holds the message formats by code and language. Formats with arguments use fmt verbs.
+var catalog = map[string]map[string]string{
+	MsgUserNotFound: {
+		English: "Authenticated user information could not be found.",
+		Korean:  "인증된 사용자 정보를 찾을 수 없습니다.",
+	},
+	MsgNotAuthenticated: {
+		English: "Not authenticated",
+		Korean:  "인증되지 않았습니다.",
+	},
+	MsgAuthenticationRequired: {
+		English: "Authentication required",
+		Korean:  "로그인이 필요합니다.",
+	},
+	MsgAdminRequired: {
+		English: "Admin access required",
+		Korean:  "관리자 권한이 필요합니다.",
+	},
+	MsgOAuthNotConfigured: {
+		English: "OAuth not configured",
+		Korean:  "OAuth가 설정되지 않았습니다.",
+	},
+	MsgOAuthCodeMissing: {
+		English: "Code not provided",
+		Korean:  "인증 코드가 없습니다.",
+	},
+	MsgInvalidStateToken: {
+		English: "Invalid state token",
+		Korean:  "유효하지 않은 state 토큰입니다.",
+	},
+	MsgTokenExchangeFailed: {
+		English: "Failed to exchange token",
+		Korean:  "토큰 교환에 실패했습니다.",
+	},
+	MsgUserInfoFailed: {
+		English: "Failed to get user info",
+		Korean:  "사용자 정보를 가져오는데 실패했습니다.",
+	},
+	MsgCSRFHeaderRequired: {
+		English: "Logout requests require the %s header.",
+		Korean:  "로그아웃 요청에는 %s 헤더가 필요합니다.",
+	},
+	MsgLoggedOut: {
+		English: "Successfully logged out",
+		Korean:  "로그아웃되었습니다.",
+	},
+	MsgAPIKeyRequired: {
+		English: "An API key is required. Set your OpenAI API key in the settings.",
+		Korean:  "API 키가 필요합니다. 설정에서 OpenAI API 키를 설정해주세요.",
+	},
+	MsgServerKeyQuotaExhausted: {
+		English: "You have used up your server API key quota. Wait until it resets or set your OpenAI API key in the settings.",
+		Korean:  "서버 API 키 사용 한도를 모두 사용했습니다. 한도가 초기화될 때까지 기다리거나 설정에서 OpenAI API 키를 설정해주세요.",
+	},
+	MsgInvalidAPIKey: {
+		English: "The OpenAI API key is invalid. Check your API key in the settings.",
+		Korean:  "OpenAI API 키가 유효하지 않습니다. 설정에서 API 키를 확인해주세요.",
+	},
+	MsgAPIKeyStoreFailed: {
+		English: "Failed to store API key",
+		Korean:  "API 키를 저장하는데 실패했습니다.",
+	},
+	MsgAPIKeyDeleteFailed: {
+		English: "Failed to delete API key",
+		Korean:  "API 키를 삭제하는데 실패했습니다.",
+	},
+	MsgInvalidAPIKeyPolicy: {
+		English: `policy must be "all" or "designated"`,
+		Korean:  `policy는 "all" 또는 "designated"여야 합니다.`,
+	},
+	MsgInvalidRequest: {
+		English: "Invalid request: %s",
+		Korean:  "잘못된 요청입니다: %s",
+	},
+	MsgRequestTooLarge: {
+		English: "Request body must be at most %d bytes",
+		Korean:  "요청 본문은 %d바이트 이하여야 합니다.",
+	},
+	MsgURLRequired: {
+		English: "A YouTube URL is required",
+		Korean:  "YouTube URL이 필요합니다.",
+	},
+	MsgInvalidYouTubeURL: {
+		English: "Invalid YouTube URL",
+		Korean:  "유효하지 않은 YouTube URL입니다.",
+	},
+	MsgInvalidCallbackURL: {
+		English: "Invalid callbackUrl: %s",
+		Korean:  "유효하지 않은 callbackUrl입니다: %s",
+	},
+	MsgSummaryInProgress: {
+		English: "Summarization for this video is already in progress or queued. You will be notified upon completion.",
+		Korean:  "이 영상의 요약이 이미 진행 중이거나 대기 중입니다. 완료되면 알려 드립니다.",
+	},
+	MsgSummaryQueued: {
+		English: "Summarization request received and queued. You will be notified upon completion.",
+		Korean:  "요약 요청이 접수되어 대기열에 추가되었습니다. 완료되면 알려 드립니다.",
+	},
+	MsgSummaryInBackground: {
+		English: "Summarization is taking longer than expected and continues in the background. You will be notified upon completion.",
+		Korean:  "요약이 예상보다 오래 걸려 백그라운드에서 계속됩니다. 완료되면 알려 드립니다.",
+	},
+	MsgJobQueueFull: {
+		English: "Server busy, job queue full. Please try again later.",
+		Korean:  "서버가 바빠 요청을 처리할 수 없습니다. 잠시 후 다시 시도해 주세요.",
+	},
+	MsgSummarizationFailed: {
+		English: "Server error during summarization.",
+		Korean:  "요약 중 서버 오류가 발생했습니다.",
+	},
+	MsgSerializeFailed: {
+		English: "Internal server error: Failed to serialize summary data.",
+		Korean:  "서버 내부 오류: 요약 데이터를 직렬화하지 못했습니다.",
+	},
+	MsgNoTranscript: {
+		English: "This video has no captions available.",
+		Korean:  "이 영상에는 사용할 수 있는 자막이 없습니다.",
+	},
+	MsgBotCheck: {
+		English: "YouTube is temporarily blocking requests from this server. Please try again later.",
+		Korean:  "YouTube가 일시적으로 이 서버의 요청을 차단하고 있습니다. 잠시 후 다시 시도해 주세요.",
+	},
+	MsgVideoTooLong: {
+		English: "This video is %s long. Only videos up to %s can be summarized.",
+		Korean:  "이 영상의 길이는 %s입니다. %s 이하의 영상만 요약할 수 있습니다.",
+	},
+	MsgServerShuttingDown: {
+		English: "Server is shutting down. Reconnect to continue receiving summaries.",
+		Korean:  "서버가 종료되고 있습니다. 요약을 계속 받으려면 다시 연결하세요.",
+	},
+	MsgInvalidVideoID: {
+		English: "Invalid video ID",
+		Korean:  "유효하지 않은 영상 ID입니다.",
+	},
+	MsgInvalidLanguage: {
+		English: "Invalid language",
+		Korean:  "유효하지 않은 언어입니다.",
+	},
+	MsgInvalidStyle: {
+		English: "Invalid style",
+		Korean:  "유효하지 않은 요약 스타일입니다.",
+	},
+	MsgSummaryNotFound: {
+		English: "Summary not found",
+		Korean:  "요약을 찾을 수 없습니다.",
+	},
+	MsgRawSummaryNotStored: {
+		English: "No raw summary stored for this video. Enable STORE_RAW_SUMMARY to record it for new summaries.",
+		Korean:  "이 영상의 원본 요약이 저장되어 있지 않습니다. 새 요약에 저장하려면 STORE_RAW_SUMMARY를 켜세요.",
+	},
+	MsgUnsupportedFormat: {
+		English: "Unsupported format",
+		Korean:  "지원하지 않는 형식입니다.",
+	},
+	MsgExportFailed: {
+		English: "Failed to export summary",
+		Korean:  "요약을 내보내는데 실패했습니다.",
+	},
+	MsgChannelIDRequired: {
+		English: "Channel ID is required",
+		Korean:  "채널 ID가 필요합니다.",
+	},
+	MsgInvalidCategory: {
+		English: "category must be one of: %s",
+		Korean:  "category는 다음 중 하나여야 합니다: %s",
+	},
+	MsgUserSummariesFailed: {
+		English: "Failed to get user summaries: %v",
+		Korean:  "사용자 요약을 가져오는데 실패했습니다: %v",
+	},
+	MsgSearchFailed: {
+		English: "Failed to search user summaries: %v",
+		Korean:  "사용자 요약을 검색하는데 실패했습니다: %v",
+	},
+	MsgQueryRequired: {
+		English: "q is required",
+		Korean:  "q가 필요합니다.",
+	},
+	MsgQueryTooLong: {
+		English: "q must be at most %d characters",
+		Korean:  "q는 %d자 이하여야 합니다.",
+	},
+	MsgHistoryRemoveFailed: {
+		English: "Failed to remove summary from history",
+		Korean:  "요약 기록에서 삭제하는데 실패했습니다.",
+	},
+	MsgCacheDeleteFailed: {
+		English: "Failed to delete cached summary",
+		Korean:  "캐시된 요약을 삭제하는데 실패했습니다.",
+	},
+	MsgCacheUpdateFailed: {
+		English: "Failed to update cached summary",
+		Korean:  "캐시된 요약을 변경하는데 실패했습니다.",
+	},
+	MsgMustBeOneOf: {
+		English: "%s must be one of: %s",
+		Korean:  "%s는 다음 중 하나여야 합니다: %s",
+	},
+	MsgPositiveInteger: {
+		English: "%s must be a positive integer",
+		Korean:  "%s는 양의 정수여야 합니다.",
+	},
+	MsgNonNegativeInteger: {
+		English: "%s must be a non-negative integer",
+		Korean:  "%s는 0 이상의 정수여야 합니다.",
+	},
+	MsgIdempotencyKeyTooLong: {
+		English: "%s must not be longer than %d characters",
+		Korean:  "%s는 %d자를 넘을 수 없습니다.",
+	},
+	MsgCountersUnavailable: {
+		English: "Counters are not initialized",
+		Korean:  "사용량 카운터가 초기화되지 않았습니다.",
+	},
+	MsgTooManyCaptionLookups: {
+		English: "Too many caption lookups. Please try again later.",
+		Korean:  "자막 조회 요청이 너무 많습니다. 잠시 후 다시 시도해 주세요.",
+	},
+	MsgCaptionListFailed: {
+		English: "Failed to list caption tracks",
+		Korean:  "자막 목록을 가져오는데 실패했습니다.",
+	},
+	MsgTranscriptFailed: {
+		English: "Failed to get transcript",
+		Korean:  "자막을 가져오는데 실패했습니다.",
+	},
+	MsgPlaylistListFailed: {
+		English: "Failed to list playlist videos",
+		Korean:  "재생목록의 영상을 가져오는데 실패했습니다.",
+	},
+	MsgPlaylistTooLarge: {
+		English: "Playlist has more than %d videos",
+		Korean:  "재생목록의 영상이 %d개를 넘습니다.",
+	},
+	MsgPlaylistEmpty: {
+		English: "Playlist has no videos",
+		Korean:  "재생목록에 영상이 없습니다.",
+	},
+	MsgInvalidMessage: {
+		English: "Invalid message: %s",
+		Korean:  "잘못된 메시지입니다: %s",
+	},
+	MsgUnknownMessageType: {
+		English: "Unknown message type",
+		Korean:  "알 수 없는 메시지 유형입니다.",
+	},
+	MsgInvalidVideoIDField: {
+		English: "Invalid videoId",
+		Korean:  "유효하지 않은 videoId입니다.",
+	},
+	MsgInvalidField: {
+		English: "Invalid %s: %s",
+		Korean:  "유효하지 않은 %s: %s",
+	},
+	MsgFieldInvalidUTF8: {
+		English: "must be valid UTF-8",
+		Korean:  "올바른 UTF-8이어야 합니다",
+	},
+	MsgFieldTooLong: {
+		English: "must be at most %d characters (got %d)",
+		Korean:  "%d자 이하여야 합니다 (현재 %d자)",
+	},
+	MsgFieldControlChars: {
+		English: "must not contain control characters",
+		Korean:  "제어 문자를 포함할 수 없습니다",
+	},
+	MsgFieldOneOf: {
+		English: "must be one of %s",
+		Korean:  "다음 중 하나여야 합니다: %s",
+	},
+	MsgFieldInvalidLanguage: {
+		English: "invalid language code %q",
+		Korean:  "유효하지 않은 언어 코드 %q",
+	},
+	MsgFieldTooManyLanguages: {
+		English: "at most %d languages may be requested",
+		Korean:  "언어는 %d개까지 요청할 수 있습니다",
+	},
+	MsgFieldInvalidModel: {
+		English: "invalid model ID %q",
+		Korean:  "유효하지 않은 모델 ID %q",
+	},
+	MsgFieldTemperature: {
+		English: "must be between 0 and %g",
+		Korean:  "0에서 %g 사이여야 합니다",
+	},
+	MsgVideoURLEmpty: {
+		English: "URL is empty",
+		Korean:  "URL이 비어 있습니다",
+	},
+	MsgVideoURLMalformed: {
+		English: "not a valid URL",
+		Korean:  "올바른 URL이 아닙니다",
+	},
+	MsgVideoURLScheme: {
+		English: "not a YouTube video URL: scheme %q is not http or https",
+		Korean:  "YouTube 영상 URL이 아닙니다: 스킴 %q는 http나 https가 아닙니다",
+	},
+	MsgVideoURLCredentials: {
+		English: "not a YouTube video URL: URLs with credentials are not accepted",
+		Korean:  "YouTube 영상 URL이 아닙니다: 인증 정보가 포함된 URL은 허용되지 않습니다",
+	},
+	MsgVideoURLHost: {
+		English: "not a YouTube video URL: host %q is not youtube.com or youtu.be",
+		Korean:  "YouTube 영상 URL이 아닙니다: 호스트 %q는 youtube.com이나 youtu.be가 아닙니다",
+	},
+	MsgVideoURLVideoID: {
+		English: "video ID %q must be 11 letters, digits, '-' or '_'",
+		Korean:  "영상 ID %q는 영문자, 숫자, '-', '_'로 된 11자여야 합니다",
+	},
+	MsgVideoURLNotVideo: {
+		English: "not a YouTube video URL (expected youtube.com/watch?v=, youtu.be/, /shorts/, /live/ or /embed/)",
+		Korean:  "YouTube 영상 URL이 아닙니다 (youtube.com/watch?v=, youtu.be/, /shorts/, /live/, /embed/ 형식이어야 합니다)",
+	},
+	MsgPlaylistURLInvalid: {
+		English: "invalid playlist URL",
+		Korean:  "유효하지 않은 재생목록 URL입니다",
+	},
+	MsgPlaylistURLNotYouTube: {
+		English: "invalid playlist URL: not a YouTube URL",
+		Korean:  "유효하지 않은 재생목록 URL입니다: YouTube URL이 아닙니다",
+	},
+	MsgPlaylistURLNoList: {
+		English: "invalid playlist URL: missing list parameter",
+		Korean:  "유효하지 않은 재생목록 URL입니다: list 파라미터가 없습니다",
+	},
+	MsgPlaylistURLListID: {
+		English: "invalid playlist URL: invalid playlist ID format",
+		Korean:  "유효하지 않은 재생목록 URL입니다: 재생목록 ID 형식이 잘못되었습니다",
+	},
+	MsgCallbackURLMalformed: {
+		English: "invalid callback URL: %v",
+		Korean:  "유효하지 않은 콜백 URL입니다: %v",
+	},
+	MsgCallbackURLScheme: {
+		English: "callback URL must use http or https",
+		Korean:  "콜백 URL은 http나 https를 사용해야 합니다",
+	},
+	MsgCallbackURLCredentials: {
+		English: "callback URL must not contain credentials",
+		Korean:  "콜백 URL에는 인증 정보를 포함할 수 없습니다",
+	},
+	MsgCallbacksDisabled: {
+		English: "callback URLs are not enabled on this server",
+		Korean:  "이 서버에서는 콜백 URL을 사용할 수 없습니다",
+	},
+	MsgCallbackHostNotAllowed: {
+		English: "callback host %q is not allowed",
+		Korean:  "콜백 호스트 %q는 허용되지 않습니다",
+	},
+	MsgCallbackHostUnresolved: {
+		English: "failed to resolve callback host %q: %v",
+		Korean:  "콜백 호스트 %q의 주소를 확인하지 못했습니다: %v",
+	},
+	MsgCallbackHostPrivate: {
+		English: "callback URL must not point to a private or internal address",
+		Korean:  "콜백 URL은 사설 또는 내부 주소를 가리킬 수 없습니다",
+	},
+}
@@ -1,26 +1,56 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/akirose/youtube-summarizer/api"
 	"github.com/akirose/youtube-summarizer/auth"
+	"github.com/akirose/youtube-summarizer/i18n"
+	"github.com/akirose/youtube-summarizer/models"
 	"github.com/akirose/youtube-summarizer/services"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	selfTest := flag.Bool("selftest", false, "run the smoke test against yt-dlp, OpenAI and the cache, then exit")
+	flag.Parse()
+
 	// Load environment variables from .env file
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Warning: .env file not found")
+		services.LogWarn(".env file not found")
+	}
+
+	// 로그 레벨 설정 (LOG_LEVEL)
+	services.ConfigureLogLevel()
+
+	// 셀프 테스트 모드: 서버를 시작하지 않고 점검 후 종료
+	if *selfTest || services.GetEnvBool("SELFTEST", false) {
+		if !runSelfTest() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// OpenTelemetry 트레이싱 초기화 (OTEL_ENABLED=true인 경우에만 활성화)
+	shutdownTracing, err := services.InitTracing(context.Background())
+	if err != nil {
+		services.LogWarn("Failed to initialize tracing: %v", err)
 	}
+	defer shutdownTracing(context.Background())
 
 	// 요약 모듈 초기화 (캐시 및 사용자 요약 디렉토리 초기화)
 	if err := api.InitSummaryModule(); err != nil {
-		log.Printf("Warning: Failed to initialize summary module: %v\n", err)
+		services.LogError("Failed to initialize summary module: %v", err)
 	}
 
 	// Initialize auth
@@ -38,20 +68,8 @@ func main() {
 	// Create Gin router
 	router := gin.Default()
 
-	// CORS 미들웨어 설정
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+	// CORS 미들웨어 설정 (CORS_ALLOWED_ORIGINS에 등록된 출처만 허용)
+	router.Use(api.CORSMiddleware())
 
 	// Load HTML templates
 	router.LoadHTMLGlob("templates/*")
@@ -62,6 +80,9 @@ func main() {
 	router.Static("/js", "../frontend/js")
 	router.Static("/img", "../frontend/img")
 
+	// Prometheus 메트릭 (큐 길이, 작업자 상태, 캐시 적중률)
+	router.GET("/metrics", api.MetricsHandler())
+
 	// Auth routes
 	authGroup := router.Group("/auth")
 	{
@@ -76,6 +97,8 @@ func main() {
 	{
 		userGroup.GET("/info", getUserInfo)
 		userGroup.GET("/api-key-status", getApiKeyStatus) // API 키 상태 확인 엔드포인트 추가
+		userGroup.PUT("/api-key", api.SaveUserAPIKeyHandler)
+		userGroup.DELETE("/api-key", api.DeleteUserAPIKeyHandler)
 	}
 
 	// API routes
@@ -84,28 +107,121 @@ func main() {
 		// 요약 요청은 인증이 필요
 		apiGroup.POST("/summary", auth.IsAuthenticated(), api.HandleSummaryRequest)
 
+		// 캐시된 요약을 버리고 다시 요약 (진행 중인 작업이 있으면 그 결과를 기다림)
+		apiGroup.POST("/summary/regenerate", auth.IsAuthenticated(), api.HandleRegenerateSummary)
+
+		// 재생목록의 모든 영상 요약 요청 (MAX_PLAYLIST_SIZE개까지)
+		apiGroup.POST("/summary/playlist", auth.IsAuthenticated(), api.HandlePlaylistSummaryRequest)
+
+		// URL 검증 (부작용 없는 순수 검증이므로 인증 불필요)
+		apiGroup.GET("/validate-url", api.HandleValidateURL)
+		apiGroup.POST("/validate-url", api.HandleValidateURL)
+
 		// 전체 최근 요약 목록 (이전 버전과의 호환성)
 		apiGroup.GET("/recent-summaries", auth.IsAuthenticated(), api.GetRecentSummariesHandler)
+		apiGroup.GET("/recent-summaries/page", auth.IsAuthenticated(), api.GetRecentSummariesPageHandler)
+
+		// 영상의 자막 언어 목록 (yt-dlp 호출, 사용자별 속도 제한)
+		apiGroup.GET("/captions", auth.IsAuthenticated(), api.HandleListCaptions)
+
+		// 요약 없이 자막만 조회 (?format=json|vtt|srt, 캐시에 없으면 자막 목록 조회와 같은 속도 제한)
+		apiGroup.GET("/transcript", auth.IsAuthenticated(), api.HandleTranscript)
+
+		// 요약 작업 상태 조회 (SSE 대신 폴링하는 클라이언트용)
+		apiGroup.GET("/summary/status/:videoId", auth.IsAuthenticated(), api.GetSummaryStatusHandler)
+
+		// 채널별 캐시된 요약 목록
+		apiGroup.GET("/channel/:channelId/summaries", auth.IsAuthenticated(), api.GetChannelSummariesHandler)
+
+		// 주제별 캐시된 요약 목록 (ENABLE_CATEGORIZATION)
+		apiGroup.GET("/summaries", auth.IsAuthenticated(), api.GetSummariesHandler)
+
+		// 사용자 요약 기록 검색 (제목, ?body=true이면 요약 본문도)
+		apiGroup.GET("/summaries/search", auth.IsAuthenticated(), api.SearchSummariesHandler)
 
 		// 사용자별 최근 요약 목록 (새 API 엔드포인트)
 		apiGroup.GET("/user-recent-summaries", auth.IsAuthenticated(), api.GetUserRecentSummariesHandler)
 
 		// SSE 엔드포인트 (인증 필요)
 		apiGroup.GET("/summary/events", auth.IsAuthenticated(), api.HandleSummaryEvents)
+		apiGroup.GET("/summary/ws", auth.IsAuthenticated(), api.HandleSummaryWebSocket) // SSE 대신 WebSocket으로 이벤트 수신 (작업 취소 가능)
+
+		// 캐시된 요약, 자막, 메타데이터를 하나의 ZIP으로 다운로드
+		apiGroup.GET("/summary/:videoId/archive", auth.IsAuthenticated(), api.HandleSummaryArchive)
+
+		// 캐시된 요약을 Markdown 문서로 내보내기
+		apiGroup.GET("/summary/:videoId/export", auth.IsAuthenticated(), api.ExportSummaryHandler)
+
+		// 요약 삭제 (사용자 기록에서 제거, 관리자는 캐시에서도 삭제)
+		apiGroup.DELETE("/summary/:videoId", auth.IsAuthenticated(), api.DeleteSummaryHandler)
+	}
+
+	// Admin routes (관리자만 접근 가능)
+	adminGroup := router.Group("/admin")
+	adminGroup.Use(auth.IsAuthenticated(), auth.RequireAdmin())
+	{
+		adminGroup.GET("/summary/:videoId/raw", api.GetRawSummaryHandler)
+
+		// 캐시된 요약 목록 (본문 없이 메타데이터만, ?sort=&order=와 페이지 지정)
+		adminGroup.GET("/cache", api.GetCacheListHandler)
+
+		// 캐시된 요약 고정 및 해제 (고정된 요약은 만료되거나 캐시 정리로 삭제되지 않음)
+		adminGroup.PUT("/cache/:videoId/pin", api.PinCacheItemHandler)
+		adminGroup.DELETE("/cache/:videoId/pin", api.UnpinCacheItemHandler)
+
+		// 요약 수와 일별/사용자별 예상 비용 통계
+		adminGroup.GET("/stats", api.HandleAdminStats)
+
+		// 서버 API 키 사용 정책 조회 및 변경 (재시작 시 환경 변수 값으로 복원)
+		adminGroup.GET("/api-key-policy", api.GetAPIKeyPolicyHandler)
+		adminGroup.PUT("/api-key-policy", api.UpdateAPIKeyPolicyHandler)
 	}
 
 	// Start server
-	log.Printf("Server starting on port %s...\n", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Error starting server: %v", err)
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		services.LogInfo("Server starting on port %s...", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error starting server: %v", err)
+		}
+	}()
+
+	// SIGINT/SIGTERM 수신 시 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	stop() // 두 번째 신호는 즉시 종료
+	services.LogInfo("Shutting down server...")
+
+	// 새 작업을 받지 않고 실행 중인 요약 작업이 끝날 때까지 대기 (SHUTDOWN_GRACE_SECONDS)
+	// 끝나지 않은 작업은 작업 저널에 남아 다음 시작 시 다시 처리됨
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), api.ShutdownGracePeriod())
+	defer cancelDrain()
+	if err := api.DrainWorkers(drainCtx); err != nil {
+		services.LogWarn("Summarization jobs did not finish within the grace period; they are re-queued on the next start: %v", err)
+	}
+
+	// SSE 스트림은 끝나지 않으므로 먼저 클라이언트에 종료를 알리고 연결을 닫음
+	api.NotifySSEShutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		services.LogWarn("Server shutdown did not complete: %v", err)
 	}
+
+	// 사용량 카운터 저장 및 캐시 정리 중지
+	api.CloseSummaryModule()
 }
 
+// shutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 // 현재 사용자 정보를 반환하는 핸들러
 func getUserInfo(c *gin.Context) {
 	userInfo, authenticated := auth.GetSessionUser(c)
 	if !authenticated {
-		c.JSON(401, gin.H{"error": "Not authenticated"})
+		c.JSON(401, gin.H{"error": i18n.T(i18n.MsgNotAuthenticated, i18n.RequestLanguage(c.Request))})
 		return
 	}
 
@@ -119,7 +235,7 @@ func getUserInfo(c *gin.Context) {
 func getApiKeyStatus(c *gin.Context) {
 	userInfo, authenticated := auth.GetSessionUser(c)
 	if !authenticated {
-		c.JSON(401, gin.H{"error": "Not authenticated"})
+		c.JSON(401, gin.H{"error": i18n.T(i18n.MsgNotAuthenticated, i18n.RequestLanguage(c.Request))})
 		return
 	}
 
@@ -127,8 +243,17 @@ func getApiKeyStatus(c *gin.Context) {
 	policy := services.GetAPIKeyPolicy()
 	canUseServerKey := policy.CanUseServerKey(userInfo.ID)
 
-	c.JSON(200, gin.H{
+	_, hasStoredKey := models.GetUserAPIKey(userInfo.ID)
+
+	response := gin.H{
 		"needsApiKey":     !canUseServerKey, // 서버 키 사용 불가능한 경우 사용자 API 키 필요
 		"serverKeyPolicy": policy.GetApiKeyPolicy(),
-	})
+		"hasStoredKey":    hasStoredKey,
+		"keySource":       api.ResolveAPIKeySource(c, userInfo.ID), // 다음 요청에 사용될 키 출처 (header|stored|server|none)
+	}
+	// 서버 키 사용량 한도가 설정된 경우 현재 기간의 사용량과 남은 한도
+	if quota, ok := policy.QuotaStatus(userInfo.ID); ok {
+		response["serverKeyQuota"] = quota
+	}
+	c.JSON(200, response)
 }
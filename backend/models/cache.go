@@ -1,32 +1,178 @@
 package models
 
 import (
+	"container/list"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/akirose/youtube-summarizer/services"
 )
 
 // SummaryCache represents the cache for video summaries
 type SummaryCache struct {
-	mutex    sync.RWMutex
-	cacheDir string
-	items    map[string]*CacheItem
+	mutex       sync.RWMutex
+	cacheDir    string
+	sharded     bool // Files are stored in subdirectories named after the first 2 characters of their key
+	items       map[string]*CacheItem
+	ttl         time.Duration            // Default TTL, 0 means items never expire
+	channelTTLs map[string]time.Duration // Per-channel TTL overrides keyed by channel ID
+
+	// Memory budget. Items evicted from memory stay on disk and are reloaded on access.
+	maxMemoryBytes int64                    // 0 means unlimited
+	memoryBytes    int64                    // Estimated size of all items in memory
+	lru            *list.List               // Front is the most recently used; values are *lruEntry
+	lruEntries     map[string]*list.Element // Cache key -> element in lru
+
+	// Entry cap. Least recently accessed items beyond it are removed from memory and disk.
+	maxEntries int                  // 0 means unlimited
+	accessedAt map[string]time.Time // Cache key -> last access, for every item in memory or on disk
+
+	// Channel ID -> cache key -> listing data. Covers items on disk too, not just those in memory.
+	channelIndex  map[string]map[string]ChannelSummary
+	videoChannels map[string]string // Cache key -> channel ID, to find an item's index entry
+
+	// Transcript hash and language -> cache key, to reuse summaries of identical transcripts
+	transcriptIndex map[string]string
+	keyTranscripts  map[string]string // Cache key -> transcriptIndex key, to find an item's index entry
+
+	// Category -> cache key -> listing data, like channelIndex
+	categoryIndex map[string]map[string]ChannelSummary
+	keyCategories map[string]string // Cache key -> category
+
+	// Cache key -> transcript coverage of items with a known coverage, to find low-coverage summaries
+	keyCoverage map[string]float64
+
+	// Cache keys of pinned items, which are never evicted or expired. Covers items on disk too.
+	pinned map[string]bool
+
+	// Cache key -> listing metadata of every indexed item, in memory or on disk. With videoChannels and
+	// pinned, this also tells whether an item on disk has expired without reading its file.
+	keyEntries map[string]CacheEntryInfo
+
+	// Lazy loading. Only file names are listed at startup; items are read on first access and the
+	// indexes above are filled in the background.
+	lazy      bool
+	unindexed map[string]bool // Cache keys of files not read yet
+	indexed   chan struct{}   // Closed when background indexing has finished; nil in eager mode
+
+	stopSweep chan struct{} // Closed by Close to stop the background sweep
+	closeOnce sync.Once
+}
+
+// CacheKey returns the key a summary is cached under: the video ID for summaries in the default
+// language, and "<videoID>.<language>" otherwise. Cache files are named after the key.
+func CacheKey(videoID, language string) string {
+	if language == "" || language == services.DefaultSummaryLanguage {
+		return videoID
+	}
+	return videoID + "." + language
+}
+
+// normalizeCacheKey validates a cache key and normalizes its video ID and language parts
+func normalizeCacheKey(key string) (string, error) {
+	videoID, language, _ := strings.Cut(key, ".")
+	videoID, err := services.NormalizeVideoID(videoID)
+	if err != nil {
+		return "", err
+	}
+	if language == "" {
+		return videoID, nil
+	}
+	language, err = services.NormalizeLanguage(language)
+	if err != nil {
+		return "", err
+	}
+	return CacheKey(videoID, language), nil
+}
+
+// ChannelSummary is the listing data of a cached summary in a channel's summary list
+type ChannelSummary struct {
+	VideoID   string    `json:"videoId"`
+	Language  string    `json:"language,omitempty"` // Set for summaries in other than the default language
+	Title     string    `json:"title"`
+	Channel   string    `json:"channel,omitempty"`
+	ChannelID string    `json:"channelId,omitempty"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// lruEntry tracks an in-memory item's position in the LRU list and its estimated size
+type lruEntry struct {
+	key  string
+	size int64
+}
+
+// CacheOptions configures expiration behavior of a SummaryCache
+type CacheOptions struct {
+	// TTL is the default lifetime of a cache item. 0 disables expiration.
+	TTL time.Duration
+	// ChannelTTLs overrides TTL for items of specific channels (keyed by channel ID).
+	// An override of 0 keeps the channel's items forever.
+	ChannelTTLs map[string]time.Duration
+	// MaxMemoryBytes bounds the estimated size of items kept in memory. Least recently used items
+	// beyond the budget are dropped from memory only; their files stay on disk. 0 means unlimited.
+	MaxMemoryBytes int64
+	// MaxEntries bounds the number of cached items. Storing a new item beyond it deletes the least
+	// recently accessed item from memory and disk. 0 means unlimited.
+	MaxEntries int
+	// SweepInterval is how often expired items are removed from memory and disk in the background.
+	// 0 disables the sweep; expired items are then only hidden from Get.
+	SweepInterval time.Duration
+	// Sharded stores each file in a subdirectory named after the first 2 characters of its key
+	// (e.g. cache/ab/abcdEFGH123.json) instead of directly in the cache directory, which keeps
+	// directories small with many cached videos. Files in the other layout are moved on startup.
+	Sharded bool
+	// LazyLoad only lists the cache files at startup instead of reading all of them into memory.
+	// Items are read from disk on first access; channel, category and transcript indexes are built
+	// in the background, so listings and summary reuse may miss older items right after startup.
+	LazyLoad bool
 }
 
 // CacheItem represents a single cache item
 type CacheItem struct {
-	VideoID    string                    `json:"videoId"`
-	Title      string                    `json:"title"`
-	Summary    string                    `json:"summary"`
-	Timestamps []Timestamp               `json:"timestamps"`
-	Transcript []services.TranscriptItem `json:"transcript,omitempty"` // 트랜스크립트 데이터 저장
-	CreatedAt  time.Time                 `json:"createdAt"`
+	VideoID           string                    `json:"videoId"`
+	Title             string                    `json:"title"`
+	Summary           string                    `json:"summary"`
+	Timestamps        []Timestamp               `json:"timestamps"`
+	Transcript        []services.TranscriptItem `json:"transcript,omitempty"` // 트랜스크립트 데이터 저장
+	Chunks            []services.ChunkSummary   `json:"chunks,omitempty"`     // 청크별 요약 (structured output 사용 시)
+	RawSummary        string                    `json:"rawSummary,omitempty"` // 후처리 전 모델 원본 출력 (STORE_RAW_SUMMARY 사용 시, 디버깅용)
+	Channel           string                    `json:"channel,omitempty"`
+	ChannelID         string                    `json:"channelId,omitempty"`         // 채널별 TTL 적용에 사용
+	Coverage          float64                   `json:"coverage,omitempty"`          // 영상 길이 대비 자막이 덮는 비율 (%)
+	Partial           bool                      `json:"partial,omitempty"`           // 생성 중단으로 일부 청크만 요약된 불완전한 결과
+	Duration          int                       `json:"duration,omitempty"`          // 영상 길이 (초)
+	UploadDate        string                    `json:"uploadDate,omitempty"`        // 업로드 날짜 (YYYYMMDD)
+	Thumbnail         string                    `json:"thumbnail,omitempty"`         // 썸네일 이미지 URL
+	Model             string                    `json:"model,omitempty"`             // 요약에 사용된 모델
+	Language          string                    `json:"language,omitempty"`          // 요약 언어 (기본 언어이면 비어 있음)
+	Style             string                    `json:"style,omitempty"`             // 요약 스타일 (기본 스타일이면 비어 있음)
+	Usage             *services.TokenUsage      `json:"usage,omitempty"`             // 요약 생성에 사용된 토큰 수
+	CostUSD           float64                   `json:"costUsd,omitempty"`           // OPENAI_MODEL_PRICING 기준 예상 비용 (USD, 가격 미등록 모델이면 0)
+	TranscriptHash    string                    `json:"transcriptHash,omitempty"`    // services.TranscriptHash 값 (동일 자막 중복 요약 방지용)
+	ReusedFrom        string                    `json:"reusedFrom,omitempty"`        // 동일한 자막의 요약을 재사용한 경우 원본 영상 ID
+	Category          string                    `json:"category,omitempty"`          // 자동 분류된 주제 (ENABLE_CATEGORIZATION 사용 시)
+	RequestedBy       string                    `json:"requestedBy,omitempty"`       // 요약을 처음 요청한 사용자 ID (재처리 결과 알림용)
+	CoverageCheckedAt *time.Time                `json:"coverageCheckedAt,omitempty"` // 자막 커버리지 개선 여부를 마지막으로 확인한 시각 (REPROCESS_LOW_COVERAGE 사용 시)
+	Pinned            bool                      `json:"pinned,omitempty"`            // 관리자가 고정한 항목 (만료 및 캐시 정리 대상에서 제외)
+	CreatedAt         time.Time                 `json:"createdAt"`
+}
+
+// SummaryStyle은 항목이 요약된 스타일을 반환합니다 (스타일 도입 전에 캐시된 항목은 기본 스타일)
+func (item *CacheItem) SummaryStyle() string {
+	if item.Style == "" {
+		return services.DefaultSummaryStyle
+	}
+	return item.Style
 }
 
 // Timestamp represents a timestamp in the summary
@@ -41,59 +187,108 @@ type VideoSummary struct {
 	VideoID    string `json:"video_id"`    // Video ID
 }
 
-// GetRecentVideoSummaries retrieves the most recent 10 VideoSummary entries
-// Updated to include recent files from the cache directory
-func GetRecentVideoSummaries() []VideoSummary {
+// RecentVideoSummaries retrieves the most recent 15 VideoSummary entries
+// from the files in the cache directory
+func (c *SummaryCache) RecentVideoSummaries() []VideoSummary {
+	summaries, _ := c.RecentVideoSummariesPage(0, 15)
+	return summaries
+}
+
+// RecentVideoSummariesPage lists the cache files newest first and returns the VideoSummary entries
+// of the page selected by offset and limit, and the number of all cache files. Only the files of the
+// page are read; unreadable ones are skipped, so a page may be shorter than limit.
+func (c *SummaryCache) RecentVideoSummariesPage(offset, limit int) ([]VideoSummary, int) {
 	// Fetch all JSON files in the cache directory
-	files, err := filepath.Glob(filepath.Join("cache", "*.json"))
+	files, err := c.cacheFiles()
 	if err != nil {
-		fmt.Printf("Warning: Failed to list cache files: %v\n", err)
-		return nil
+		services.LogWarn("Failed to list cache files: %v", err)
+		return []VideoSummary{}, 0
 	}
 
 	// Sort files by modification time in descending order
-	sort.Slice(files, func(i, j int) bool {
-		infoI, errI := os.Stat(files[i])
-		infoJ, errJ := os.Stat(files[j])
-		if errI != nil || errJ != nil {
-			return false
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
 		}
-		return infoI.ModTime().After(infoJ.ModTime())
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return modTimes[files[i]].After(modTimes[files[j]])
 	})
 
-	// Limit to the most recent 15 files
-	if len(files) > 15 {
-		files = files[:15]
+	total := len(files)
+	if offset >= total {
+		return []VideoSummary{}, total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
 	}
 
-	// Read and parse each file into VideoSummary
-	var recentSummaries []VideoSummary
-	for _, file := range files {
-		f, err := os.Open(file)
+	// Read and parse each file of the page into VideoSummary
+	recentSummaries := []VideoSummary{}
+	for _, file := range files[offset:end] {
+		item, err := readVideoSummary(file)
 		if err != nil {
-			fmt.Printf("Warning: Failed to open cache file %s: %v\n", file, err)
+			services.LogWarn("%v", err)
 			continue
 		}
-		defer f.Close()
+		recentSummaries = append(recentSummaries, item)
+	}
+
+	return recentSummaries, total
+}
 
-		var item CacheItem
-		decoder := json.NewDecoder(f)
-		if err := decoder.Decode(&item); err != nil {
-			fmt.Printf("Warning: Failed to decode cache file %s: %v\n", file, err)
+// readVideoSummary decodes the title and video ID of a cache file
+func readVideoSummary(file string) (VideoSummary, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return VideoSummary{}, fmt.Errorf("failed to open cache file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var item CacheItem
+	if err := json.NewDecoder(f).Decode(&item); err != nil {
+		return VideoSummary{}, fmt.Errorf("failed to decode cache file %s: %w", file, err)
+	}
+	return VideoSummary{
+		VideoTitle: item.Title,
+		VideoID:    item.VideoID,
+	}, nil
+}
+
+// ParseChannelTTLOverrides parses a comma-separated list of channelID:hours pairs
+// (e.g. "UCabc:0,UCdef:6") into per-channel TTLs. 0 hours means never expire.
+func ParseChannelTTLOverrides(value string) (map[string]time.Duration, error) {
+	overrides := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
 			continue
 		}
 
-		recentSummaries = append(recentSummaries, VideoSummary{
-			VideoTitle: item.Title,
-			VideoID:    item.VideoID,
-		})
-	}
+		// Channel IDs never contain ':', so split on the last one
+		sep := strings.LastIndex(entry, ":")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid channel TTL override %q: expected channelID:hours", entry)
+		}
 
-	return recentSummaries
+		hours, err := strconv.ParseFloat(strings.TrimSpace(entry[sep+1:]), 64)
+		if err != nil || hours < 0 {
+			return nil, fmt.Errorf("invalid TTL hours in channel override %q", entry)
+		}
+		overrides[strings.TrimSpace(entry[:sep])] = time.Duration(hours * float64(time.Hour))
+	}
+	return overrides, nil
 }
 
 // NewSummaryCache creates a new cache
 func NewSummaryCache(cacheDir string) (*SummaryCache, error) {
+	return NewSummaryCacheWithOptions(cacheDir, CacheOptions{})
+}
+
+// NewSummaryCacheWithOptions creates a new cache with the given expiration options
+func NewSummaryCacheWithOptions(cacheDir string, opts CacheOptions) (*SummaryCache, error) {
 	// Create cache directory if it doesn't exist
 	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(cacheDir, 0755); err != nil {
@@ -102,62 +297,397 @@ func NewSummaryCache(cacheDir string) (*SummaryCache, error) {
 	}
 
 	cache := &SummaryCache{
-		cacheDir: cacheDir,
-		items:    make(map[string]*CacheItem),
+		cacheDir:        cacheDir,
+		sharded:         opts.Sharded,
+		items:           make(map[string]*CacheItem),
+		ttl:             opts.TTL,
+		channelTTLs:     opts.ChannelTTLs,
+		maxMemoryBytes:  opts.MaxMemoryBytes,
+		lru:             list.New(),
+		lruEntries:      make(map[string]*list.Element),
+		maxEntries:      opts.MaxEntries,
+		accessedAt:      make(map[string]time.Time),
+		channelIndex:    make(map[string]map[string]ChannelSummary),
+		videoChannels:   make(map[string]string),
+		transcriptIndex: make(map[string]string),
+		keyTranscripts:  make(map[string]string),
+		categoryIndex:   make(map[string]map[string]ChannelSummary),
+		keyCategories:   make(map[string]string),
+		keyCoverage:     make(map[string]float64),
+		keyEntries:      make(map[string]CacheEntryInfo),
+		pinned:          make(map[string]bool),
+		lazy:            opts.LazyLoad,
+		unindexed:       make(map[string]bool),
 	}
 
-	// Load existing cache items
+	// Move files written before sharding was switched on or off, then load existing cache items
+	cache.removeTempFiles()
+	cache.migrateLayout()
 	if err := cache.loadFromDisk(); err != nil {
-		fmt.Printf("Warning: Failed to load cache from disk: %v\n", err)
+		services.LogWarn("Failed to load cache from disk: %v", err)
+	}
+
+	if opts.SweepInterval > 0 && cache.canExpire() {
+		cache.stopSweep = make(chan struct{})
+		go cache.sweepLoop(opts.SweepInterval)
 	}
 
 	return cache, nil
 }
 
-// Get retrieves an item from the cache
-func (c *SummaryCache) Get(videoID string) (*CacheItem, bool) {
+// canExpire reports whether any item can ever expire under the configured TTLs
+func (c *SummaryCache) canExpire() bool {
+	if c.ttl > 0 {
+		return true
+	}
+	for _, ttl := range c.channelTTLs {
+		if ttl > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sweepLoop removes expired items every interval until Close is called
+func (c *SummaryCache) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if removed := c.Sweep(); removed > 0 {
+				services.LogInfo("Removed %d expired cache item(s)", removed)
+			}
+		case <-c.stopSweep:
+			return
+		}
+	}
+}
+
+// Sweep removes expired items from memory and disk and returns how many were removed.
+// Items on disk are checked against the indexes, so no files are read. Files not read yet in lazy
+// mode are left for a later sweep, once background indexing has read them.
+func (c *SummaryCache) Sweep() int {
+	now := time.Now()
+	removed := 0
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key := range c.accessedAt {
+		item, inMemory := c.items[key]
+		if !inMemory {
+			if c.unindexed[key] {
+				continue
+			}
+			item = c.indexedItem(key)
+		}
+		if c.isExpired(item, now) {
+			if err := c.removeLocked(key); err != nil {
+				services.LogWarn("Failed to remove expired cache item %s: %v", key, err)
+			}
+			removed++
+		}
+	}
+	return removed
+}
+
+// indexedItem returns an item with the fields the indexes hold about it, enough to tell whether it
+// has expired. The caller must hold c.mutex.
+func (c *SummaryCache) indexedItem(key string) *CacheItem {
+	return &CacheItem{ChannelID: c.videoChannels[key], CreatedAt: c.keyEntries[key].CreatedAt, Pinned: c.pinned[key]}
+}
+
+// Close stops the background sweep
+func (c *SummaryCache) Close() {
+	c.closeOnce.Do(func() {
+		if c.stopSweep != nil {
+			close(c.stopSweep)
+		}
+	})
+}
+
+// Get retrieves an item from the cache by video ID or by a CacheKey for another language.
+// Items older than their TTL are treated as a miss.
+func (c *SummaryCache) Get(key string) (*CacheItem, bool) {
+	key, err := normalizeCacheKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	// Write lock: a hit updates the LRU order and a miss may reload the item from disk
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	item, ok := c.items[key]
+	if ok {
+		c.touch(key)
+	} else if _, onDisk := c.accessedAt[key]; onDisk {
+		// The item was evicted from memory under the memory budget, or not read yet in lazy mode
+		loaded, err := c.loadItemFromDisk(c.cacheFile(key))
+		if err != nil {
+			return nil, false
+		}
+		item = loaded
+		c.storeInMemory(key, item)
+		if c.unindexed[key] {
+			c.indexItem(key, item)
+		}
+	} else {
+		return nil, false
+	}
+
+	if c.isExpired(item, time.Now()) {
+		return nil, false
+	}
+	c.accessedAt[key] = time.Now()
+	return item, true
+}
+
+// storeInMemory adds or replaces an in-memory item and evicts least recently used items over the budget
+func (c *SummaryCache) storeInMemory(key string, item *CacheItem) {
+	c.removeFromMemory(key)
+
+	size := estimateItemSize(item)
+	c.items[key] = item
+	c.lruEntries[key] = c.lru.PushFront(&lruEntry{key: key, size: size})
+	c.memoryBytes += size
+
+	if c.maxMemoryBytes <= 0 {
+		return
+	}
+	// Always keep the item just stored, even if it alone exceeds the budget, and pinned items
+	elem := c.lru.Back()
+	for c.memoryBytes > c.maxMemoryBytes && elem != nil {
+		oldest := elem.Value.(*lruEntry)
+		elem = elem.Prev()
+		if oldest.key != key && !c.pinned[oldest.key] {
+			c.removeFromMemory(oldest.key)
+		}
+	}
+}
+
+// removeFromMemory drops an item from memory without touching its file on disk
+func (c *SummaryCache) removeFromMemory(key string) {
+	if elem, ok := c.lruEntries[key]; ok {
+		c.memoryBytes -= elem.Value.(*lruEntry).size
+		c.lru.Remove(elem)
+		delete(c.lruEntries, key)
+	}
+	delete(c.items, key)
+}
+
+// touch marks an in-memory item as most recently used
+func (c *SummaryCache) touch(key string) {
+	if elem, ok := c.lruEntries[key]; ok {
+		c.lru.MoveToFront(elem)
+	}
+}
+
+// MemoryBytes returns the estimated size of the items currently held in memory
+func (c *SummaryCache) MemoryBytes() int64 {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
+	return c.memoryBytes
+}
+
+// estimateItemSize approximates the memory used by a cache item from its text content.
+// Transcripts dominate the size of long videos, so every transcript entry is counted.
+func estimateItemSize(item *CacheItem) int64 {
+	const structOverhead = 64
+	const entryOverhead = 32 // Per transcript item, chunk and timestamp (start/duration fields and slice slot)
 
-	item, ok := c.items[videoID]
-	return item, ok
+	size := int64(structOverhead)
+	size += int64(len(item.VideoID) + len(item.Title) + len(item.Summary) + len(item.RawSummary) + len(item.Channel) + len(item.ChannelID))
+	for _, t := range item.Transcript {
+		size += int64(len(t.Text)) + entryOverhead
+	}
+	for _, chunk := range item.Chunks {
+		size += int64(len(chunk.Text)) + entryOverhead
+	}
+	for _, ts := range item.Timestamps {
+		size += int64(len(ts.Text)) + entryOverhead
+	}
+	return size
+}
+
+// ttlFor returns the TTL that applies to an item, preferring its channel's override
+func (c *SummaryCache) ttlFor(item *CacheItem) time.Duration {
+	if item.ChannelID != "" {
+		if ttl, ok := c.channelTTLs[item.ChannelID]; ok {
+			return ttl
+		}
+	}
+	return c.ttl
+}
+
+// isExpired reports whether an item has outlived its TTL. Pinned items never expire.
+func (c *SummaryCache) isExpired(item *CacheItem, now time.Time) bool {
+	if item.Pinned {
+		return false
+	}
+	ttl := c.ttlFor(item)
+	return ttl > 0 && now.Sub(item.CreatedAt) > ttl
 }
 
 // Set adds an item to the cache
 func (c *SummaryCache) Set(videoID, title, summary string, timestamps []Timestamp, transcript []services.TranscriptItem) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	item := &CacheItem{
+	return c.SetItem(&CacheItem{
 		VideoID:    videoID,
 		Title:      title,
 		Summary:    summary,
 		Timestamps: timestamps,
 		Transcript: transcript,
-		CreatedAt:  time.Now(),
+	})
+}
+
+// SetItem adds a fully populated item to the cache under the CacheKey of its video ID and language.
+// CreatedAt is kept when already set so that updating an existing item does not reset its age.
+func (c *SummaryCache) SetItem(item *CacheItem) error {
+	if item == nil || item.VideoID == "" {
+		return fmt.Errorf("cache item must have a video ID")
+	}
+	videoID, err := services.NormalizeVideoID(item.VideoID)
+	if err != nil {
+		return fmt.Errorf("invalid cache key %q: %w", item.VideoID, err)
+	}
+	language, err := services.NormalizeLanguage(item.Language)
+	if err != nil {
+		return fmt.Errorf("invalid cache item language: %w", err)
+	}
+	if language == services.DefaultSummaryLanguage {
+		language = ""
+	}
+	item.VideoID = videoID
+	item.Language = language
+	if item.Style == services.DefaultSummaryStyle {
+		item.Style = ""
+	}
+	key := CacheKey(videoID, language)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+
+	if _, exists := c.accessedAt[key]; !exists {
+		// Make room for the new item; replacing an existing one keeps the count
+		for c.maxEntries > 0 && len(c.accessedAt) >= c.maxEntries {
+			if !c.evictLeastRecentlyAccessed() {
+				break // Only pinned items are left
+			}
+		}
+	}
+	// A regenerated summary stays pinned
+	if c.pinned[key] {
+		item.Pinned = true
 	}
+	c.accessedAt[key] = time.Now()
 
-	c.items[videoID] = item
+	c.storeInMemory(key, item)
+	c.indexItem(key, item)
 
 	// Save to disk
-	return c.saveToDisk(videoID, item)
+	return c.saveToDisk(key, item)
 }
 
-// Delete removes an item from the cache
-func (c *SummaryCache) Delete(videoID string) error {
+// Delete removes an item from the cache by video ID or CacheKey
+func (c *SummaryCache) Delete(key string) error {
+	key, err := normalizeCacheKey(key)
+	if err != nil {
+		return err
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Check if item exists
-	if _, ok := c.items[videoID]; !ok {
-		return nil
+	return c.removeLocked(key)
+}
+
+// IsPinned reports whether the item by video ID or CacheKey is pinned. Files not read yet in lazy mode
+// are read first, since whether they are pinned is only known from the file.
+func (c *SummaryCache) IsPinned(key string) bool {
+	key, err := normalizeCacheKey(key)
+	if err != nil {
+		return false
 	}
 
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.unindexed[key] {
+		if item, err := c.loadItemFromDisk(c.cacheFile(key)); err == nil {
+			c.indexItem(key, item)
+		}
+	}
+	return c.pinned[key]
+}
+
+// Pin keeps an item by video ID or CacheKey from being evicted or expiring, also after a restart.
+// It returns false if there is no such item.
+func (c *SummaryCache) Pin(key string) (bool, error) {
+	return c.setPinned(key, true)
+}
+
+// Unpin lets a pinned item be evicted and expire again. It returns false if there is no such item.
+func (c *SummaryCache) Unpin(key string) (bool, error) {
+	return c.setPinned(key, false)
+}
+
+// setPinned updates the pinned state of an item in memory, the index and on disk
+func (c *SummaryCache) setPinned(key string, pinned bool) (bool, error) {
+	key, err := normalizeCacheKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		if _, onDisk := c.accessedAt[key]; !onDisk {
+			return false, nil
+		}
+		if item, err = c.loadItemFromDisk(c.cacheFile(key)); err != nil {
+			return false, err
+		}
+	}
+	if item.Pinned == pinned {
+		return true, nil
+	}
+
+	// Items are shared with readers of Get, so a copy is stored
+	updated := *item
+	updated.Pinned = pinned
+	if err := c.saveToDisk(key, &updated); err != nil {
+		return false, err
+	}
+	if ok {
+		c.storeInMemory(key, &updated)
+	}
+	c.indexPinned(key, &updated)
+	return true, nil
+}
+
+// removeLocked removes an item from memory, the indexes and disk. The caller must hold c.mutex.
+func (c *SummaryCache) removeLocked(key string) error {
 	// Remove from memory
-	delete(c.items, videoID)
+	c.removeFromMemory(key)
+	c.unindexChannel(key)
+	c.unindexTranscript(key)
+	c.unindexCategory(key)
+	delete(c.keyCoverage, key)
+	delete(c.keyEntries, key)
+	delete(c.pinned, key)
+	delete(c.accessedAt, key)
+	delete(c.unindexed, key)
 
-	// Remove from disk
-	filename := filepath.Join(c.cacheDir, videoID+".json")
+	// Remove from disk (the item may exist only on disk after being evicted from memory)
+	filename := c.cacheFile(key)
 	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove cache file: %w", err)
 	}
@@ -165,6 +695,41 @@ func (c *SummaryCache) Delete(videoID string) error {
 	return nil
 }
 
+// evictLeastRecentlyAccessed removes the unpinned item accessed longest ago from memory and disk
+// and reports whether there was one. Files not read yet in lazy mode are read first, since whether
+// they are pinned is only known from the file. The caller must hold c.mutex.
+func (c *SummaryCache) evictLeastRecentlyAccessed() bool {
+	for {
+		oldestKey := ""
+		var oldest time.Time
+		for key, accessed := range c.accessedAt {
+			if c.pinned[key] {
+				continue
+			}
+			if oldestKey == "" || accessed.Before(oldest) {
+				oldestKey, oldest = key, accessed
+			}
+		}
+		if oldestKey == "" {
+			return false
+		}
+		if c.unindexed[oldestKey] {
+			if item, err := c.loadItemFromDisk(c.cacheFile(oldestKey)); err == nil {
+				c.indexItem(oldestKey, item)
+				if item.Pinned {
+					continue
+				}
+			}
+		}
+		if err := c.removeLocked(oldestKey); err != nil {
+			// Forget the item anyway so the cap is not blocked by a file that cannot be removed
+			services.LogWarn("Failed to evict cache item %s: %v", oldestKey, err)
+			delete(c.accessedAt, oldestKey)
+		}
+		return true
+	}
+}
+
 // Clear removes all items from the cache
 func (c *SummaryCache) Clear() error {
 	c.mutex.Lock()
@@ -172,90 +737,457 @@ func (c *SummaryCache) Clear() error {
 
 	// Clear memory cache
 	c.items = make(map[string]*CacheItem)
+	c.lru.Init()
+	c.lruEntries = make(map[string]*list.Element)
+	c.memoryBytes = 0
+	c.accessedAt = make(map[string]time.Time)
+	c.channelIndex = make(map[string]map[string]ChannelSummary)
+	c.videoChannels = make(map[string]string)
+	c.transcriptIndex = make(map[string]string)
+	c.keyTranscripts = make(map[string]string)
+	c.categoryIndex = make(map[string]map[string]ChannelSummary)
+	c.keyCategories = make(map[string]string)
+	c.keyCoverage = make(map[string]float64)
+	c.keyEntries = make(map[string]CacheEntryInfo)
+	c.pinned = make(map[string]bool)
+	c.unindexed = make(map[string]bool)
 
 	// Remove all files from cache directory
-	files, err := filepath.Glob(filepath.Join(c.cacheDir, "*.json"))
+	files, err := c.cacheFiles()
 	if err != nil {
 		return fmt.Errorf("failed to list cache files: %w", err)
 	}
 
 	for _, file := range files {
 		if err := os.Remove(file); err != nil {
-			fmt.Printf("Warning: Failed to remove cache file %s: %v\n", file, err)
+			services.LogWarn("Failed to remove cache file %s: %v", file, err)
 		}
 	}
 
 	return nil
 }
 
-// saveToDisk saves a cache item to disk
-func (c *SummaryCache) saveToDisk(videoID string, item *CacheItem) error {
-	// Create cache file
-	filename := filepath.Join(c.cacheDir, videoID+".json")
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create cache file: %w", err)
+// saveToDisk saves a cache item to disk. The file is written next to its final path and renamed
+// into place, so a crash or a concurrent write never leaves a truncated file behind.
+func (c *SummaryCache) saveToDisk(key string, item *CacheItem) error {
+	if err := writeFileAtomic(c.cacheFile(key), item); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
 	}
-	defer file.Close()
+	return nil
+}
 
-	// Write cache item to file
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(item); err != nil {
-		return fmt.Errorf("failed to encode cache item: %w", err)
+// removeTempFiles deletes temporary files left behind by writes interrupted by a crash
+func (c *SummaryCache) removeTempFiles() {
+	for _, pattern := range []string{"*.json.tmp-*", filepath.Join("??", "*.json.tmp-*")} {
+		files, _ := filepath.Glob(filepath.Join(c.cacheDir, pattern))
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				services.LogWarn("Failed to remove temporary cache file %s: %v", file, err)
+			}
+		}
 	}
-
-	return nil
 }
 
 // loadFromDisk loads cache items from disk
 func (c *SummaryCache) loadFromDisk() error {
 	// Find all cache files
-	files, err := filepath.Glob(filepath.Join(c.cacheDir, "*.json"))
+	files, err := c.cacheFiles()
 	if err != nil {
 		return fmt.Errorf("failed to list cache files: %w", err)
 	}
 
+	// Load the most recently written files last so they are the ones kept under a memory budget
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return modTimes[files[i]].Before(modTimes[files[j]])
+	})
+
 	// Load each file
 	for _, file := range files {
-		// Extract video ID from filename
-		videoID := filepath.Base(file)
-		videoID = videoID[:len(videoID)-5] // Remove .json extension
+		// Extract the cache key from filename
+		key := filepath.Base(file)
+		key = key[:len(key)-5] // Remove .json extension
 
-		// Open file
-		f, err := os.Open(file)
-		if err != nil {
-			fmt.Printf("Warning: Failed to open cache file %s: %v\n", file, err)
+		// Access times are not persisted, so the last write stands in for them
+		if c.lazy {
+			c.accessedAt[key] = modTimes[file]
+			c.unindexed[key] = true
 			continue
 		}
 
-		// Decode file
-		var item CacheItem
-		decoder := json.NewDecoder(f)
-		if err := decoder.Decode(&item); err != nil {
-			f.Close()
-			fmt.Printf("Warning: Failed to decode cache file %s: %v\n", file, err)
+		item, err := c.loadItemFromDisk(file)
+		if err != nil {
+			services.LogWarn("%v", err)
 			continue
 		}
-		f.Close()
 
 		// Add to memory cache
-		c.items[videoID] = &item
+		c.storeInMemory(key, item)
+		c.accessedAt[key] = modTimes[file]
+		c.indexItem(key, item)
+	}
+
+	// Apply a cap lowered since the items were stored
+	for c.maxEntries > 0 && len(c.accessedAt) > c.maxEntries {
+		if !c.evictLeastRecentlyAccessed() {
+			break
+		}
+	}
+
+	if c.lazy {
+		services.LogInfo("Found %d cached item(s); reading them on first access", len(c.accessedAt))
+		// Newest first, so recent summaries show up in listings soonest
+		sort.SliceStable(files, func(i, j int) bool {
+			return modTimes[files[i]].After(modTimes[files[j]])
+		})
+		c.indexed = make(chan struct{})
+		go c.indexFromDisk(files)
 	}
 
 	return nil
 }
 
+// indexFromDisk reads the files not read yet in lazy mode and adds their items to the indexes
+// without keeping them in memory. Files are read without holding the lock.
+func (c *SummaryCache) indexFromDisk(files []string) {
+	defer close(c.indexed)
+
+	for _, file := range files {
+		key := strings.TrimSuffix(filepath.Base(file), ".json")
+		c.mutex.RLock()
+		pending := c.unindexed[key]
+		c.mutex.RUnlock()
+		if !pending {
+			continue // Read by Get, replaced by SetItem or removed in the meantime
+		}
+
+		item, err := c.loadItemFromDisk(file)
+		if err != nil {
+			services.LogWarn("%v", err)
+			continue
+		}
+
+		c.mutex.Lock()
+		if c.unindexed[key] {
+			c.indexItem(key, item)
+		}
+		c.mutex.Unlock()
+	}
+}
+
+// indexItem records an item in all indexes
+func (c *SummaryCache) indexItem(key string, item *CacheItem) {
+	c.indexChannel(key, item)
+	c.indexTranscript(key, item)
+	c.indexCategory(key, item)
+	c.indexCoverage(key, item)
+	c.indexPinned(key, item)
+	c.keyEntries[key] = newCacheEntryInfo(key, item)
+	delete(c.unindexed, key)
+}
+
+// indexPinned records whether an item is pinned
+func (c *SummaryCache) indexPinned(key string, item *CacheItem) {
+	if item.Pinned {
+		c.pinned[key] = true
+	} else {
+		delete(c.pinned, key)
+	}
+}
+
+// indexChannel records an item in the channel index, moving it if its channel changed
+func (c *SummaryCache) indexChannel(key string, item *CacheItem) {
+	c.unindexChannel(key)
+	if item.ChannelID == "" {
+		return
+	}
+	if c.channelIndex[item.ChannelID] == nil {
+		c.channelIndex[item.ChannelID] = make(map[string]ChannelSummary)
+	}
+	c.videoChannels[key] = item.ChannelID
+	c.channelIndex[item.ChannelID][key] = newChannelSummary(item)
+}
+
+// newChannelSummary returns the listing data of an item
+func newChannelSummary(item *CacheItem) ChannelSummary {
+	return ChannelSummary{
+		VideoID:   item.VideoID,
+		Language:  item.Language,
+		Title:     item.Title,
+		Channel:   item.Channel,
+		ChannelID: item.ChannelID,
+		Thumbnail: item.Thumbnail,
+		Category:  item.Category,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+// indexCategory records an item in the category index, moving it if its category changed
+func (c *SummaryCache) indexCategory(key string, item *CacheItem) {
+	c.unindexCategory(key)
+	if item.Category == "" {
+		return
+	}
+	if c.categoryIndex[item.Category] == nil {
+		c.categoryIndex[item.Category] = make(map[string]ChannelSummary)
+	}
+	c.keyCategories[key] = item.Category
+	c.categoryIndex[item.Category][key] = newChannelSummary(item)
+}
+
+// unindexCategory removes an item from the category index
+func (c *SummaryCache) unindexCategory(key string) {
+	category, ok := c.keyCategories[key]
+	if !ok {
+		return
+	}
+	delete(c.keyCategories, key)
+	delete(c.categoryIndex[category], key)
+	if len(c.categoryIndex[category]) == 0 {
+		delete(c.categoryIndex, category)
+	}
+}
+
+// indexCoverage records the transcript coverage of an item, if it is known
+func (c *SummaryCache) indexCoverage(key string, item *CacheItem) {
+	if item.Coverage > 0 {
+		c.keyCoverage[key] = item.Coverage
+	} else {
+		delete(c.keyCoverage, key)
+	}
+}
+
+// LowCoverageKeys returns the sorted cache keys of items whose transcript covers less than
+// threshold percent of the video. Items with an unknown coverage are not included.
+func (c *SummaryCache) LowCoverageKeys(threshold float64) []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var keys []string
+	for key, coverage := range c.keyCoverage {
+		if coverage < threshold {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unindexChannel removes an item from the channel index
+func (c *SummaryCache) unindexChannel(key string) {
+	channelID, ok := c.videoChannels[key]
+	if !ok {
+		return
+	}
+	delete(c.videoChannels, key)
+	delete(c.channelIndex[channelID], key)
+	if len(c.channelIndex[channelID]) == 0 {
+		delete(c.channelIndex, channelID)
+	}
+}
+
+// transcriptIndexKey returns the transcriptIndex key of a transcript hash and summary language
+func transcriptIndexKey(hash, language string) string {
+	return hash + "." + language
+}
+
+// indexTranscript records a complete item in the transcript index. Items cached before hashes were
+// stored are hashed from their transcript.
+func (c *SummaryCache) indexTranscript(key string, item *CacheItem) {
+	c.unindexTranscript(key)
+	if item.Partial {
+		return
+	}
+	hash := item.TranscriptHash
+	if hash == "" {
+		hash = services.TranscriptHash(item.Transcript)
+	}
+	if hash == "" {
+		return
+	}
+	indexKey := transcriptIndexKey(hash, item.Language)
+	c.transcriptIndex[indexKey] = key
+	c.keyTranscripts[key] = indexKey
+}
+
+// unindexTranscript removes an item from the transcript index
+func (c *SummaryCache) unindexTranscript(key string) {
+	indexKey, ok := c.keyTranscripts[key]
+	if !ok {
+		return
+	}
+	delete(c.keyTranscripts, key)
+	if c.transcriptIndex[indexKey] == key {
+		delete(c.transcriptIndex, indexKey)
+	}
+}
+
+// FindByTranscriptHash returns a complete summary in the given language of a video whose transcript
+// has the given services.TranscriptHash, if one is cached.
+func (c *SummaryCache) FindByTranscriptHash(hash, language string) (*CacheItem, bool) {
+	if hash == "" {
+		return nil, false
+	}
+	language, err := services.NormalizeLanguage(language)
+	if err != nil {
+		return nil, false
+	}
+	if language == services.DefaultSummaryLanguage {
+		language = ""
+	}
+
+	c.mutex.RLock()
+	key, ok := c.transcriptIndex[transcriptIndexKey(hash, language)]
+	c.mutex.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return c.Get(key)
+}
+
+// ListByChannel returns the cached summaries of a channel's videos, newest first,
+// skipping expired items. offset and limit select a page; total is the number of all matches.
+// A channel without cached summaries yields an empty list.
+func (c *SummaryCache) ListByChannel(channelID string, offset, limit int) ([]ChannelSummary, int) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.page(c.channelIndex[channelID], offset, limit)
+}
+
+// ListByCategory returns the cached summaries classified into a category, newest first,
+// like ListByChannel.
+func (c *SummaryCache) ListByCategory(category string, offset, limit int) ([]ChannelSummary, int) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.page(c.categoryIndex[category], offset, limit)
+}
+
+// CacheEntryInfo is the metadata of a cached item for admin listings, without the summary and transcript bodies
+type CacheEntryInfo struct {
+	Key              string    `json:"key"`
+	VideoID          string    `json:"videoId"`
+	Language         string    `json:"language,omitempty"`
+	Style            string    `json:"style,omitempty"`
+	Title            string    `json:"title"`
+	Channel          string    `json:"channel,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	TranscriptLength int       `json:"transcriptLength"` // Characters of all transcript segments
+	SummaryLength    int       `json:"summaryLength"`    // Characters of the summary
+	Partial          bool      `json:"partial,omitempty"`
+	Pinned           bool      `json:"pinned,omitempty"`
+	Expired          bool      `json:"expired,omitempty"` // Not served anymore, but not swept yet
+}
+
+// List returns the metadata of every cached item, in memory or on disk, in no particular order.
+// With lazy loading, files not read yet by the background indexing are not included; their number is
+// returned as well.
+func (c *SummaryCache) List() ([]CacheEntryInfo, int) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntryInfo, 0, len(c.keyEntries))
+	for key, entry := range c.keyEntries {
+		entry.Pinned = c.pinned[key]
+		entry.Expired = c.isExpired(c.indexedItem(key), now)
+		entries = append(entries, entry)
+	}
+	return entries, len(c.unindexed)
+}
+
+// newCacheEntryInfo returns the listing metadata of an item. Expired is left for List to fill in.
+func newCacheEntryInfo(key string, item *CacheItem) CacheEntryInfo {
+	transcriptLength := 0
+	for _, segment := range item.Transcript {
+		transcriptLength += utf8.RuneCountInString(segment.Text)
+	}
+	return CacheEntryInfo{
+		Key:              key,
+		VideoID:          item.VideoID,
+		Language:         item.Language,
+		Style:            item.Style,
+		Title:            item.Title,
+		Channel:          item.Channel,
+		CreatedAt:        item.CreatedAt,
+		TranscriptLength: transcriptLength,
+		SummaryLength:    utf8.RuneCountInString(item.Summary),
+		Partial:          item.Partial,
+		Pinned:           item.Pinned,
+	}
+}
+
+// page sorts index entries newest first, skipping expired items, and returns the selected page
+// and the number of all unexpired entries. The caller must hold c.mutex.
+func (c *SummaryCache) page(entries map[string]ChannelSummary, offset, limit int) ([]ChannelSummary, int) {
+	now := time.Now()
+	summaries := []ChannelSummary{}
+	for key, summary := range entries {
+		if c.isExpired(&CacheItem{ChannelID: summary.ChannelID, CreatedAt: summary.CreatedAt, Pinned: c.pinned[key]}, now) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+	})
+
+	total := len(summaries)
+	if offset >= total {
+		return []ChannelSummary{}, total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return summaries[offset:end], total
+}
+
+// loadItemFromDisk reads a single cache file
+func (c *SummaryCache) loadItemFromDisk(file string) (*CacheItem, error) {
+	// Open file
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	// Decode file
+	var item CacheItem
+	decoder := json.NewDecoder(f)
+	if err := decoder.Decode(&item); err != nil {
+		return nil, fmt.Errorf("failed to decode cache file %s: %w", file, err)
+	}
+
+	return &item, nil
+}
+
 // AddUserSummaryToCache는 캐시에 비디오 요약을 추가하고 동시에 사용자의 요약 목록에도 추가합니다.
 func (c *SummaryCache) AddUserSummaryToCache(userID, videoID, title, summary string, timestamps []Timestamp, transcript []services.TranscriptItem) error {
+	return c.AddUserSummaryItemToCache(userID, &CacheItem{
+		VideoID:    videoID,
+		Title:      title,
+		Summary:    summary,
+		Timestamps: timestamps,
+		Transcript: transcript,
+	})
+}
+
+// AddUserSummaryItemToCache는 완성된 캐시 항목을 저장하고 사용자의 요약 목록에도 추가합니다.
+func (c *SummaryCache) AddUserSummaryItemToCache(userID string, item *CacheItem) error {
 	// 먼저 글로벌 캐시에 추가
-	err := c.Set(videoID, title, summary, timestamps, transcript)
+	err := c.SetItem(item)
 	if err != nil {
 		return fmt.Errorf("글로벌 캐시에 추가 실패: %w", err)
 	}
 
 	// 사용자의 요약 목록에 추가
-	err = AddUserSummary(userID, videoID, title)
+	err = AddUserSummary(userID, item.VideoID, item.Title)
 	if err != nil {
 		return fmt.Errorf("사용자 요약 목록에 추가 실패: %w", err)
 	}
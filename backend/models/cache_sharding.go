@@ -0,0 +1,83 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// shardPrefixLength is the number of leading characters of a cache key naming its shard directory
+const shardPrefixLength = 2
+
+// cacheFile returns the path of the file an item is stored in: <cacheDir>/<key>.json, or
+// <cacheDir>/<first 2 characters of key>/<key>.json when sharding is enabled
+func (c *SummaryCache) cacheFile(key string) string {
+	if c.sharded && len(key) >= shardPrefixLength {
+		return filepath.Join(c.cacheDir, key[:shardPrefixLength], key+".json")
+	}
+	return filepath.Join(c.cacheDir, key+".json")
+}
+
+// cacheFiles lists the files of all cached items in the configured layout
+func (c *SummaryCache) cacheFiles() ([]string, error) {
+	if c.sharded {
+		return filepath.Glob(filepath.Join(c.cacheDir, "??", "*.json"))
+	}
+	return filepath.Glob(filepath.Join(c.cacheDir, "*.json"))
+}
+
+// migrateLayout moves files written in the other layout into the configured one, so sharding can be
+// switched on or off without losing cached summaries. Files that can't be moved are left in place.
+func (c *SummaryCache) migrateLayout() {
+	pattern := filepath.Join(c.cacheDir, "??", "*.json")
+	if c.sharded {
+		pattern = filepath.Join(c.cacheDir, "*.json")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		services.LogWarn("Failed to list cache files for migration: %v", err)
+		return
+	}
+
+	moved := 0
+	shards := make(map[string]bool)
+	for _, file := range files {
+		name := filepath.Base(file)
+		key := name[:len(name)-5] // Remove .json extension
+		if len(key) < shardPrefixLength {
+			continue
+		}
+		if !c.sharded {
+			dir := filepath.Dir(file)
+			if filepath.Base(dir) != key[:shardPrefixLength] {
+				continue // Not a shard directory
+			}
+			shards[dir] = true
+		}
+
+		target := c.cacheFile(key)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			services.LogWarn("Failed to create cache shard directory for %s: %v", key, err)
+			continue
+		}
+		if err := os.Rename(file, target); err != nil {
+			services.LogWarn("Failed to move cache file %s: %v", file, err)
+			continue
+		}
+		moved++
+	}
+
+	// Remove shard directories left empty; ones that still hold files are kept
+	for dir := range shards {
+		os.Remove(dir)
+	}
+
+	if moved > 0 {
+		layout := "flat"
+		if c.sharded {
+			layout = "sharded"
+		}
+		services.LogInfo("Moved %d cache file(s) to the %s cache layout", moved, layout)
+	}
+}
@@ -0,0 +1,523 @@
+package models
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChannelTTLOverrides(t *testing.T) {
+	overrides, err := ParseChannelTTLOverrides("UCnews:2, UCtutorial:0,UChalf:0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, overrides["UCnews"])
+	assert.Equal(t, time.Duration(0), overrides["UCtutorial"])
+	assert.Equal(t, 30*time.Minute, overrides["UChalf"])
+
+	_, err = ParseChannelTTLOverrides("UCnews")
+	assert.Error(t, err)
+	_, err = ParseChannelTTLOverrides("UCnews:-1")
+	assert.Error(t, err)
+}
+
+func TestCacheChannelTTLOverride(t *testing.T) {
+	cache, err := NewSummaryCacheWithOptions(t.TempDir(), CacheOptions{
+		TTL: time.Hour,
+		ChannelTTLs: map[string]time.Duration{
+			"UCnews":      time.Minute,
+			"UCevergreen": 0,
+		},
+	})
+	assert.NoError(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", ChannelID: "UCnews", CreatedAt: time.Now().Add(-5 * time.Minute)}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb", ChannelID: "UCevergreen", CreatedAt: old}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "ccccccccccc", ChannelID: "UCother", CreatedAt: old}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "ddddddddddd", ChannelID: "UCother"}))
+
+	_, found := cache.Get("aaaaaaaaaaa")
+	assert.False(t, found, "volatile channel item should expire after its override TTL")
+	_, found = cache.Get("bbbbbbbbbbb")
+	assert.True(t, found, "evergreen channel item should never expire")
+	_, found = cache.Get("ccccccccccc")
+	assert.False(t, found, "items without override should use the global TTL")
+	_, found = cache.Get("ddddddddddd")
+	assert.True(t, found)
+}
+
+func TestCacheMaxEntriesEvictsLeastRecentlyAccessed(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{MaxEntries: 3})
+	assert.NoError(t, err)
+
+	assert.NoError(t, cache.Set("aaaaaaaaaaa", "A", "summary", nil, nil))
+	assert.NoError(t, cache.Set("bbbbbbbbbbb", "B", "summary", nil, nil))
+	assert.NoError(t, cache.Set("ccccccccccc", "C", "summary", nil, nil))
+	_, found := cache.Get("aaaaaaaaaaa") // The oldest item is now the most recently accessed one
+	assert.True(t, found)
+
+	// Replacing an existing item does not count as a new entry
+	assert.NoError(t, cache.Set("ccccccccccc", "C", "updated", nil, nil))
+	assert.FileExists(t, filepath.Join(dir, "bbbbbbbbbbb.json"))
+
+	assert.NoError(t, cache.Set("ddddddddddd", "D", "summary", nil, nil))
+
+	cache.mutex.RLock()
+	_, evictedInMemory := cache.items["bbbbbbbbbbb"]
+	count := len(cache.items)
+	cache.mutex.RUnlock()
+	assert.False(t, evictedInMemory)
+	assert.Equal(t, 3, count)
+	assert.NoFileExists(t, filepath.Join(dir, "bbbbbbbbbbb.json"))
+	_, found = cache.Get("bbbbbbbbbbb")
+	assert.False(t, found)
+	for _, key := range []string{"aaaaaaaaaaa", "ccccccccccc", "ddddddddddd"} {
+		_, found := cache.Get(key)
+		assert.True(t, found, key)
+	}
+}
+
+func TestCachePinnedItemsAreNotEvicted(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{MaxEntries: 2, TTL: time.Hour})
+	assert.NoError(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Summary: "pinned", CreatedAt: old}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb", Summary: "expired", CreatedAt: old}))
+	found, err := cache.Pin("aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	found, err = cache.Pin("ccccccccccc")
+	assert.NoError(t, err)
+	assert.False(t, found, "there is no such item")
+
+	// The pinned item doesn't expire
+	item, found := cache.Get("aaaaaaaaaaa")
+	assert.True(t, found)
+	assert.True(t, item.Pinned)
+	assert.Equal(t, 1, cache.Sweep())
+
+	// The eviction pass removes the unpinned older items, even when they were accessed more recently
+	assert.NoError(t, cache.Set("ccccccccccc", "C", "summary", nil, nil))
+	assert.NoError(t, cache.Set("ddddddddddd", "D", "summary", nil, nil))
+	assert.NoError(t, cache.Set("eeeeeeeeeee", "E", "summary", nil, nil))
+	_, found = cache.Get("ddddddddddd")
+	assert.False(t, found)
+	_, found = cache.Get("aaaaaaaaaaa")
+	assert.True(t, found)
+
+	// Only pinned items are left: the cap is exceeded instead of evicting them
+	_, err = cache.Pin("eeeeeeeeeee")
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Set("fffffffffff", "F", "summary", nil, nil))
+	for _, key := range []string{"aaaaaaaaaaa", "eeeeeeeeeee", "fffffffffff"} {
+		_, found := cache.Get(key)
+		assert.True(t, found, key)
+	}
+
+	// The pinned state is stored on disk and kept when the summary is regenerated
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Summary: "regenerated", CreatedAt: old}))
+	reloaded, err := NewSummaryCacheWithOptions(dir, CacheOptions{MaxEntries: 2, TTL: time.Hour})
+	assert.NoError(t, err)
+	item, found = reloaded.Get("aaaaaaaaaaa")
+	if assert.True(t, found) {
+		assert.True(t, item.Pinned)
+		assert.Equal(t, "regenerated", item.Summary)
+	}
+	_, found = reloaded.Get("fffffffffff")
+	assert.False(t, found, "the cap is applied to the unpinned items on startup")
+
+	found, err = reloaded.Unpin("aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	_, found = reloaded.Get("aaaaaaaaaaa")
+	assert.False(t, found, "expired once unpinned")
+}
+
+func TestCacheMemoryBudgetEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{MaxMemoryBytes: 2700})
+	assert.NoError(t, err)
+
+	longTranscript := make([]services.TranscriptItem, 40)
+	for i := range longTranscript {
+		longTranscript[i] = services.TranscriptItem{Text: strings.Repeat("x", 30), Start: float64(i)}
+	}
+
+	// Two small items (~80 bytes each) and one large item (~2.5KB): only one small item fits next to the large one
+	assert.NoError(t, cache.Set("smallVideo1", "Small 1", "short", nil, nil))
+	assert.NoError(t, cache.Set("smallVideo2", "Small 2", "short", nil, nil))
+	_, found := cache.Get("smallVideo1") // smallVideo1 is now more recently used than smallVideo2
+	assert.True(t, found)
+	assert.NoError(t, cache.Set("largeVideo1", "Large", "long", nil, longTranscript))
+	assert.LessOrEqual(t, cache.MemoryBytes(), int64(2700))
+
+	// smallVideo2 was the least recently used item and is the only one that had to go
+	cache.mutex.RLock()
+	_, small1InMemory := cache.items["smallVideo1"]
+	_, small2InMemory := cache.items["smallVideo2"]
+	_, largeInMemory := cache.items["largeVideo1"]
+	cache.mutex.RUnlock()
+	assert.True(t, small1InMemory)
+	assert.False(t, small2InMemory)
+	assert.True(t, largeInMemory)
+
+	// Evicted items stay on disk and are reloaded on access
+	item, found := cache.Get("smallVideo2")
+	assert.True(t, found)
+	assert.Equal(t, "Small 2", item.Title)
+
+	// Another large item pushes out everything else; the newest item is always kept in memory
+	assert.NoError(t, cache.Set("largeVideo2", "Large 2", "long", nil, longTranscript))
+	assert.NoError(t, cache.Set("hugeVideo01", "Huge", "long", nil, append(longTranscript, longTranscript...)))
+	cache.mutex.RLock()
+	assert.Len(t, cache.items, 1)
+	_, hugeInMemory := cache.items["hugeVideo01"]
+	cache.mutex.RUnlock()
+	assert.True(t, hugeInMemory)
+
+	item, found = cache.Get("largeVideo1")
+	assert.True(t, found)
+	assert.Len(t, item.Transcript, 40)
+}
+
+func TestCacheNormalizesVideoIDKeys(t *testing.T) {
+	cache, err := NewSummaryCache(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, cache.Set(" dQw4w9WgXcQ ", "Title", "summary", nil, nil))
+	item, found := cache.Get("dQw4w9WgXcQ\t")
+	assert.True(t, found)
+	assert.Equal(t, "dQw4w9WgXcQ", item.VideoID)
+
+	assert.Error(t, cache.Set("not-a-video-id", "Title", "summary", nil, nil))
+	_, found = cache.Get("not-a-video-id")
+	assert.False(t, found)
+}
+
+func TestCacheListByChannel(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"channelVid1", "channelVid2", "channelVid3"} {
+		assert.NoError(t, cache.SetItem(&CacheItem{VideoID: id, Title: id, ChannelID: "UCone", CreatedAt: base.Add(time.Duration(i) * time.Minute)}))
+	}
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "otherVideo1", ChannelID: "UCtwo"}))
+
+	summaries, total := cache.ListByChannel("UCone", 0, 2)
+	assert.Equal(t, 3, total)
+	if assert.Len(t, summaries, 2) {
+		assert.Equal(t, "channelVid3", summaries[0].VideoID) // newest first
+		assert.Equal(t, "channelVid2", summaries[1].VideoID)
+	}
+
+	summaries, _ = cache.ListByChannel("UCone", 2, 2)
+	assert.Len(t, summaries, 1)
+
+	// The index follows deletes and is rebuilt from disk
+	assert.NoError(t, cache.Delete("channelVid1"))
+	reloaded, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	_, total = reloaded.ListByChannel("UCone", 0, 10)
+	assert.Equal(t, 2, total)
+
+	summaries, total = reloaded.ListByChannel("UCunknown", 0, 10)
+	assert.Equal(t, 0, total)
+	assert.NotNil(t, summaries)
+	assert.Empty(t, summaries)
+}
+
+func TestCacheListByCategory(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Category: "tech", CreatedAt: time.Now().Add(-time.Minute)}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb", Category: "tech"}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "ccccccccccc", Category: "cooking"}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "ddddddddddd"}))
+
+	summaries, total := cache.ListByCategory("tech", 0, 10)
+	assert.Equal(t, 2, total)
+	if assert.Len(t, summaries, 2) {
+		assert.Equal(t, "bbbbbbbbbbb", summaries[0].VideoID) // newest first
+		assert.Equal(t, "tech", summaries[0].Category)
+	}
+
+	// Recategorizing moves the item; the index is rebuilt from disk
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Category: "cooking"}))
+	reloaded, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	_, total = reloaded.ListByCategory("tech", 0, 10)
+	assert.Equal(t, 1, total)
+	_, total = reloaded.ListByCategory("cooking", 0, 10)
+	assert.Equal(t, 2, total)
+}
+
+func TestCacheList(t *testing.T) {
+	// A tiny memory budget keeps only the most recent item in memory; the listing covers the disk too
+	cache, err := NewSummaryCacheWithOptions(t.TempDir(), CacheOptions{TTL: time.Hour, MaxMemoryBytes: 1})
+	assert.NoError(t, err)
+	entries, unindexed := cache.List()
+	assert.Empty(t, entries)
+	assert.Zero(t, unindexed)
+
+	created := time.Now().Add(-time.Minute).Truncate(time.Second)
+	assert.NoError(t, cache.SetItem(&CacheItem{
+		VideoID:    "aaaaaaaaaaa",
+		Title:      "Title",
+		Summary:    "요약 summary",
+		Transcript: []services.TranscriptItem{{Text: "hello"}, {Text: "world!"}},
+		CreatedAt:  created,
+	}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Language: "en", CreatedAt: time.Now().Add(-2 * time.Hour)}))
+
+	_, err = cache.Pin("aaaaaaaaaaa")
+	assert.NoError(t, err)
+
+	entries, unindexed = cache.List()
+	assert.Zero(t, unindexed)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	assert.Equal(t, []CacheEntryInfo{
+		{Key: "aaaaaaaaaaa", VideoID: "aaaaaaaaaaa", Title: "Title", CreatedAt: created, TranscriptLength: 11, SummaryLength: 10, Pinned: true},
+		{Key: "aaaaaaaaaaa.en", VideoID: "aaaaaaaaaaa", Language: "en", CreatedAt: entries[1].CreatedAt, Expired: true},
+	}, entries)
+}
+
+func TestCacheKeepsLanguagesSeparate(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "dQw4w9WgXcQ", Summary: "default"}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "dQw4w9WgXcQ", Language: "EN", Summary: "english"}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "dQw4w9WgXcQ", Language: services.DefaultSummaryLanguage, Summary: "default again"}))
+	assert.Error(t, cache.SetItem(&CacheItem{VideoID: "dQw4w9WgXcQ", Language: "../en"}))
+
+	item, found := cache.Get("dQw4w9WgXcQ")
+	assert.True(t, found)
+	assert.Equal(t, "default again", item.Summary)
+
+	item, found = cache.Get(CacheKey("dQw4w9WgXcQ", "en"))
+	assert.True(t, found)
+	assert.Equal(t, "english", item.Summary)
+	assert.Equal(t, "en", item.Language)
+
+	_, found = cache.Get(CacheKey("dQw4w9WgXcQ", "ja"))
+	assert.False(t, found)
+
+	// Both languages survive a reload from disk
+	reloaded, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	item, found = reloaded.Get("dQw4w9WgXcQ.en")
+	assert.True(t, found)
+	assert.Equal(t, "english", item.Summary)
+
+	assert.NoError(t, reloaded.Delete("dQw4w9WgXcQ.en"))
+	_, found = reloaded.Get("dQw4w9WgXcQ.en")
+	assert.False(t, found)
+	_, found = reloaded.Get("dQw4w9WgXcQ")
+	assert.True(t, found)
+}
+
+func TestCacheSweepRemovesExpiredItemsFromMemoryAndDisk(t *testing.T) {
+	dir := t.TempDir()
+	// A tiny memory budget keeps only the most recent item in memory, so the sweep has to check the rest
+	// from the indexes
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{TTL: time.Hour, MaxMemoryBytes: 1})
+	assert.NoError(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", CreatedAt: old}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb"}))
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "ccccccccccc", CreatedAt: old}))
+	// Files are not read by the sweep
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "aaaaaaaaaaa.json"), []byte("{"), 0644))
+
+	assert.Equal(t, 2, cache.Sweep())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "bbbbbbbbbbb.json")}, files)
+	_, found := cache.Get("bbbbbbbbbbb")
+	assert.True(t, found)
+}
+
+func TestCacheWithoutTTLNeverSweeps(t *testing.T) {
+	cache, err := NewSummaryCacheWithOptions(t.TempDir(), CacheOptions{SweepInterval: time.Millisecond})
+	assert.NoError(t, err)
+	defer cache.Close()
+	assert.Nil(t, cache.stopSweep, "no sweep goroutine without a TTL")
+
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", CreatedAt: time.Now().Add(-10000 * time.Hour)}))
+	assert.Equal(t, 0, cache.Sweep())
+	_, found := cache.Get("aaaaaaaaaaa")
+	assert.True(t, found)
+}
+
+func TestCacheBackgroundSweep(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{TTL: time.Hour, SweepInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "aaaaaaaaaaa.json"))
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCacheShardingMigratesFiles(t *testing.T) {
+	dir := t.TempDir()
+	flat, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, flat.Set("abcdEFGH123", "A", "summary", nil, nil))
+	assert.NoError(t, flat.SetItem(&CacheItem{VideoID: "xyzzyXYZZY1", Language: "en", Title: "X"}))
+
+	// Switching sharding on moves the existing files into shard directories
+	sharded, err := NewSummaryCacheWithOptions(dir, CacheOptions{Sharded: true})
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "ab", "abcdEFGH123.json"))
+	assert.FileExists(t, filepath.Join(dir, "xy", "xyzzyXYZZY1.en.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "abcdEFGH123.json"))
+	_, found := sharded.Get("xyzzyXYZZY1.en")
+	assert.True(t, found)
+
+	assert.NoError(t, sharded.Set("bbbbbbbbbbb", "B", "summary", nil, nil))
+	assert.FileExists(t, filepath.Join(dir, "bb", "bbbbbbbbbbb.json"))
+	_, total := sharded.RecentVideoSummariesPage(0, 10)
+	assert.Equal(t, 3, total)
+	assert.NoError(t, sharded.Delete("abcdEFGH123"))
+	assert.NoFileExists(t, filepath.Join(dir, "ab", "abcdEFGH123.json"))
+
+	// Switching it off again moves them back and removes the emptied shard directories
+	flat, err = NewSummaryCache(dir)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "bbbbbbbbbbb.json"))
+	assert.FileExists(t, filepath.Join(dir, "xyzzyXYZZY1.en.json"))
+	assert.NoDirExists(t, filepath.Join(dir, "bb"))
+	_, found = flat.Get("bbbbbbbbbbb")
+	assert.True(t, found)
+}
+
+func TestCacheLazyLoadSweepsWithoutReadingFiles(t *testing.T) {
+	dir := t.TempDir()
+	eager, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, eager.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	assert.NoError(t, eager.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb"}))
+
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{LazyLoad: true, TTL: time.Hour})
+	assert.NoError(t, err)
+	<-cache.indexed
+	for _, key := range []string{"aaaaaaaaaaa", "bbbbbbbbbbb"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, key+".json"), []byte("{"), 0644))
+	}
+
+	assert.Equal(t, 1, cache.Sweep())
+	assert.NoFileExists(t, filepath.Join(dir, "aaaaaaaaaaa.json"))
+	assert.FileExists(t, filepath.Join(dir, "bbbbbbbbbbb.json"))
+	assert.Equal(t, int64(0), cache.MemoryBytes(), "nothing is read into memory")
+}
+
+func TestCacheLazyLoad(t *testing.T) {
+	dir := t.TempDir()
+	eager, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, eager.SetItem(&CacheItem{VideoID: "aaaaaaaaaaa", Title: "A", ChannelID: "UCchannel"}))
+	assert.NoError(t, eager.SetItem(&CacheItem{VideoID: "bbbbbbbbbbb", Title: "B", ChannelID: "UCchannel"}))
+
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{LazyLoad: true})
+	assert.NoError(t, err)
+	<-cache.indexed
+
+	// Nothing is read into memory at startup, but the background indexing covers all items
+	assert.Equal(t, int64(0), cache.MemoryBytes())
+	_, total := cache.ListByChannel("UCchannel", 0, 10)
+	assert.Equal(t, 2, total)
+	entries, unindexed := cache.List()
+	assert.Len(t, entries, 2)
+	assert.Zero(t, unindexed)
+
+	item, found := cache.Get("aaaaaaaaaaa")
+	assert.True(t, found)
+	assert.Equal(t, "A", item.Title)
+	assert.Greater(t, cache.MemoryBytes(), int64(0))
+	_, found = cache.Get("ccccccccccc")
+	assert.False(t, found)
+
+	assert.NoError(t, cache.Delete("bbbbbbbbbbb"))
+	_, found = cache.Get("bbbbbbbbbbb")
+	assert.False(t, found)
+	_, total = cache.ListByChannel("UCchannel", 0, 10)
+	assert.Equal(t, 1, total)
+}
+
+func TestCacheLazyLoadDoesNotEvictUnindexedPinnedItems(t *testing.T) {
+	dir := t.TempDir()
+	eager, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	for _, videoID := range []string{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"} {
+		assert.NoError(t, eager.SetItem(&CacheItem{VideoID: videoID, Summary: videoID}))
+	}
+	_, err = eager.Pin("aaaaaaaaaaa")
+	assert.NoError(t, err)
+
+	// The pinned item was written longest ago, so it is the first eviction candidate
+	for i, videoID := range []string{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"} {
+		modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		assert.NoError(t, os.Chtimes(filepath.Join(dir, videoID+".json"), modTime, modTime))
+	}
+
+	// The lowered cap is applied at startup, before any file has been read
+	cache, err := NewSummaryCacheWithOptions(dir, CacheOptions{LazyLoad: true, MaxEntries: 2})
+	assert.NoError(t, err)
+	<-cache.indexed
+
+	item, found := cache.Get("aaaaaaaaaaa")
+	if assert.True(t, found, "the pinned item is kept") {
+		assert.True(t, item.Pinned)
+	}
+	_, found = cache.Get("bbbbbbbbbbb")
+	assert.False(t, found, "the oldest unpinned item is evicted instead")
+	_, found = cache.Get("ccccccccccc")
+	assert.True(t, found)
+}
+
+func TestCacheInterruptedWriteKeepsOldFile(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Set("aaaaaaaaaaa", "Old", "summary", nil, nil))
+
+	// Encoding fails halfway through the item, after the file would have been truncated by a direct write
+	broken := []services.TranscriptItem{{Text: "line", Start: math.NaN()}}
+	assert.Error(t, cache.Set("aaaaaaaaaaa", "New", "summary", nil, broken))
+	assert.Error(t, cache.Set("bbbbbbbbbbb", "New", "summary", nil, broken))
+	assert.NoFileExists(t, filepath.Join(dir, "bbbbbbbbbbb.json"))
+
+	// A crash during a write leaves only a temporary file, which is removed on startup
+	tmp := filepath.Join(dir, "aaaaaaaaaaa.json.tmp-123")
+	assert.NoError(t, os.WriteFile(tmp, []byte(`{"videoId": "aaaa`), 0644))
+
+	reloaded, err := NewSummaryCache(dir)
+	assert.NoError(t, err)
+	item, found := reloaded.Get("aaaaaaaaaaa")
+	assert.True(t, found)
+	assert.Equal(t, "Old", item.Title)
+	assert.NoFileExists(t, tmp)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "aaaaaaaaaaa.json")}, files)
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// CounterStore keeps named counters and named per-key counters (e.g. per user or per video)
+// in memory and periodically writes them to a JSON file, so they survive restarts.
+// All methods are safe for concurrent use.
+type CounterStore struct {
+	mutex    sync.Mutex
+	path     string
+	counters map[string]int64
+	keyed    map[string]map[string]int64
+	dirty    bool // Changed since the last flush
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// KeyCount is a single entry of a per-key counter
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// counterFile is the on-disk format of a CounterStore
+type counterFile struct {
+	Counters map[string]int64            `json:"counters"`
+	Keyed    map[string]map[string]int64 `json:"keyed"`
+	SavedAt  time.Time                   `json:"savedAt"`
+}
+
+// NewCounterStore loads the counters saved at path, if any, and flushes changes back every
+// flushInterval. A flushInterval of 0 disables periodic flushing; call Flush or Close instead.
+func NewCounterStore(path string, flushInterval time.Duration) (*CounterStore, error) {
+	store := &CounterStore{
+		path:     path,
+		counters: make(map[string]int64),
+		keyed:    make(map[string]map[string]int64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	if flushInterval > 0 {
+		go store.flushLoop(flushInterval)
+	} else {
+		close(store.done)
+	}
+	return store, nil
+}
+
+// load reads the counter file. A missing file starts with empty counters.
+func (s *CounterStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read counter file: %w", err)
+	}
+
+	var file counterFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse counter file %s: %w", s.path, err)
+	}
+	for name, value := range file.Counters {
+		s.counters[name] = value
+	}
+	for name, values := range file.Keyed {
+		s.keyed[name] = values
+	}
+	return nil
+}
+
+// flushLoop writes the counters to disk every interval until Close is called
+func (s *CounterStore) flushLoop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				services.LogError("Failed to flush counters: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Increment adds delta to the named counter and returns the new value
+func (s *CounterStore) Increment(name string, delta int64) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.counters[name] += delta
+	s.dirty = true
+	return s.counters[name]
+}
+
+// Get returns the value of the named counter
+func (s *CounterStore) Get(name string) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.counters[name]
+}
+
+// IncrementKey adds delta to key's entry of the named per-key counter and returns the new value
+func (s *CounterStore) IncrementKey(name, key string, delta int64) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	values, ok := s.keyed[name]
+	if !ok {
+		values = make(map[string]int64)
+		s.keyed[name] = values
+	}
+	values[key] += delta
+	s.dirty = true
+	return values[key]
+}
+
+// GetKey returns key's entry of the named per-key counter
+func (s *CounterStore) GetKey(name, key string) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.keyed[name][key]
+}
+
+// TopN returns the n highest entries of the named per-key counter, highest first.
+// Ties are ordered by key so the result is stable.
+func (s *CounterStore) TopN(name string, n int) []KeyCount {
+	s.mutex.Lock()
+	entries := make([]KeyCount, 0, len(s.keyed[name]))
+	for key, count := range s.keyed[name] {
+		entries = append(entries, KeyCount{Key: key, Count: count})
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	if n >= 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Flush writes the counters to disk if they changed. The file is replaced atomically,
+// so a crash mid-write leaves the previous version intact.
+func (s *CounterStore) Flush() error {
+	s.mutex.Lock()
+	if !s.dirty {
+		s.mutex.Unlock()
+		return nil
+	}
+	file := counterFile{
+		Counters: make(map[string]int64, len(s.counters)),
+		Keyed:    make(map[string]map[string]int64, len(s.keyed)),
+		SavedAt:  time.Now(),
+	}
+	for name, value := range s.counters {
+		file.Counters[name] = value
+	}
+	for name, values := range s.keyed {
+		copied := make(map[string]int64, len(values))
+		for key, value := range values {
+			copied[key] = value
+		}
+		file.Keyed[name] = copied
+	}
+	s.dirty = false
+	s.mutex.Unlock()
+
+	if err := writeFileAtomic(s.path, file); err != nil {
+		s.mutex.Lock()
+		s.dirty = true // Retry on the next flush
+		s.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close stops periodic flushing and writes the counters one last time
+func (s *CounterStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+	return s.Flush()
+}
+
+// writeFileAtomic writes v as JSON to a temporary file next to path and renames it into place
+func writeFileAtomic(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
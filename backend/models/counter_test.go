@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterStoreConcurrentIncrements(t *testing.T) {
+	store, err := NewCounterStore(filepath.Join(t.TempDir(), "counters.json"), 0)
+	assert.NoError(t, err)
+
+	const goroutines, increments = 20, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				store.Increment("summaries_total", 1)
+				store.IncrementKey("popularity", fmt.Sprintf("video%d", g%4), 1)
+				if i%10 == 0 {
+					assert.NoError(t, store.Flush())
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(goroutines*increments), store.Get("summaries_total"))
+	for v := 0; v < 4; v++ {
+		assert.Equal(t, int64(goroutines*increments/4), store.GetKey("popularity", fmt.Sprintf("video%d", v)))
+	}
+}
+
+func TestCounterStoreFlushAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counters.json")
+	store, err := NewCounterStore(path, 0)
+	assert.NoError(t, err)
+
+	store.Increment("summaries_total", 3)
+	store.IncrementKey("popularity", "aaaaaaaaaaa", 5)
+	store.IncrementKey("popularity", "bbbbbbbbbbb", 7)
+	store.IncrementKey("popularity", "ccccccccccc", 5)
+	assert.NoError(t, store.Close())
+
+	reloaded, err := NewCounterStore(path, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), reloaded.Get("summaries_total"))
+	assert.Equal(t, []KeyCount{
+		{Key: "bbbbbbbbbbb", Count: 7},
+		{Key: "aaaaaaaaaaa", Count: 5},
+	}, reloaded.TopN("popularity", 2))
+	assert.Empty(t, reloaded.TopN("unknown", 10))
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// JobJournal persists pending jobs so they survive a restart. Entries are stored as raw JSON keyed
+// by a job ID, leaving the job format to the caller. Every change rewrites the file atomically,
+// which is fine for the small number of jobs a queue holds. All methods are safe for concurrent use.
+type JobJournal struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]json.RawMessage
+}
+
+// OpenJobJournal loads the jobs recorded at path. A missing file starts an empty journal.
+func OpenJobJournal(path string) (*JobJournal, error) {
+	journal := &JobJournal{path: path, entries: make(map[string]json.RawMessage)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job journal: %w", err)
+	}
+	if err := json.Unmarshal(data, &journal.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse job journal %s: %w", path, err)
+	}
+	return journal, nil
+}
+
+// Put records or replaces the job with the given ID
+func (j *JobJournal) Put(id string, job any) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", id, err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.entries[id] = data
+	return writeFileAtomic(j.path, j.entries)
+}
+
+// Remove drops the job with the given ID, if recorded
+func (j *JobJournal) Remove(id string) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, ok := j.entries[id]; !ok {
+		return nil
+	}
+	delete(j.entries, id)
+	return writeFileAtomic(j.path, j.entries)
+}
+
+// Entries returns a copy of all recorded jobs by ID
+func (j *JobJournal) Entries() map[string]json.RawMessage {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	entries := make(map[string]json.RawMessage, len(j.entries))
+	for id, data := range j.entries {
+		entries[id] = data
+	}
+	return entries
+}
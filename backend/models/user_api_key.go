@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	userAPIKeyMutex sync.RWMutex
+	userAPIKeysDir  = filepath.Join("users", "keys")
+)
+
+// SetUserAPIKeyDirectory는 사용자 API 키 저장 디렉토리를 변경합니다 (테스트 및 배포 설정용).
+func SetUserAPIKeyDirectory(dir string) {
+	userAPIKeyMutex.Lock()
+	defer userAPIKeyMutex.Unlock()
+	userAPIKeysDir = dir
+}
+
+// userAPIKeyPath는 사용자 ID에 해당하는 키 파일 경로를 반환합니다.
+func userAPIKeyPath(userID string) (string, error) {
+	if userID == "" || strings.ContainsAny(userID, `/\`) || userID == "." || userID == ".." {
+		return "", errors.New("유효하지 않은 사용자 ID입니다")
+	}
+	return filepath.Join(userAPIKeysDir, userID+".key"), nil
+}
+
+// SaveUserAPIKey는 사용자의 OpenAI API 키를 저장합니다.
+// 키 파일은 소유자만 읽을 수 있도록 0600 권한으로 생성됩니다.
+func SaveUserAPIKey(userID, apiKey string) error {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return errors.New("API 키는 필수입니다")
+	}
+
+	userAPIKeyMutex.Lock()
+	defer userAPIKeyMutex.Unlock()
+
+	path, err := userAPIKeyPath(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(userAPIKeysDir, 0700); err != nil {
+		return fmt.Errorf("API 키 디렉토리 생성 실패: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(apiKey), 0600); err != nil {
+		return fmt.Errorf("API 키 저장 실패: %w", err)
+	}
+	return nil
+}
+
+// GetUserAPIKey는 저장된 사용자의 API 키를 반환합니다. 저장된 키가 없으면 false를 반환합니다.
+func GetUserAPIKey(userID string) (string, bool) {
+	userAPIKeyMutex.RLock()
+	defer userAPIKeyMutex.RUnlock()
+
+	path, err := userAPIKeyPath(userID)
+	if err != nil {
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	apiKey := strings.TrimSpace(string(data))
+	return apiKey, apiKey != ""
+}
+
+// DeleteUserAPIKey는 저장된 사용자의 API 키를 삭제합니다. 저장된 키가 없어도 오류가 아닙니다.
+func DeleteUserAPIKey(userID string) error {
+	userAPIKeyMutex.Lock()
+	defer userAPIKeyMutex.Unlock()
+
+	path, err := userAPIKeyPath(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("API 키 삭제 실패: %w", err)
+	}
+	return nil
+}
@@ -6,8 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/akirose/youtube-summarizer/services"
 )
 
 // UserSummary 구조체는 사용자가 본 비디오 요약의 기록을 나타냅니다.
@@ -42,6 +45,13 @@ func InitUserSummaryDirectory() error {
 	return nil
 }
 
+// SetUserSummaryDirectory는 사용자 요약 파일을 저장할 디렉토리를 변경합니다 (테스트 및 배포 설정용).
+func SetUserSummaryDirectory(dir string) {
+	userSummaryMutex.Lock()
+	defer userSummaryMutex.Unlock()
+	usersDir = dir
+}
+
 // SetMaxUserSummaries는 사용자별 최대 저장 요약 수를 설정합니다.
 func SetMaxUserSummaries(max int) {
 	if max > 0 {
@@ -55,6 +65,10 @@ func AddUserSummary(userID, videoID, videoTitle string) error {
 	if userID == "" || videoID == "" {
 		return fmt.Errorf("사용자 ID와 비디오 ID는 필수입니다")
 	}
+	videoID, err := services.NormalizeVideoID(videoID)
+	if err != nil {
+		return fmt.Errorf("유효하지 않은 비디오 ID입니다: %w", err)
+	}
 
 	userSummaryMutex.Lock()
 	defer userSummaryMutex.Unlock()
@@ -132,8 +146,15 @@ func AddUserSummary(userID, videoID, videoTitle string) error {
 // GetUserSummaries는 사용자의 비디오 요약 기록을 가져옵니다.
 // limit이 0보다 크면 최신 항목 limit개만 반환합니다.
 func GetUserSummaries(userID string, limit int) ([]UserSummary, error) {
+	summaries, _, err := GetUserSummariesPage(userID, 0, limit)
+	return summaries, err
+}
+
+// GetUserSummariesPage는 최신순으로 정렬된 사용자의 요약 기록 중 offset번째부터 limit개를 가져옵니다.
+// limit이 0 이하이면 offset 이후의 모든 항목을 반환하며, 함께 반환되는 total은 전체 항목 수입니다.
+func GetUserSummariesPage(userID string, offset, limit int) ([]UserSummary, int, error) {
 	if userID == "" {
-		return nil, fmt.Errorf("사용자 ID는 필수입니다")
+		return nil, 0, fmt.Errorf("사용자 ID는 필수입니다")
 	}
 
 	userSummaryMutex.RLock()
@@ -144,37 +165,116 @@ func GetUserSummaries(userID string, limit int) ([]UserSummary, error) {
 
 	// 파일이 존재하지 않으면 빈 목록 반환
 	if _, err := os.Stat(userFilePath); os.IsNotExist(err) {
-		return []UserSummary{}, nil
+		return []UserSummary{}, 0, nil
 	}
 
 	// 파일 로드
 	file, err := os.Open(userFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("사용자 요약 파일 열기 실패: %w", err)
+		return nil, 0, fmt.Errorf("사용자 요약 파일 열기 실패: %w", err)
 	}
 	defer file.Close()
 
 	var userSummaries UserSummaries
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&userSummaries); err != nil {
-		return nil, fmt.Errorf("사용자 요약 파일 디코딩 실패: %w", err)
+		return nil, 0, fmt.Errorf("사용자 요약 파일 디코딩 실패: %w", err)
 	}
 
 	// 요약 목록 시간 순으로 정렬 (최신 항목이 먼저 오도록)
-	sort.Slice(userSummaries.Summaries, func(i, j int) bool {
-		return userSummaries.Summaries[i].ViewedAt.After(userSummaries.Summaries[j].ViewedAt)
+	summaries := userSummaries.Summaries
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ViewedAt.After(summaries[j].ViewedAt)
 	})
 
-	// limit이 0보다 크면 최신 항목 limit개만 반환
-	if limit > 0 && limit < len(userSummaries.Summaries) {
-		return userSummaries.Summaries[:limit], nil
+	// offset부터 limit개만 반환
+	total := len(summaries)
+	if offset >= total {
+		return []UserSummary{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return summaries[offset:end], total, nil
+}
+
+// SearchUserSummaries는 사용자의 요약 기록에서 query를 대소문자 구분 없이 포함하는 항목을 찾습니다.
+// cache가 nil이 아니면 캐시된 요약 본문도 검색하며, 제목이 일치하는 항목을 본문만 일치하는 항목보다
+// 먼저 반환합니다. 각 그룹 안에서는 최근에 본 항목이 먼저 옵니다.
+func SearchUserSummaries(userID, query string, cache *SummaryCache) ([]UserSummary, error) {
+	summaries, err := GetUserSummaries(userID, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	return userSummaries.Summaries, nil
+	query = strings.ToLower(strings.TrimSpace(query))
+	titleMatches := []UserSummary{}
+	var bodyMatches []UserSummary
+	for _, summary := range summaries {
+		if strings.Contains(strings.ToLower(summary.VideoTitle), query) {
+			titleMatches = append(titleMatches, summary)
+			continue
+		}
+		if cache == nil {
+			continue
+		}
+		if item, found := cache.Get(summary.VideoID); found && strings.Contains(strings.ToLower(item.Summary), query) {
+			bodyMatches = append(bodyMatches, summary)
+		}
+	}
+	return append(titleMatches, bodyMatches...), nil
 }
 
-// GetRecentUserSummaries는 사용자의 최근 15개 요약을 가져옵니다.
-func GetRecentUserSummaries(userID string) ([]UserSummary, error) {
-	// 최근 15개 요약 가져오기
-	return GetUserSummaries(userID, 15)
+// RemoveUserSummary는 사용자의 요약 기록에서 비디오를 삭제합니다.
+// 기록에 해당 비디오가 있어 삭제했으면 true를 반환합니다.
+func RemoveUserSummary(userID, videoID string) (bool, error) {
+	if userID == "" || videoID == "" {
+		return false, fmt.Errorf("사용자 ID와 비디오 ID는 필수입니다")
+	}
+	videoID, err := services.NormalizeVideoID(videoID)
+	if err != nil {
+		return false, fmt.Errorf("유효하지 않은 비디오 ID입니다: %w", err)
+	}
+
+	userSummaryMutex.Lock()
+	defer userSummaryMutex.Unlock()
+
+	// 사용자 요약 파일 경로
+	userFilePath := filepath.Join(usersDir, userID+".json")
+
+	data, err := os.ReadFile(userFilePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("사용자 요약 파일 열기 실패: %w", err)
+	}
+
+	var userSummaries UserSummaries
+	if err := json.Unmarshal(data, &userSummaries); err != nil {
+		return false, fmt.Errorf("사용자 요약 파일 디코딩 실패: %w", err)
+	}
+
+	remaining := []UserSummary{}
+	for _, summary := range userSummaries.Summaries {
+		if summary.VideoID != videoID {
+			remaining = append(remaining, summary)
+		}
+	}
+	if len(remaining) == len(userSummaries.Summaries) {
+		return false, nil
+	}
+	userSummaries.Summaries = remaining
+	userSummaries.UpdatedAt = time.Now()
+
+	// 파일 저장
+	data, err = json.MarshalIndent(userSummaries, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("사용자 요약 파일 인코딩 실패: %w", err)
+	}
+	if err := os.WriteFile(userFilePath, data, 0644); err != nil {
+		return false, fmt.Errorf("사용자 요약 파일 생성 실패: %w", err)
+	}
+	return true, nil
 }
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveUserSummary(t *testing.T) {
+	SetUserSummaryDirectory(t.TempDir())
+	t.Cleanup(func() { SetUserSummaryDirectory("users") })
+
+	assert.NoError(t, AddUserSummary("user1", "dQw4w9WgXcQ", "Kept"))
+	assert.NoError(t, AddUserSummary("user1", "aaaaaaaaaaa", "Removed"))
+
+	removed, err := RemoveUserSummary("user1", "aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	summaries, err := GetUserSummaries("user1", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) {
+		assert.Equal(t, "dQw4w9WgXcQ", summaries[0].VideoID)
+	}
+
+	removed, err = RemoveUserSummary("user1", "aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.False(t, removed, "a video that is not in the history is not removed")
+
+	removed, err = RemoveUserSummary("user2", "aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.False(t, removed, "users without a history file have nothing to remove")
+}
+
+func TestGetUserSummariesPage(t *testing.T) {
+	dir := t.TempDir()
+	SetUserSummaryDirectory(dir)
+	t.Cleanup(func() { SetUserSummaryDirectory("users") })
+
+	// 25 summaries viewed one minute apart, stored oldest first
+	history := UserSummaries{UserID: "user1"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 25; i++ {
+		history.Summaries = append(history.Summaries, UserSummary{
+			VideoID:    fmt.Sprintf("video%06d", i),
+			VideoTitle: fmt.Sprintf("Video %d", i),
+			ViewedAt:   start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	data, err := json.Marshal(history)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "user1.json"), data, 0644))
+
+	page, total, err := GetUserSummariesPage("user1", 10, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, total)
+	if assert.Len(t, page, 10) {
+		assert.Equal(t, "Video 14", page[0].VideoTitle, "newest first, after skipping the 10 newest")
+		assert.Equal(t, "Video 5", page[9].VideoTitle)
+	}
+
+	page, total, err = GetUserSummariesPage("user1", 20, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, total)
+	assert.Len(t, page, 5)
+
+	page, total, err = GetUserSummariesPage("user1", 30, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, total)
+	assert.Empty(t, page)
+	assert.NotNil(t, page, "an empty page encodes as [] rather than null")
+
+	page, total, err = GetUserSummariesPage("nobody", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, page)
+}
+
+func TestSearchUserSummaries(t *testing.T) {
+	SetUserSummaryDirectory(t.TempDir())
+	t.Cleanup(func() { SetUserSummaryDirectory("users") })
+	cache, err := NewSummaryCache(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, AddUserSummary("user1", "aaaaaaaaaaa", "Cooking pasta at home"))
+	assert.NoError(t, cache.Set("aaaaaaaaaaa", "Cooking pasta at home", "[00:00] Boil water.", nil, nil))
+	assert.NoError(t, AddUserSummary("user1", "bbbbbbbbbbb", "Travel vlog"))
+	assert.NoError(t, cache.Set("bbbbbbbbbbb", "Travel vlog", "[00:10] We ate PASTA in Rome.", nil, nil))
+	assert.NoError(t, AddUserSummary("user1", "ccccccccccc", "Guitar lesson"))
+
+	// Titles only unless a cache is given
+	results, err := SearchUserSummaries("user1", "PASTA", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "aaaaaaaaaaa", results[0].VideoID)
+	}
+
+	// Body matches follow title matches, even if viewed more recently
+	results, err = SearchUserSummaries("user1", " pasta ", cache)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "aaaaaaaaaaa", results[0].VideoID)
+		assert.Equal(t, "bbbbbbbbbbb", results[1].VideoID)
+	}
+
+	results, err = SearchUserSummaries("user1", "drums", cache)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	assert.NotNil(t, results)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/akirose/youtube-summarizer/models"
+	"github.com/akirose/youtube-summarizer/services"
+)
+
+// defaultSelfTestVideoID is a short, long-lived public video ("Me at the zoo") used for the yt-dlp checks
+const defaultSelfTestVideoID = "jNQXAC9IVRw"
+
+// selfTestCheck is a single named step of the smoke test
+type selfTestCheck struct {
+	name string
+	run  func() error
+}
+
+// runSelfTest exercises yt-dlp, OpenAI and the cache end-to-end without starting the server.
+// Each check prints a pass/fail line; the return value is false if any check failed.
+func runSelfTest() bool {
+	videoID := os.Getenv("SELFTEST_VIDEO_ID")
+	if videoID == "" {
+		videoID = defaultSelfTestVideoID
+	}
+
+	var transcript []services.TranscriptItem
+
+	checks := []selfTestCheck{
+		{"yt-dlp video info", func() error {
+			info, err := services.GetVideoInfo(context.Background(), videoID)
+			if err != nil {
+				return err
+			}
+			if info.Title == "" {
+				return errors.New("video info has no title")
+			}
+			return nil
+		}},
+		{"yt-dlp transcript", func() error {
+			chunks, err := services.GetTranscript(context.Background(), videoID, 0)
+			if err != nil {
+				return err
+			}
+			if len(chunks) == 0 || len(chunks[0]) == 0 {
+				return errors.New("transcript is empty")
+			}
+			transcript = chunks[0]
+			return nil
+		}},
+		{"OpenAI summarization", func() error {
+			text := services.GetFormattedTranscript(transcript)
+			if text == "" {
+				// Keep the OpenAI check independent of the transcript check
+				text = "[00:00] Hello, this is a connectivity test."
+			}
+			summary, _, err := services.SummarizeTranscript(context.Background(), &services.GPTRequest{}, text, os.Getenv("OPENAI_API_KEY"), "")
+			if err != nil {
+				return err
+			}
+			if summary == "" {
+				return errors.New("empty summary returned")
+			}
+			return nil
+		}},
+		{"cache read/write", func() error {
+			dir, err := os.MkdirTemp("", "selftest-cache-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+
+			cache, err := models.NewSummaryCache(dir)
+			if err != nil {
+				return err
+			}
+			if err := cache.Set(videoID, "selftest", "selftest summary", nil, nil); err != nil {
+				return err
+			}
+
+			// Reload from disk to make sure the entry was actually persisted
+			reloaded, err := models.NewSummaryCache(dir)
+			if err != nil {
+				return err
+			}
+			item, found := reloaded.Get(videoID)
+			if !found || item.Summary != "selftest summary" {
+				return errors.New("cached entry could not be read back")
+			}
+			return nil
+		}},
+	}
+
+	passed := true
+	for _, check := range checks {
+		start := time.Now()
+		err := check.run()
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			passed = false
+			fmt.Printf("[FAIL] %s (%s): %v\n", check.name, elapsed, err)
+			continue
+		}
+		fmt.Printf("[PASS] %s (%s)\n", check.name, elapsed)
+	}
+
+	if passed {
+		fmt.Println("Self-test passed")
+	} else {
+		fmt.Println("Self-test failed")
+	}
+	return passed
+}
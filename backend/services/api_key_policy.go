@@ -1,9 +1,12 @@
 package services
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // API 키 정책 상수
@@ -20,7 +23,33 @@ type APIKeyPolicy struct {
 	Policy string
 	// 지정된 사용자 ID 목록 (PolicyDesignatedUsers인 경우 사용)
 	DesignatedUsers map[string]bool
-	mu              sync.RWMutex
+	// 사용자별 서버 키 사용량 한도 (nil이면 무제한)
+	quota ServerKeyQuota
+	mu    sync.RWMutex
+}
+
+// ServerKeyQuota는 사용자별 서버 키 사용량 한도를 확인하는 인터페이스
+type ServerKeyQuota interface {
+	// Status returns the user's server key usage in the current quota window
+	Status(userID string) QuotaStatus
+}
+
+// QuotaStatus는 현재 기간의 서버 키 사용량과 남은 한도 (한도 0은 무제한, 남은 양은 nil)
+type QuotaStatus struct {
+	Window              string    `json:"window"` // "day" 또는 "month"
+	SummaryLimit        int64     `json:"summaryLimit,omitempty"`
+	SummariesUsed       int64     `json:"summariesUsed"`
+	SummariesInProgress int64     `json:"summariesInProgress,omitempty"` // 요청되어 아직 끝나지 않은 요약 (한도에서 미리 차감)
+	SummariesRemaining  *int64    `json:"summariesRemaining,omitempty"`
+	TokenLimit          int64     `json:"tokenLimit,omitempty"`
+	TokensUsed          int64     `json:"tokensUsed"`
+	TokensRemaining     *int64    `json:"tokensRemaining,omitempty"`
+	ResetsAt            time.Time `json:"resetsAt"`
+}
+
+// Exhausted reports whether any limit of the window is used up
+func (s QuotaStatus) Exhausted() bool {
+	return (s.SummariesRemaining != nil && *s.SummariesRemaining <= 0) || (s.TokensRemaining != nil && *s.TokensRemaining <= 0)
 }
 
 var (
@@ -64,8 +93,19 @@ func GetAPIKeyPolicy() *APIKeyPolicy {
 	return globalPolicy
 }
 
-// CanUseServerKey checks if a user can use the server's OpenAI API key
+// CanUseServerKey checks if a user can use the server's OpenAI API key for a new summary:
+// the policy must allow it and the user's server key quota must not be used up
 func (p *APIKeyPolicy) CanUseServerKey(userID string) bool {
+	if !p.allowsServerKey(userID) {
+		return false
+	}
+	status, ok := p.QuotaStatus(userID)
+	return !ok || !status.Exhausted()
+}
+
+// allowsServerKey checks the policy only. Requests of a summary that was already admitted use it,
+// so a quota used up halfway through doesn't break the summary.
+func (p *APIKeyPolicy) allowsServerKey(userID string) bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -77,6 +117,24 @@ func (p *APIKeyPolicy) CanUseServerKey(userID string) bool {
 	return p.DesignatedUsers[userID]
 }
 
+// SetServerKeyQuota limits users' server key usage. nil removes the limit.
+func (p *APIKeyPolicy) SetServerKeyQuota(quota ServerKeyQuota) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.quota = quota
+}
+
+// QuotaStatus returns the user's server key usage, or false if no quota is configured
+func (p *APIKeyPolicy) QuotaStatus(userID string) (QuotaStatus, bool) {
+	p.mu.RLock()
+	quota := p.quota
+	p.mu.RUnlock()
+	if quota == nil {
+		return QuotaStatus{}, false
+	}
+	return quota.Status(userID), true
+}
+
 // UpdateDesignatedUsers updates the list of designated users
 func (p *APIKeyPolicy) UpdateDesignatedUsers(userIDs []string) {
 	p.mu.Lock()
@@ -87,10 +145,37 @@ func (p *APIKeyPolicy) UpdateDesignatedUsers(userIDs []string) {
 
 	// 새 목록 설정
 	for _, userID := range userIDs {
-		p.DesignatedUsers[strings.TrimSpace(userID)] = true
+		if userID = strings.TrimSpace(userID); userID != "" {
+			p.DesignatedUsers[userID] = true
+		}
 	}
 }
 
+// DesignatedUserIDs returns the designated user IDs, sorted
+func (p *APIKeyPolicy) DesignatedUserIDs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	userIDs := make([]string, 0, len(p.DesignatedUsers))
+	for userID := range p.DesignatedUsers {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+// SetPolicy switches the policy to PolicyAllUsers or PolicyDesignatedUsers
+func (p *APIKeyPolicy) SetPolicy(policy string) error {
+	if policy != PolicyAllUsers && policy != PolicyDesignatedUsers {
+		return fmt.Errorf("policy must be %q or %q", PolicyAllUsers, PolicyDesignatedUsers)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Policy = policy
+	return nil
+}
+
 // GetApiKeyPolicy returns the current policy as a string
 func (p *APIKeyPolicy) GetApiKeyPolicy() string {
 	p.mu.RLock()
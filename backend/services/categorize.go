@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultSummaryCategories is used when SUMMARY_CATEGORIES is not set
+var defaultSummaryCategories = []string{"tech", "science", "education", "news", "business", "cooking", "music", "gaming", "sports", "entertainment", "other"}
+
+// SummaryCategories returns the categories videos are classified into, configured via
+// SUMMARY_CATEGORIES as a comma separated list. Categories are lower-cased.
+func SummaryCategories() []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, category := range strings.Split(os.Getenv("SUMMARY_CATEGORIES"), ",") {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category != "" && !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		return defaultSummaryCategories
+	}
+	return categories
+}
+
+// categorizationPrompt asks the model for exactly one of the categories
+func categorizationPrompt(categories []string) string {
+	return "You classify YouTube videos by topic. The transcript below is the video's title and summary. " +
+		"Answer with exactly one of these categories and nothing else: " + strings.Join(categories, ", ") + "."
+}
+
+// CategorizeSummary classifies a summarized video into one of SummaryCategories using the summary
+// instead of the transcript, which keeps the extra request small. It returns the category and the
+// tokens the request used. Answers outside the list fall back to "other" if that is a category,
+// otherwise an error is returned.
+func CategorizeSummary(ctx context.Context, title, summary, userAPIKey, userID string) (string, TokenUsage, error) {
+	categories := SummaryCategories()
+	request := &GPTRequest{systemPrompt: categorizationPrompt(categories)}
+	answer, _, err := SummarizeTranscript(ctx, request, fmt.Sprintf("Title: %s\nSummary:\n%s", title, summary), userAPIKey, userID)
+	if err != nil {
+		return "", request.usage, fmt.Errorf("failed to categorize summary: %w", err)
+	}
+
+	answer = strings.ToLower(strings.Trim(strings.TrimSpace(removeThinkTags(answer)), ".\"'`*"))
+	for _, category := range categories {
+		if answer == category {
+			return category, request.usage, nil
+		}
+	}
+	for _, category := range categories {
+		if category == "other" {
+			return category, request.usage, nil
+		}
+	}
+	return "", request.usage, fmt.Errorf("model answered with unknown category %q", answer)
+}
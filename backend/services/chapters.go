@@ -0,0 +1,115 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// Chapter is a creator-defined section of a video, as reported by yt-dlp
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"` // Seconds
+	End   float64 `json:"end"`   // Seconds
+}
+
+// parseChapters converts yt-dlp's "chapters" list into chapters sorted by start time.
+// Entries without a title are skipped.
+func parseChapters(data interface{}) []Chapter {
+	list, ok := data.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var chapters []Chapter
+	for _, entry := range list {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		title, _ := fields["title"].(string)
+		if title = strings.TrimSpace(title); title == "" {
+			continue
+		}
+		start, _ := fields["start_time"].(float64)
+		end, _ := fields["end_time"].(float64)
+		chapters = append(chapters, Chapter{Title: title, Start: start, End: end})
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+	return chapters
+}
+
+// chapterIndex returns the index of the chapter a point in time falls into, or -1 without chapters.
+// Times before the first chapter count as part of it.
+func chapterIndex(chapters []Chapter, seconds float64) int {
+	if len(chapters) == 0 {
+		return -1
+	}
+	i := sort.Search(len(chapters), func(i int) bool { return chapters[i].Start > seconds })
+	return max(i-1, 0)
+}
+
+// ChunkTranscriptByChapters splits transcript chunks again along chapter boundaries, so that no chunk
+// spans two chapters. Chapters longer than chunkSize seconds are split into several chunks like
+// time-based chunks are. Without chapters the chunks are returned unchanged.
+func ChunkTranscriptByChapters(chunks [][]TranscriptItem, chapters []Chapter, chunkSize float64) [][]TranscriptItem {
+	if len(chapters) == 0 {
+		return chunks
+	}
+
+	overlap := transcriptChunkOverlap(chunkSize)
+	var result [][]TranscriptItem
+	var section []TranscriptItem
+	current := -1
+	for _, item := range MergeTranscriptChunks(chunks) {
+		if i := chapterIndex(chapters, item.Start); i != current {
+			if len(section) > 0 {
+				result = append(result, chunkTranscriptItems(section, chunkSize, overlap)...)
+			}
+			section, current = nil, i
+		}
+		section = append(section, item)
+	}
+	if len(section) > 0 {
+		result = append(result, chunkTranscriptItems(section, chunkSize, overlap)...)
+	}
+	return result
+}
+
+// chunkChapterIndex returns the index of the chapter chunk i starts in, or -1 without chapters
+func chunkChapterIndex(chunks [][]TranscriptItem, i int, chapters []Chapter) int {
+	if len(chunks[i]) == 0 {
+		return -1
+	}
+	start, _ := chunkTimeRange(chunks[i])
+	return chapterIndex(chapters, start)
+}
+
+// chunkChapter returns the title of the chapter chunk i starts in, or "" without chapters
+func chunkChapter(chunks [][]TranscriptItem, i int, chapters []Chapter) string {
+	if index := chunkChapterIndex(chunks, i, chapters); index >= 0 {
+		return chapters[index].Title
+	}
+	return ""
+}
+
+// chunkTranscript formats chunk i for the model, headed by the title of its chapter if there are chapters
+func chunkTranscript(chunks [][]TranscriptItem, i int, chapters []Chapter) string {
+	transcript := GetFormattedTranscript(chunks[i])
+	if title := chunkChapter(chunks, i, chapters); title != "" {
+		return "Chapter: " + title + "\n\n" + transcript
+	}
+	return transcript
+}
+
+// withChapterHeading puts the chapter title above the summary of a chunk that starts a new chapter.
+// Further chunks of a long chapter are not headed again.
+func withChapterHeading(summary string, chunks [][]TranscriptItem, i int, chapters []Chapter) string {
+	index := chunkChapterIndex(chunks, i, chapters)
+	if index < 0 || (i > 0 && chunkChapterIndex(chunks, i-1, chapters) == index) {
+		return summary
+	}
+	return "## " + chapters[index].Title + "\n\n" + strings.TrimSpace(summary)
+}
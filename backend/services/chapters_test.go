@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVideoInfoParsesChapters(t *testing.T) {
+	stubRunCommand(t, `{
+		"title": "Video",
+		"chapters": [
+			{"start_time": 95.5, "end_time": 300, "title": "Main part"},
+			{"start_time": 0, "end_time": 95.5, "title": "Intro"},
+			{"start_time": 300, "end_time": 310, "title": " "}
+		]
+	}`)
+
+	info, err := GetVideoInfo(context.Background(), "eeeeeeeeeee")
+	assert.NoError(t, err)
+	assert.Equal(t, []Chapter{
+		{Title: "Intro", Start: 0, End: 95.5},
+		{Title: "Main part", Start: 95.5, End: 300},
+	}, info.Chapters)
+
+	stubRunCommand(t, `{"title": "Video", "chapters": null}`)
+	info, err = GetVideoInfo(context.Background(), "fffffffffff")
+	assert.NoError(t, err)
+	assert.Empty(t, info.Chapters)
+}
+
+func TestChunkTranscriptByChapters(t *testing.T) {
+	t.Setenv("TRANSCRIPT_CHUNK_OVERLAP_SECONDS", "")
+	items := []TranscriptItem{
+		{Text: "a", Start: 0}, {Text: "b", Start: 50}, {Text: "c", Start: 100},
+		{Text: "d", Start: 150}, {Text: "e", Start: 300}, {Text: "f", Start: 650},
+	}
+	timeChunks := chunkTranscriptItems(items, 400, 0)
+	chapters := []Chapter{{Title: "Intro", Start: 10}, {Title: "Talk", Start: 120}}
+
+	chunks := ChunkTranscriptByChapters(timeChunks, chapters, 400)
+	assert.Equal(t, [][]TranscriptItem{
+		{{Text: "a", Start: 0}, {Text: "b", Start: 50}, {Text: "c", Start: 100}}, // Before the first chapter counts as part of it
+		{{Text: "d", Start: 150}, {Text: "e", Start: 300}},
+		{{Text: "f", Start: 650}}, // Long chapters are still split by time
+	}, chunks)
+	assert.Equal(t, items, MergeTranscriptChunks(chunks))
+
+	assert.Equal(t, timeChunks, ChunkTranscriptByChapters(timeChunks, nil, 400), "falls back to time-based chunks")
+
+	assert.Equal(t, "Intro", chunkChapter(chunks, 0, chapters))
+	assert.Equal(t, "Talk", chunkChapter(chunks, 2, chapters))
+	assert.Equal(t, "", chunkChapter(chunks, 0, nil))
+}
+
+func TestSummarizeChunksHeadsChapters(t *testing.T) {
+	for _, concurrency := range []string{"1", "3"} {
+		t.Run("concurrency "+concurrency, func(t *testing.T) {
+			server := newChunkEchoServer(t)
+			t.Setenv("OPENAI_API_URL", server.URL)
+			t.Setenv("OPENAI_CHUNK_CONCURRENCY", concurrency)
+
+			chunks := [][]TranscriptItem{
+				{{Text: "first", Start: 0, Duration: 5}},
+				{{Text: "second", Start: 400, Duration: 5}},
+				{{Text: "third", Start: 800, Duration: 5}},
+			}
+			chapters := []Chapter{{Title: "Intro", Start: 0}, {Title: "Q&A", Start: 800}}
+			result, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{Chapters: chapters}, "sk-test", "user")
+			assert.NoError(t, err)
+			if assert.Len(t, result.Chunks, 3) {
+				// The echoed transcript shows the chapter was sent to the model
+				assert.True(t, strings.HasPrefix(result.Chunks[0].Text, "## Intro\n\n"), result.Chunks[0].Text)
+				assert.Contains(t, result.Chunks[0].Text, "Chapter: Intro\n\n[00:00] first")
+				assert.NotContains(t, result.Chunks[1].Text, "## Intro", "a chapter is headed once")
+				assert.Contains(t, result.Chunks[1].Text, "Chapter: Intro\n\n[06:40] second")
+				assert.True(t, strings.HasPrefix(result.Chunks[2].Text, "## Q&A\n\n"), result.Chunks[2].Text)
+				assert.Contains(t, result.Summary, "## Q&A")
+				assert.Equal(t, "Intro", result.Chunks[1].Chapter)
+				assert.Equal(t, "Q&A", result.Chunks[2].Chapter)
+			}
+		})
+	}
+}
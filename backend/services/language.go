@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultSummaryLanguage is the language summaries are written in unless another one is requested
+const DefaultSummaryLanguage = "ko"
+
+// languageCodePattern matches BCP 47 style language codes such as "en", "pt-br" or "zh-hant"
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// languageNames maps language codes to the names used in the summarization prompt
+var languageNames = map[string]string{
+	"ko":      "Korean",
+	"en":      "English",
+	"ja":      "Japanese",
+	"zh":      "Chinese",
+	"zh-hans": "Simplified Chinese",
+	"zh-hant": "Traditional Chinese",
+	"es":      "Spanish",
+	"fr":      "French",
+	"de":      "German",
+	"pt":      "Portuguese",
+	"it":      "Italian",
+	"ru":      "Russian",
+	"vi":      "Vietnamese",
+	"th":      "Thai",
+	"id":      "Indonesian",
+}
+
+// NormalizeLanguage lowercases and validates a language code. An empty code means DefaultSummaryLanguage.
+func NormalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return DefaultSummaryLanguage, nil
+	}
+	if !languageCodePattern.MatchString(language) {
+		return "", fmt.Errorf("invalid language code %q", language)
+	}
+	return language, nil
+}
+
+// LanguageName returns the English name of a language code for use in prompts.
+// Codes without a known name are described by the code itself.
+func LanguageName(language string) string {
+	if name, ok := languageNames[language]; ok {
+		return name
+	}
+	if base, _, found := strings.Cut(language, "-"); found {
+		if name, ok := languageNames[base]; ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("the language with code %q", language)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// LogLevel is the minimum severity written to the log
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// currentLogLevel is set from LOG_LEVEL by ConfigureLogLevel
+var currentLogLevel = LevelInfo
+
+// ParseLogLevel converts a LOG_LEVEL value (debug, info, warn, error) to a LogLevel.
+func ParseLogLevel(value string) (LogLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return LevelDebug, true
+	case "info", "":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error":
+		return LevelError, true
+	default:
+		return LevelInfo, false
+	}
+}
+
+// ConfigureLogLevel reads LOG_LEVEL from the environment. Unknown values fall back to info.
+func ConfigureLogLevel() {
+	value := os.Getenv("LOG_LEVEL")
+	level, ok := ParseLogLevel(value)
+	currentLogLevel = level
+	if !ok {
+		LogWarn("Unknown LOG_LEVEL '%s'. Defaulting to info.", value)
+	}
+}
+
+// logAt writes the message with its level prefix if the level is enabled.
+func logAt(level LogLevel, prefix, format string, args ...interface{}) {
+	if level < currentLogLevel {
+		return
+	}
+	log.Print(prefix + fmt.Sprintf(format, args...))
+}
+
+// LogDebug, LogInfo, LogWarn and LogError write a message at their level; lower levels than LOG_LEVEL are dropped
+func LogDebug(format string, args ...interface{}) { logAt(LevelDebug, "Debug: ", format, args...) }
+func LogInfo(format string, args ...interface{})  { logAt(LevelInfo, "Info: ", format, args...) }
+func LogWarn(format string, args ...interface{})  { logAt(LevelWarn, "Warning: ", format, args...) }
+func LogError(format string, args ...interface{}) { logAt(LevelError, "Error: ", format, args...) }
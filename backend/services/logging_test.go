@@ -0,0 +1,42 @@
+package services
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelSuppressesLowerLevels(t *testing.T) {
+	var out bytes.Buffer
+	originalOutput, originalLevel := log.Writer(), currentLogLevel
+	log.SetOutput(&out)
+	t.Cleanup(func() {
+		log.SetOutput(originalOutput)
+		currentLogLevel = originalLevel
+	})
+
+	t.Setenv("LOG_LEVEL", "warn")
+	ConfigureLogLevel()
+	LogDebug("debug message")
+	LogInfo("info message")
+	LogWarn("warn message")
+	LogError("error message")
+
+	assert.NotContains(t, out.String(), "debug message")
+	assert.NotContains(t, out.String(), "info message")
+	assert.Contains(t, out.String(), "Warning: warn message")
+	assert.Contains(t, out.String(), "Error: error message")
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for value, want := range map[string]LogLevel{"debug": LevelDebug, "": LevelInfo, "WARNING": LevelWarn, " error ": LevelError} {
+		level, ok := ParseLogLevel(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, level, value)
+	}
+	level, ok := ParseLogLevel("verbose")
+	assert.False(t, ok)
+	assert.Equal(t, LevelInfo, level)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -23,6 +28,12 @@ const (
 	// Maximum number of tokens to generate
 	MaxTokens = 1500
 
+	// Sampling temperature used unless a request chooses another one
+	DefaultTemperature = 0.2
+
+	// Highest sampling temperature the API accepts
+	MaxTemperature = 2.0
+
 	// System prompt template for summarization
 	SummarizationPrompt = `# YouTube Video Summary Expert
 
@@ -80,6 +91,23 @@ type TimestampInfo struct {
 	Text string `json:"text"` // The text associated with this timestamp
 }
 
+// ChunkSummary holds the summary generated for a single transcript chunk and the time range it covers
+type ChunkSummary struct {
+	StartSec float64 `json:"startSec"`
+	EndSec   float64 `json:"endSec"`
+	Text     string  `json:"text"`
+	Chapter  string  `json:"chapter,omitempty"` // Title of the video chapter the chunk starts in (USE_VIDEO_CHAPTERS)
+}
+
+// ChunkedSummary is the combined result of summarizing every transcript chunk of a video
+type ChunkedSummary struct {
+	Summary    string         // Chunk summaries joined in order, as returned by SummarizeChunks
+	RawSummary string         // Model output before any cleanup (e.g. <think> removal), for debugging
+	Chunks     []ChunkSummary // Per-chunk summaries, kept to trace a section back to its source chunk
+	Model      string         // Model that generated the summary
+	Usage      TokenUsage     // Tokens billed for all chunks summarized in this call
+}
+
 // GPTMessage represents a message in the GPT API request
 type GPTMessage struct {
 	Role    string `json:"role"`
@@ -90,47 +118,142 @@ type GPTMessage struct {
 type GPTRequest struct {
 	Model       string       `json:"model"`
 	Messages    []GPTMessage `json:"messages"`
-	MaxTokens   int          `json:"max_tokens"`
+	MaxTokens   int          `json:"max_tokens,omitempty"`
 	Temperature float64      `json:"temperature"`
+
+	// MaxCompletionTokens replaces MaxTokens for providers that require it (OPENAI_API_FLAVOR)
+	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
+
+	// Stream asks for the completion as server-sent events (OPENAI_STREAM)
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	systemPrompt string     // System prompt sent with each chunk; SummarizationPrompt if empty
+	usage        TokenUsage // Tokens billed for the completions requested with this request so far
+	model        string     // Model chosen for this request; OPENAI_API_MODEL or Model if empty
+	temperature  *float64   // Temperature chosen for this request; DefaultTemperature if nil
+}
+
+// SummarizeOptions selects how a summary is generated. The zero value uses the defaults.
+type SummarizeOptions struct {
+	Language string // Output language code (e.g. "en"); empty means DefaultSummaryLanguage
+	Style    string // Summary style (e.g. StyleBullets); empty means DefaultSummaryStyle
+
+	// Model replaces the configured model, e.g. when the user chose one for their own API key
+	Model string
+	// Temperature replaces DefaultTemperature if set (0 to MaxTemperature)
+	Temperature *float64
+	// Chapters, if set, are named to the model with each chunk and head the summary of each chapter.
+	// The chunks should not span chapters; see ChunkTranscriptByChapters.
+	Chapters []Chapter
+
+	// OnChunk, if set, is called in chunk order as soon as each chunk is summarized
+	OnChunk func(ChunkProgress)
+}
+
+// summaryModel returns the model a summary is generated with: the requested one, else OPENAI_API_MODEL, else Model
+func summaryModel(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if model := os.Getenv("OPENAI_API_MODEL"); model != "" {
+		return model
+	}
+	return Model
+}
+
+// openAIMaxTokens returns the completion token limit configured via OPENAI_API_MAX_TOKENS
+func openAIMaxTokens() int {
+	maxTokens, err := strconv.Atoi(os.Getenv("OPENAI_API_MAX_TOKENS"))
+	if err != nil {
+		// 설정되지 않았거나 변환 실패 시 기본값 사용
+		return MaxTokens
+	}
+	return maxTokens
+}
+
+// newSummaryRequest returns the request a chunked summary is generated with
+func newSummaryRequest(opts SummarizeOptions) *GPTRequest {
+	return &GPTRequest{
+		systemPrompt: summarizationPrompt(opts.Language, opts.Style),
+		model:        opts.Model,
+		temperature:  opts.Temperature,
+	}
+}
+
+// ChunkProgress reports a chunk summary while the rest of the video is still being summarized
+type ChunkProgress struct {
+	Chunk   int    // 1-based number of the chunk just summarized
+	Total   int    // Number of chunks of the video, including chunks resumed from a partial result
+	Text    string // Summary of the chunk
+	Summary string // Summary of all chunks so far
+}
+
+// summarizationPrompt returns the system prompt of a summary style that makes the model write in the given language.
+// Unknown styles use SummarizationPrompt. The [MM:SS] timestamp format stays the same for every language
+// so ExtractTimestamps keeps working.
+func summarizationPrompt(language, style string) string {
+	prompt, ok := summaryStylePrompts[style]
+	if !ok {
+		prompt = SummarizationPrompt
+	}
+	if language == "" || language == DefaultSummaryLanguage {
+		return prompt
+	}
+	return strings.ReplaceAll(prompt, "Korean", LanguageName(language))
 }
 
 // GPTResponse represents the response from the GPT API
 type GPTResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string      `json:"id"`
+	Object  string      `json:"object"`
+	Created int         `json:"created"`
+	Choices []GPTChoice `json:"choices"`
+	Usage   GPTUsage    `json:"usage"`
+}
+
+// GPTChoice is one completion in a GPTResponse
+type GPTChoice struct {
+	Index        int        `json:"index"`
+	Message      GPTMessage `json:"message"`
+	FinishReason string     `json:"finish_reason"`
+}
+
+// GPTUsage is the token usage reported in a GPTResponse
+type GPTUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // SummarizeTranscript generates a summary of a transcript using OpenAI's API
 // userAPIKey: 사용자가 제공한 API 키 (없는 경우 빈 문자열)
 // userID: 사용자 ID (서버 API 키 사용 권한 확인용)
-func SummarizeTranscript(request *GPTRequest, transcript string, userAPIKey string, userID string) (string, []TimestampInfo, error) {
-	// API 키 결정 (사용자 키 우선, 없으면 서버 키 정책에 따라 결정)
-	apiKey := ""
+func SummarizeTranscript(ctx context.Context, request *GPTRequest, transcript string, userAPIKey string, userID string) (string, []TimestampInfo, error) {
+	ctx, span := StartSpan(ctx, "openai chat completion")
+	summary, timestamps, err := summarizeTranscript(ctx, request, transcript, userAPIKey, userID)
+	span.SetAttributes(attribute.String("openai.model", request.Model))
+	EndSpan(span, err)
+	return summary, timestamps, err
+}
 
+// resolveOpenAIKey returns the key an OpenAI request is made with: the user's own key if given,
+// otherwise the server key if the API key policy allows the user to use it, otherwise ""
+func resolveOpenAIKey(userAPIKey, userID string) string {
 	// 사용자 API 키가 제공된 경우 우선 사용
 	if userAPIKey != "" {
-		apiKey = userAPIKey
-	} else {
-		// 사용자 API 키가 없는 경우, 서버 키 사용 가능한지 확인
-		policy := GetAPIKeyPolicy()
-		if policy.CanUseServerKey(userID) {
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		}
+		return userAPIKey
 	}
+	// 사용자 API 키가 없는 경우, 서버 키 사용 가능한지 확인
+	if GetAPIKeyPolicy().allowsServerKey(userID) {
+		return os.Getenv("OPENAI_API_KEY")
+	}
+	return ""
+}
+
+func summarizeTranscript(ctx context.Context, request *GPTRequest, transcript string, userAPIKey string, userID string) (string, []TimestampInfo, error) {
+	// API 키 결정 (사용자 키 우선, 없으면 서버 키 정책에 따라 결정)
+	apiKey := resolveOpenAIKey(userAPIKey, userID)
 
 	// API 키가 없으면 에러 반환
 	if apiKey == "" {
@@ -139,25 +262,12 @@ func SummarizeTranscript(request *GPTRequest, transcript string, userAPIKey stri
 
 	// 환경 변수 설정 가져오기
 	apiUrl := os.Getenv("OPENAI_API_URL")
-	apiModel := os.Getenv("OPENAI_API_MODEL")
-	apiMaxTokensStr := os.Getenv("OPENAI_API_MAX_TOKENS")
-
-	apiMaxTokens := MaxTokens // 기본값 설정
-	if apiMaxTokensStr != "" {
-		var err error
-		apiMaxTokens, err = strconv.Atoi(apiMaxTokensStr)
-		if err != nil {
-			// 변환 실패 시 기본값 사용
-			apiMaxTokens = MaxTokens
-		}
-	}
+	apiModel := summaryModel(request.model)
+	apiMaxTokens := openAIMaxTokens()
 
 	if apiUrl == "" {
 		apiUrl = OpenAIAPIURL
 	}
-	if apiModel == "" {
-		apiModel = Model
-	}
 
 	// Create the system prompt with the transcript
 	userPrompt := fmt.Sprintf("Transcript: %s\n", transcript)
@@ -169,13 +279,22 @@ func SummarizeTranscript(request *GPTRequest, transcript string, userAPIKey stri
 	}
 
 	request.Model = apiModel
-	request.MaxTokens = apiMaxTokens
-	request.Temperature = 0.2
+	request.Temperature = DefaultTemperature
+	if request.temperature != nil {
+		request.Temperature = *request.temperature
+	}
+	request.Stream = GetEnvBool("OPENAI_STREAM", false)
+	flavor := openAIAPIFlavor()
+	applyAPIFlavor(request, flavor, apiMaxTokens)
 
+	systemPrompt := request.systemPrompt
+	if systemPrompt == "" {
+		systemPrompt = SummarizationPrompt
+	}
 	request.Messages = append(request.Messages,
 		GPTMessage{
 			Role:    "system",
-			Content: SummarizationPrompt,
+			Content: systemPrompt,
 		})
 	request.Messages = append(request.Messages,
 		GPTMessage{
@@ -199,16 +318,102 @@ func SummarizeTranscript(request *GPTRequest, transcript string, userAPIKey stri
 	// 	Temperature: 0.2,
 	// }
 
+	fallbackEnabled := GetEnvBool("MODEL_FALLBACK_ON_ACCESS_ERROR", false)
+	if _, rejected := rejectedModels.Load(request.Model); rejected && fallbackEnabled {
+		// Don't wait for another access error before using the fallback model
+		request.Model = Model
+	}
+
+	response, err := sendChatCompletion(ctx, apiUrl, apiKey, request)
+	if err != nil && fallbackEnabled && request.Model != Model && isModelAccessError(err) {
+		LogWarn("Model %q was rejected by the provider (%v). Falling back to %q.", request.Model, err, Model)
+		rejectedModels.Store(request.Model, true)
+		request.Model = Model
+		response, err = sendChatCompletion(ctx, apiUrl, apiKey, request)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if response.Usage == (GPTUsage{}) && flavor != APIFlavorCompatible {
+		LogDebug("The chat completion response of model %s reported no token usage.", request.Model)
+	}
+	request.usage.Add(TokenUsage{PromptTokens: response.Usage.PromptTokens, CompletionTokens: response.Usage.CompletionTokens})
+
+	// Get the generated summary
+	summary := response.Choices[0].Message.Content
+
+	request.Messages = append(request.Messages,
+		GPTMessage{
+			Role:    "assistant",
+			Content: summary,
+		},
+	)
+
+	// Extract timestamps from the summary
+	timestamps := ExtractTimestamps(summary)
+
+	return summary, timestamps, nil
+}
+
+// rejectedModels remembers configured models the provider refused, so later chunks use the fallback right away
+var rejectedModels sync.Map
+
+// APIStatusError is returned when the OpenAI API answers with a non-200 status
+type APIStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the Retry-After header, if the response had one
+}
+
+func (e *APIStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// isModelAccessError reports whether err means the model doesn't exist or the key may not use it
+func isModelAccessError(err error) bool {
+	var statusErr *APIStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	if statusErr.StatusCode != http.StatusNotFound && statusErr.StatusCode != http.StatusForbidden {
+		return false
+	}
+	// e.g. {"error": {"code": "model_not_found", "message": "The model `x` does not exist or you do not have access to it."}}
+	return strings.Contains(strings.ToLower(statusErr.Body), "model")
+}
+
+// sendChatCompletion posts a chat completion request and parses the response.
+// Transient failures are retried up to OPENAI_MAX_RETRIES times with exponential backoff.
+func sendChatCompletion(ctx context.Context, apiUrl, apiKey string, request *GPTRequest) (*GPTResponse, error) {
 	// Convert request body to JSON
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
+	maxRetries := openAIMaxRetries()
+	for attempt := 0; ; attempt++ {
+		response, err := postChatCompletion(ctx, apiUrl, apiKey, requestJSON, request.Stream)
+		if err == nil || attempt >= maxRetries || !isRetryableOpenAIError(ctx, err) {
+			return response, err
+		}
+
+		delay := openAIRetryDelay(err, attempt+1)
+		LogWarn("OpenAI request failed (%v). Retrying in %s (%d/%d).", err, delay.Round(time.Millisecond), attempt+1, maxRetries)
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// postChatCompletion sends one chat completion request with an encoded body.
+// stream must match the request's Stream field.
+func postChatCompletion(ctx context.Context, apiUrl, apiKey string, requestJSON []byte, stream bool) (*GPTResponse, error) {
 	// Create HTTP request
-	req, err := http.NewRequest("POST", apiUrl, bytes.NewBuffer(requestJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiUrl, bytes.NewBuffer(requestJSON))
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	// Set headers
@@ -216,89 +421,181 @@ func SummarizeTranscript(request *GPTRequest, transcript string, userAPIKey stri
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := openAIClient().Do(req)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIStatusError{StatusCode: resp.StatusCode, Body: string(body), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	// Providers without streaming support may answer with a plain JSON response instead
+	if stream && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readChatCompletionStream(resp.Body)
 	}
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	// Parse response
 	var response GPTResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	// Check if we have a valid response
 	if len(response.Choices) == 0 {
-		return "", nil, errors.New("no response generated")
+		return nil, errors.New("no response generated")
 	}
+	return &response, nil
+}
 
-	// Get the generated summary
-	summary := response.Choices[0].Message.Content
+// SummarizeChunks processes each transcript chunk, summarizes it, and combines the summaries into a final summary
+// userAPIKey: 사용자가 제공한 API 키 (없는 경우 빈 문자열)
+// userID: 사용자 ID (서버 API 키 사용 권한 확인용)
+func SummarizeChunks(ctx context.Context, chunks [][]TranscriptItem, userAPIKey string, userID string) (string, error) {
+	result, err := SummarizeChunksDetailed(ctx, chunks, userAPIKey, userID)
+	if err != nil {
+		return "", err
+	}
+	return result.Summary, nil
+}
 
-	request.Messages = append(request.Messages,
-		GPTMessage{
-			Role:    "assistant",
-			Content: summary,
-		},
-	)
+// PartialSummaryError is returned by SummarizeChunksDetailed when a chunk fails after earlier chunks
+// were summarized, e.g. because the connection dropped. Partial holds the summaries completed so far.
+type PartialSummaryError struct {
+	Partial *ChunkedSummary
+	Err     error
+}
 
-	// Extract timestamps from the summary
-	timestamps := extractTimestamps(summary)
+func (e *PartialSummaryError) Error() string {
+	return e.Err.Error()
+}
 
-	return summary, timestamps, nil
+func (e *PartialSummaryError) Unwrap() error {
+	return e.Err
 }
 
-// SummarizeChunks processes each transcript chunk, summarizes it, and combines the summaries into a final summary
-// userAPIKey: 사용자가 제공한 API 키 (없는 경우 빈 문자열)
-// userID: 사용자 ID (서버 API 키 사용 권한 확인용)
-func SummarizeChunks(chunks [][]TranscriptItem, userAPIKey string, userID string) (string, error) {
+// SummarizeChunksDetailed works like SummarizeChunks but also keeps each chunk's summary
+// together with the start and end time of the transcript chunk it was generated from
+func SummarizeChunksDetailed(ctx context.Context, chunks [][]TranscriptItem, userAPIKey string, userID string) (*ChunkedSummary, error) {
+	return SummarizeChunksFrom(ctx, chunks, nil, SummarizeOptions{}, userAPIKey, userID)
+}
+
+// SummarizeChunksFrom continues an interrupted summary: the first len(done) chunks are taken from done
+// (the chunks of an earlier partial result) and only the remaining chunks are sent to the model.
+// opts selects the output language.
+func SummarizeChunksFrom(ctx context.Context, chunks [][]TranscriptItem, done []ChunkSummary, opts SummarizeOptions, userAPIKey string, userID string) (*ChunkedSummary, error) {
 	var finalSummary strings.Builder
-	var request *GPTRequest = &GPTRequest{}
+	var rawSummary strings.Builder
+	var request *GPTRequest = newSummaryRequest(opts)
+	result := &ChunkedSummary{}
+
+	// Chunks too dense for the model's context window are split instead of being rejected by the provider
+	chunks = fitChunksToContext(chunks, summaryModel(opts.Model), request.systemPrompt)
+	if len(done) > len(chunks) {
+		done = nil // Chunking changed since the partial result was stored; start over
+	}
+	if workers := chunkConcurrency(); workers > 1 && len(chunks)-len(done) > 1 {
+		return summarizeChunksConcurrently(ctx, chunks, done, opts, userAPIKey, userID, workers)
+	}
+
+	for _, chunk := range done {
+		finalSummary.WriteString(chunk.Text + "\n\n")
+		rawSummary.WriteString(chunk.Text + "\n\n")
+		result.Chunks = append(result.Chunks, chunk)
+	}
+	if len(done) > 0 {
+		// Let the model know what was already summarized so it doesn't repeat it
+		request.Messages = append(request.Messages, GPTMessage{Role: "assistant", Content: done[len(done)-1].Text})
+	}
+
+	for i := len(done); i < len(chunks); i++ {
+		chunk := chunks[i]
 
-	for i, chunk := range chunks {
 		// Summarize the chunk
-		summary, _, err := SummarizeTranscript(request, GetFormattedTranscript(chunk), userAPIKey, userID)
+		summary, _, err := SummarizeTranscript(ctx, request, chunkTranscript(chunks, i, opts.Chapters), userAPIKey, userID)
 		if err != nil {
-			return "", fmt.Errorf("failed to summarize chunk %d: %v", i+1, err)
+			err = fmt.Errorf("failed to summarize chunk %d: %v", i+1, err)
+			if len(result.Chunks) > 0 {
+				result.Summary = finalSummary.String()
+				result.RawSummary = rawSummary.String()
+				result.Usage = request.usage
+				return nil, &PartialSummaryError{Partial: result, Err: err}
+			}
+			return nil, err
 		}
 
+		result.Model = request.Model
+		rawSummary.WriteString(summary + "\n\n")
+
 		// Remove any <think>...</think> tags from the summary
-		// This can happen when the AI model includes its thinking process
-		summary = regexp.MustCompile(`(?s)<think>.*?</think>`).ReplaceAllString(summary, "")
+		summary = removeThinkTags(summary)
+		summary = withChapterHeading(summary, chunks, i, opts.Chapters)
 
 		// Append the chunk summary to the final summary
 		finalSummary.WriteString(summary + "\n\n")
+
+		startSec, endSec := chunkTimeRange(chunk)
+		result.Chunks = append(result.Chunks, ChunkSummary{
+			StartSec: startSec,
+			EndSec:   endSec,
+			Text:     strings.TrimSpace(summary),
+			Chapter:  chunkChapter(chunks, i, opts.Chapters),
+		})
+		if opts.OnChunk != nil {
+			opts.OnChunk(ChunkProgress{Chunk: i + 1, Total: len(chunks), Text: strings.TrimSpace(summary), Summary: finalSummary.String()})
+		}
 	}
 
-	return finalSummary.String(), nil
+	result.Summary = finalSummary.String()
+	result.RawSummary = rawSummary.String()
+	result.Usage = request.usage
+	return result, nil
 }
 
-// extractTimestamps parses the summary text for timestamp markers and extracts them
-func extractTimestamps(summary string) []TimestampInfo {
-	var timestamps []TimestampInfo
+// removeThinkTags removes any <think>...</think> tags from a summary.
+// This can happen when the AI model includes its thinking process.
+func removeThinkTags(summary string) string {
+	return thinkTagPattern.ReplaceAllString(summary, "")
+}
+
+var thinkTagPattern = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// chunkTimeRange returns the start of the first item and the latest end time in a transcript chunk
+func chunkTimeRange(chunk []TranscriptItem) (float64, float64) {
+	if len(chunk) == 0 {
+		return 0, 0
+	}
+
+	start := chunk[0].Start
+	end := start
+	for _, item := range chunk {
+		if item.Start < start {
+			start = item.Start
+		}
+		if item.Start+item.Duration > end {
+			end = item.Start + item.Duration
+		}
+	}
+	return start, end
+}
 
-	// Regular expression to find timestamps in format [MM:SS] or [HH:MM:SS]
-	re := regexp.MustCompile(`\[(\d{1,2}):(\d{2})(?::(\d{2}))?\]`)
-	matches := re.FindAllStringSubmatchIndex(summary, -1)
+// timestampMarkerPattern matches the [MM:SS] and [HH:MM:SS] timestamp markers in summaries
+var timestampMarkerPattern = regexp.MustCompile(`\[(\d{1,2}):(\d{2})(?::(\d{2}))?\]`)
 
-	for _, match := range matches {
-		// Extract timestamp text
-		timestampStr := summary[match[0]:match[1]]
+// ExtractTimestamps parses the summary text for timestamp markers and extracts them
+func ExtractTimestamps(summary string) []TimestampInfo {
+	var timestamps []TimestampInfo
 
+	for _, match := range timestampMarkerPattern.FindAllStringSubmatchIndex(summary, -1) {
 		// Extract the sentence following the timestamp (up to the next period or end of text)
 		startIndex := match[1]
 		endIndex := len(summary)
@@ -310,24 +607,17 @@ func extractTimestamps(summary string) []TimestampInfo {
 
 		text := strings.TrimSpace(summary[startIndex:endIndex])
 
-		// Parse time components
-		var hours, minutes, seconds int
-		timestampComponents := re.FindStringSubmatch(timestampStr)
-
-		if len(timestampComponents) >= 3 {
-			fmt.Sscanf(timestampComponents[1], "%d", &minutes)
-			fmt.Sscanf(timestampComponents[2], "%d", &seconds)
-
-			if len(timestampComponents) >= 4 && timestampComponents[3] != "" {
-				// We have an HH:MM:SS format
-				hours = minutes
-				minutes = seconds
-				fmt.Sscanf(timestampComponents[3], "%d", &seconds)
-			}
+		// Parse time components: [MM:SS] has two groups, [HH:MM:SS] a third one for the seconds
+		group := func(i int) int {
+			value, _ := strconv.Atoi(summary[match[2*i]:match[2*i+1]])
+			return value
+		}
+		var timeInSeconds int
+		if match[6] >= 0 {
+			timeInSeconds = group(1)*3600 + group(2)*60 + group(3)
+		} else {
+			timeInSeconds = group(1)*60 + group(2)
 		}
-
-		// Convert to seconds
-		timeInSeconds := hours*3600 + minutes*60 + seconds
 
 		timestamps = append(timestamps, TimestampInfo{
 			Time: timeInSeconds,
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// chunkContextChars is how much of the previous chunk's transcript is sent along with a chunk
+// summarized in parallel, so the model can tell where the section starts without repeating it
+const chunkContextChars = 600
+
+// chunkConcurrency returns how many chunks are summarized at once, as configured via
+// OPENAI_CHUNK_CONCURRENCY. 1 (the default) keeps the sequential mode with conversation history.
+func chunkConcurrency() int {
+	if n := GetEnvInt("OPENAI_CHUNK_CONCURRENCY", 1); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// chunkResult is the outcome of summarizing one chunk in parallel
+type chunkResult struct {
+	raw     string
+	summary string
+	model   string
+	usage   TokenUsage
+	err     error
+}
+
+// summarizeChunksConcurrently summarizes the chunks after done with up to workers requests in flight
+// and reassembles the summaries in chunk order.
+// Chunks don't share a conversation history in this mode. Instead, each request carries the tail of the
+// previous chunk's transcript as context, or the last summary of done for the first chunk to resume.
+// If a chunk fails no further chunks are sent; the completed chunks before it are returned
+// as a PartialSummaryError like in the sequential mode.
+func summarizeChunksConcurrently(ctx context.Context, chunks [][]TranscriptItem, done []ChunkSummary, opts SummarizeOptions, userAPIKey string, userID string, workers int) (*ChunkedSummary, error) {
+	results := make([]chunkResult, len(chunks))
+	indexes := make(chan int)
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	progress := newChunkProgressReporter(opts.OnChunk, done, len(chunks))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				request := newSummaryRequest(opts)
+				request.Messages = chunkContextMessages(chunks, done, i)
+
+				summary, _, err := SummarizeTranscript(ctx, request, chunkTranscript(chunks, i, opts.Chapters), userAPIKey, userID)
+				if err != nil {
+					progress.store(results, i, chunkResult{err: err})
+					// Stop sending new chunks; the ones in flight still finish so the partial result keeps them
+					failOnce.Do(func() { close(failed) })
+					continue
+				}
+				progress.store(results, i, chunkResult{raw: summary, summary: withChapterHeading(removeThinkTags(summary), chunks, i, opts.Chapters), model: request.Model, usage: request.usage})
+			}
+		}()
+	}
+
+feed:
+	for i := len(done); i < len(chunks); i++ {
+		select {
+		case indexes <- i:
+		case <-failed:
+			break feed
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	var finalSummary strings.Builder
+	var rawSummary strings.Builder
+	result := &ChunkedSummary{}
+	for _, chunk := range done {
+		finalSummary.WriteString(chunk.Text + "\n\n")
+		rawSummary.WriteString(chunk.Text + "\n\n")
+		result.Chunks = append(result.Chunks, chunk)
+	}
+
+	for i := len(done); i < len(chunks); i++ {
+		res := results[i]
+		result.Usage.Add(res.usage)
+		if res.err != nil || res.model == "" {
+			// The first chunk that failed, or was never sent because another chunk failed first
+			err := res.err
+			if err == nil {
+				err = firstChunkError(ctx, results[i:])
+			}
+			err = fmt.Errorf("failed to summarize chunk %d: %v", i+1, err)
+			if len(result.Chunks) > 0 {
+				result.Summary = finalSummary.String()
+				result.RawSummary = rawSummary.String()
+				return nil, &PartialSummaryError{Partial: result, Err: err}
+			}
+			return nil, err
+		}
+
+		result.Model = res.model
+		rawSummary.WriteString(res.raw + "\n\n")
+		finalSummary.WriteString(res.summary + "\n\n")
+
+		startSec, endSec := chunkTimeRange(chunks[i])
+		result.Chunks = append(result.Chunks, ChunkSummary{
+			StartSec: startSec,
+			EndSec:   endSec,
+			Text:     strings.TrimSpace(res.summary),
+			Chapter:  chunkChapter(chunks, i, opts.Chapters),
+		})
+	}
+
+	result.Summary = finalSummary.String()
+	result.RawSummary = rawSummary.String()
+	return result, nil
+}
+
+// chunkProgressReporter stores the results of chunks summarized in parallel and calls
+// SummarizeOptions.OnChunk in chunk order, although chunks complete out of order
+type chunkProgressReporter struct {
+	onChunk func(ChunkProgress)
+	total   int
+
+	mutex   sync.Mutex
+	next    int             // First chunk not reported yet
+	summary strings.Builder // Summary of the reported chunks
+}
+
+func newChunkProgressReporter(onChunk func(ChunkProgress), done []ChunkSummary, total int) *chunkProgressReporter {
+	r := &chunkProgressReporter{onChunk: onChunk, total: total, next: len(done)}
+	for _, chunk := range done {
+		r.summary.WriteString(chunk.Text + "\n\n")
+	}
+	return r
+}
+
+// store sets the result of chunk i and reports every chunk that is now next in order
+func (r *chunkProgressReporter) store(results []chunkResult, i int, res chunkResult) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	results[i] = res
+	if r.onChunk == nil {
+		return
+	}
+	for r.next < r.total && results[r.next].model != "" {
+		res := results[r.next]
+		r.summary.WriteString(res.summary + "\n\n")
+		r.next++
+		r.onChunk(ChunkProgress{Chunk: r.next, Total: r.total, Text: strings.TrimSpace(res.summary), Summary: r.summary.String()})
+	}
+}
+
+// chunkContextMessages returns the context sent ahead of chunk i when chunks are summarized in parallel
+func chunkContextMessages(chunks [][]TranscriptItem, done []ChunkSummary, i int) []GPTMessage {
+	if i == len(done) && len(done) > 0 {
+		// Let the model know what was already summarized so it doesn't repeat it
+		return []GPTMessage{{Role: "assistant", Content: done[len(done)-1].Text}}
+	}
+	if i == 0 {
+		return nil
+	}
+
+	previous := GetFormattedTranscript(chunks[i-1])
+	if runes := []rune(previous); len(runes) > chunkContextChars {
+		previous = string(runes[len(runes)-chunkContextChars:])
+	}
+	return []GPTMessage{{
+		Role:    "user",
+		Content: "End of the previous section, for context only. It is summarized separately; do not summarize it again:\n" + previous,
+	}}
+}
+
+// firstChunkError returns the first error among results, used to explain why a chunk was never summarized
+func firstChunkError(ctx context.Context, results []chunkResult) error {
+	for _, res := range results {
+		if res.err != nil {
+			return res.err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return context.Canceled
+}
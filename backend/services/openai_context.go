@@ -0,0 +1,137 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultContextTokens is assumed for models without a known or configured context window
+const defaultContextTokens = 128000
+
+// contextSafetyMargin leaves room for message framing and estimation error
+const contextSafetyMargin = 512
+
+// minChunkTokens keeps misconfigured context limits from splitting transcripts into single lines
+const minChunkTokens = 500
+
+// modelContextTokens holds the context windows of common models. OPENAI_MODEL_CONTEXT_TOKENS adds to and overrides it.
+var modelContextTokens = map[string]int{
+	"gpt-4.1":       1047576,
+	"gpt-4.1-mini":  1047576,
+	"gpt-4.1-nano":  1047576,
+	"gpt-4o":        128000,
+	"gpt-4o-mini":   128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+}
+
+// EstimateTokens returns a rough token count of text. It counts one token per 3 bytes of UTF-8, which
+// is close to the real count for non-Latin scripts and errs on the high side for English.
+func EstimateTokens(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// ModelContextTokens returns the context window of a model in tokens.
+// OPENAI_MODEL_CONTEXT_TOKENS configures it as comma separated model:tokens entries (e.g. "llama3:8192").
+func ModelContextTokens(model string) int {
+	for _, entry := range strings.Split(os.Getenv("OPENAI_MODEL_CONTEXT_TOKENS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// The model is everything before the last colon, since fine-tuned model names contain colons
+		sep := strings.LastIndex(entry, ":")
+		tokens, err := strconv.Atoi(strings.TrimSpace(entry[sep+1:]))
+		if sep <= 0 || err != nil || tokens <= 0 {
+			LogWarn("Ignoring OPENAI_MODEL_CONTEXT_TOKENS entry %q: expected model:tokens", entry)
+			continue
+		}
+		if strings.TrimSpace(entry[:sep]) == model {
+			return tokens
+		}
+	}
+	if tokens, ok := modelContextTokens[model]; ok {
+		return tokens
+	}
+	return defaultContextTokens
+}
+
+// maxChunkTokens returns how many tokens of transcript one request may carry with the given model.
+// Besides the chunk, a request holds the system prompt, the previous chunk and its summary as
+// history, and room for the completion.
+func maxChunkTokens(model, systemPrompt string) int {
+	completion := openAIMaxTokens()
+	available := ModelContextTokens(model) - EstimateTokens(systemPrompt) - 2*completion - contextSafetyMargin
+	// The previous chunk is sent as history, so each chunk gets half of what is left
+	return max(available/2, minChunkTokens)
+}
+
+// fitChunksToContext splits chunks whose transcript would not fit in the model's context window into
+// consecutive smaller chunks, so the provider doesn't reject the request. Chunks that fit are kept as is.
+func fitChunksToContext(chunks [][]TranscriptItem, model, systemPrompt string) [][]TranscriptItem {
+	limit := maxChunkTokens(model, systemPrompt)
+	var fitted [][]TranscriptItem
+	for _, chunk := range chunks {
+		if EstimateTokens(GetFormattedTranscript(chunk)) <= limit {
+			fitted = append(fitted, chunk)
+			continue
+		}
+		parts := splitChunk(chunk, limit)
+		LogInfo("Transcript chunk of ~%d tokens exceeds the %d token budget of model %q; split into %d parts.",
+			EstimateTokens(GetFormattedTranscript(chunk)), limit, model, len(parts))
+		fitted = append(fitted, parts...)
+	}
+	return fitted
+}
+
+// splitChunk divides a chunk into consecutive parts of at most limit estimated tokens.
+// Items longer than the limit on their own have their text cut into several items with the same start.
+func splitChunk(chunk []TranscriptItem, limit int) [][]TranscriptItem {
+	var parts [][]TranscriptItem
+	var part []TranscriptItem
+	partTokens := 0
+	for _, item := range chunk {
+		for _, piece := range splitItemText(item, limit) {
+			tokens := EstimateTokens(GetFormattedTranscript([]TranscriptItem{piece}))
+			if len(part) > 0 && partTokens+tokens > limit {
+				parts = append(parts, part)
+				part, partTokens = nil, 0
+			}
+			part = append(part, piece)
+			partTokens += tokens
+		}
+	}
+	if len(part) > 0 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// splitItemText cuts an item whose text alone exceeds limit estimated tokens into several items
+func splitItemText(item TranscriptItem, limit int) []TranscriptItem {
+	maxBytes := limit*3 - 32 // Room for the timestamp prefix of the formatted line
+	if len(item.Text) <= maxBytes {
+		return []TranscriptItem{item}
+	}
+	var pieces []TranscriptItem
+	text := item.Text
+	for len(text) > maxBytes {
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut-- // Don't split a multi-byte character
+		}
+		if space := strings.LastIndex(text[:cut], " "); space > cut/2 {
+			cut = space
+		}
+		piece := item
+		piece.Text = strings.TrimSpace(text[:cut])
+		pieces = append(pieces, piece)
+		text = text[cut:]
+	}
+	piece := item
+	piece.Text = strings.TrimSpace(text)
+	return append(pieces, piece)
+}
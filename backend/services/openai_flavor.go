@@ -0,0 +1,46 @@
+package services
+
+import (
+	"os"
+	"strings"
+)
+
+// Chat completion API flavors, selected with OPENAI_API_FLAVOR for providers that differ from OpenAI's schema
+const (
+	// APIFlavorOpenAI sends max_tokens and asks for the token usage of streamed completions
+	APIFlavorOpenAI = "openai"
+	// APIFlavorOpenAIReasoning sends max_completion_tokens, which OpenAI's reasoning models require instead of max_tokens
+	APIFlavorOpenAIReasoning = "openai-reasoning"
+	// APIFlavorCompatible is for OpenAI-compatible servers such as Ollama, LM Studio or vLLM. It leaves out
+	// stream_options, which some of them reject, and doesn't expect the response to report the token usage.
+	APIFlavorCompatible = "compatible"
+)
+
+// openAIAPIFlavor returns the flavor configured via OPENAI_API_FLAVOR. Unknown values use APIFlavorOpenAI.
+func openAIAPIFlavor() string {
+	flavor := strings.ToLower(strings.TrimSpace(os.Getenv("OPENAI_API_FLAVOR")))
+	switch flavor {
+	case APIFlavorOpenAIReasoning, APIFlavorCompatible:
+		return flavor
+	case "", APIFlavorOpenAI:
+		return APIFlavorOpenAI
+	default:
+		LogWarn("Unknown OPENAI_API_FLAVOR %q. Using %q.", flavor, APIFlavorOpenAI)
+		return APIFlavorOpenAI
+	}
+}
+
+// applyAPIFlavor sets the completion token limit and stream options of a request in the form the provider expects
+func applyAPIFlavor(request *GPTRequest, flavor string, maxTokens int) {
+	request.MaxTokens, request.MaxCompletionTokens = 0, 0
+	if flavor == APIFlavorOpenAIReasoning {
+		request.MaxCompletionTokens = maxTokens
+	} else {
+		request.MaxTokens = maxTokens
+	}
+
+	request.StreamOptions = nil
+	if request.Stream && flavor != APIFlavorCompatible {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OpenAIModelsURL lists the models a key may use; it is the cheapest authenticated call
+	OpenAIModelsURL = "https://api.openai.com/v1/models"
+
+	// defaultAPIKeyValidationTTLSeconds keeps validation results long enough to cover a burst of requests
+	defaultAPIKeyValidationTTLSeconds = 600
+
+	// apiKeyValidationTimeout bounds the check so a slow provider doesn't hold up the request
+	apiKeyValidationTimeout = 5 * time.Second
+)
+
+// ErrInvalidAPIKey is returned when the provider rejects a user-supplied API key
+var ErrInvalidAPIKey = errors.New("the OpenAI API key was rejected")
+
+// apiKeyValidation is a cached ValidateAPIKey verdict
+type apiKeyValidation struct {
+	valid   bool
+	expires time.Time
+}
+
+var (
+	apiKeyValidationMutex sync.Mutex
+	apiKeyValidations     = make(map[string]apiKeyValidation) // Keyed by the SHA-256 of the key, never the key itself
+)
+
+// apiKeyValidationTTL returns how long validation results are reused, configured via API_KEY_VALIDATION_TTL_SECONDS.
+// A value of 0 disables the cache.
+func apiKeyValidationTTL() time.Duration {
+	seconds := GetEnvInt("API_KEY_VALIDATION_TTL_SECONDS", defaultAPIKeyValidationTTLSeconds)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// openAIModelsURL returns the endpoint used to validate keys.
+// OPENAI_MODELS_URL wins; otherwise it is derived from OPENAI_API_URL. An empty result means the
+// configured provider has no known models endpoint and keys can't be checked up front.
+func openAIModelsURL() string {
+	if modelsURL := os.Getenv("OPENAI_MODELS_URL"); modelsURL != "" {
+		return modelsURL
+	}
+	apiUrl := os.Getenv("OPENAI_API_URL")
+	if apiUrl == "" {
+		return OpenAIModelsURL
+	}
+	if base, ok := strings.CutSuffix(strings.TrimRight(apiUrl, "/"), "/chat/completions"); ok {
+		return base + "/models"
+	}
+	return ""
+}
+
+// ValidateAPIKey checks a user-supplied key with a GET on the models endpoint.
+// It returns ErrInvalidAPIKey only when the provider answers 401 or 403; network failures and other
+// statuses are returned as-is so callers can let the job run and fail later if the key really is bad.
+// Verdicts are cached for API_KEY_VALIDATION_TTL_SECONDS.
+func ValidateAPIKey(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrInvalidAPIKey
+	}
+	modelsURL := openAIModelsURL()
+	if modelsURL == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	cacheKey := hex.EncodeToString(sum[:])
+	ttl := apiKeyValidationTTL()
+	if ttl > 0 {
+		apiKeyValidationMutex.Lock()
+		cached, ok := apiKeyValidations[cacheKey]
+		if ok && time.Now().After(cached.expires) {
+			delete(apiKeyValidations, cacheKey)
+			ok = false
+		}
+		apiKeyValidationMutex.Unlock()
+		if ok {
+			if !cached.valid {
+				return ErrInvalidAPIKey
+			}
+			return nil
+		}
+	}
+
+	valid, err := requestModels(ctx, modelsURL, key)
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		apiKeyValidationMutex.Lock()
+		apiKeyValidations[cacheKey] = apiKeyValidation{valid: valid, expires: time.Now().Add(ttl)}
+		apiKeyValidationMutex.Unlock()
+	}
+	if !valid {
+		return ErrInvalidAPIKey
+	}
+	return nil
+}
+
+// requestModels asks the models endpoint whether key is accepted
+func requestModels(ctx context.Context, modelsURL, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiKeyValidationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	resp, err := openAIClient().Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, &APIStatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+}
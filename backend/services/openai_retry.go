@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIMaxRetries     = 3
+	defaultOpenAIRetryBaseDelay = time.Second
+	maxOpenAIRetryDelay         = 30 * time.Second
+	maxOpenAIRetryAfter         = 2 * time.Minute // Longer Retry-After values are not waited for
+	defaultOpenAITimeout        = 120 * time.Second
+)
+
+// openAIMaxRetries returns how often a failed request is retried, configured via OPENAI_MAX_RETRIES
+func openAIMaxRetries() int {
+	if n := GetEnvInt("OPENAI_MAX_RETRIES", defaultOpenAIMaxRetries); n >= 0 {
+		return n
+	}
+	return defaultOpenAIMaxRetries
+}
+
+// openAIClient returns the HTTP client for OpenAI requests, with the timeout configured via OPENAI_TIMEOUT_SECONDS
+func openAIClient() *http.Client {
+	timeout := defaultOpenAITimeout
+	if seconds := GetEnvInt("OPENAI_TIMEOUT_SECONDS", 0); seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// isRetryableOpenAIError reports whether a failed request may succeed when sent again:
+// network errors, timeouts, rate limiting (429) and server errors (5xx).
+// Client errors such as 400 and 401 fail fast, and a canceled request is not retried.
+func isRetryableOpenAIError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *APIStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// openAIRetryDelay returns how long to wait before retry number attempt (starting at 1): the
+// Retry-After of the failed response if it sent one, otherwise an exponential backoff with jitter
+// starting at OPENAI_RETRY_BASE_DELAY_MS.
+func openAIRetryDelay(err error, attempt int) time.Duration {
+	var statusErr *APIStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, maxOpenAIRetryAfter)
+	}
+
+	base := defaultOpenAIRetryBaseDelay
+	if ms := GetEnvInt("OPENAI_RETRY_BASE_DELAY_MS", 0); ms > 0 {
+		base = time.Duration(ms) * time.Millisecond
+	}
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > maxOpenAIRetryDelay {
+		delay = maxOpenAIRetryDelay
+	}
+	// Full jitter between half and the whole delay, so parallel chunks don't retry in lockstep
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StreamOptions configures a streamed chat completion
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // Send the token usage in a final event
+}
+
+// gptStreamChunk is the data of one server-sent event of a streamed chat completion
+type gptStreamChunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *GPTUsage `json:"usage"`
+}
+
+// streamDoneSentinel is the data of the event that ends a stream
+const streamDoneSentinel = "[DONE]"
+
+// readChatCompletionStream reads a streamed chat completion and assembles the deltas into the
+// GPTResponse a non-streamed request would have returned. An event's data may span several
+// data: lines, which are joined before the JSON is parsed. A stream that ends without the
+// [DONE] sentinel was cut off and is reported as an error.
+func readChatCompletionStream(body io.Reader) (*GPTResponse, error) {
+	reader := bufio.NewReader(body)
+	response := &GPTResponse{}
+	var content strings.Builder
+	var data []string
+	received := false
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		// Comments (": keep-alive") and fields other than data are ignored
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+
+		// A blank line dispatches the pending event, as does the end of the body
+		if (line == "" || readErr != nil) && len(data) > 0 {
+			payload := strings.Join(data, "\n")
+			data = data[:0]
+			if payload == streamDoneSentinel {
+				break
+			}
+
+			var chunk gptStreamChunk
+			if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+				return nil, fmt.Errorf("invalid stream event: %w", err)
+			}
+			if chunk.Usage != nil {
+				response.Usage = *chunk.Usage
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Index != 0 {
+					continue
+				}
+				received = true
+				content.WriteString(choice.Delta.Content)
+				if choice.FinishReason != nil {
+					response.Choices = []GPTChoice{{FinishReason: *choice.FinishReason}}
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil, errors.New("stream ended before [DONE]")
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	if !received {
+		return nil, errors.New("no response generated")
+	}
+	if len(response.Choices) == 0 {
+		response.Choices = []GPTChoice{{}}
+	}
+	response.Choices[0].Message = GPTMessage{Role: "assistant", Content: content.String()}
+	return response, nil
+}
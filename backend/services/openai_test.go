@@ -0,0 +1,660 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDroppingOpenAIServer answers the first okResponses chat completion requests and then drops the connection mid-response
+func newDroppingOpenAIServer(t *testing.T, okResponses int32) *httptest.Server {
+	t.Setenv("OPENAI_RETRY_BASE_DELAY_MS", "1") // Dropped connections are retried
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= okResponses {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Chunk summary"}}]}`))
+			return
+		}
+
+		// Send part of the response, then disconnect
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatalf("hijack failed: %v", err)
+		}
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 500\r\n\r\n{\"choices\": [")
+		buf.Flush()
+		conn.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSummarizeChunksReturnsPartialResultOnDisconnect(t *testing.T) {
+	server := newDroppingOpenAIServer(t, 1)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	chunks := [][]TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "second", Start: 400, Duration: 5}},
+		{{Text: "third", Start: 800, Duration: 5}},
+	}
+
+	result, err := SummarizeChunksDetailed(context.Background(), chunks, "sk-test", "user")
+	assert.Nil(t, result)
+
+	var partialErr *PartialSummaryError
+	if assert.True(t, errors.As(err, &partialErr)) {
+		assert.Len(t, partialErr.Partial.Chunks, 1)
+		assert.Equal(t, "[00:00] Chunk summary", partialErr.Partial.Chunks[0].Text)
+		assert.Contains(t, partialErr.Partial.Summary, "Chunk summary")
+		assert.Contains(t, err.Error(), "chunk 2")
+	}
+}
+
+func TestSummarizeChunksFromContinuesPartialResult(t *testing.T) {
+	server := newDroppingOpenAIServer(t, 2)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	chunks := [][]TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "second", Start: 400, Duration: 5}},
+		{{Text: "third", Start: 800, Duration: 5}},
+	}
+	done := []ChunkSummary{{StartSec: 0, EndSec: 5, Text: "[00:00] Earlier summary"}}
+
+	result, err := SummarizeChunksFrom(context.Background(), chunks, done, SummarizeOptions{}, "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Len(t, result.Chunks, 3)
+	assert.Equal(t, "[00:00] Earlier summary", result.Chunks[0].Text)
+	assert.Equal(t, 800.0, result.Chunks[2].StartSec)
+}
+
+func TestSummarizeChunksFailsWithoutPartialWhenFirstChunkFails(t *testing.T) {
+	server := newDroppingOpenAIServer(t, 0)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	_, err := SummarizeChunksDetailed(context.Background(), [][]TranscriptItem{{{Text: "only", Duration: 1}}}, "sk-test", "user")
+	assert.Error(t, err)
+
+	var partialErr *PartialSummaryError
+	assert.False(t, errors.As(err, &partialErr))
+}
+
+func TestSummarizationPromptLanguage(t *testing.T) {
+	assert.Equal(t, SummarizationPrompt, summarizationPrompt("", ""))
+	assert.Equal(t, SummarizationPrompt, summarizationPrompt(DefaultSummaryLanguage, DefaultSummaryStyle))
+
+	english := summarizationPrompt("en", "")
+	assert.Contains(t, english, "All content in English")
+	assert.NotContains(t, english, "Korean")
+	assert.Contains(t, english, "[MM:SS]", "the timestamp format must not depend on the language")
+}
+
+func TestSummarizationPromptStyle(t *testing.T) {
+	for _, style := range SummaryStyles() {
+		prompt := summarizationPrompt("en", style)
+		assert.Contains(t, prompt, "English", style)
+		assert.NotContains(t, prompt, "Korean", style)
+	}
+	assert.Equal(t, tldrPrompt, summarizationPrompt("", StyleTLDR))
+	assert.NotContains(t, summarizationPrompt("", StyleBullets), "[MM:SS]")
+	assert.Contains(t, summarizationPrompt("", StyleDetailed), "[MM:SS]")
+
+	style, err := NormalizeSummaryStyle(" TLDR ")
+	assert.NoError(t, err)
+	assert.Equal(t, StyleTLDR, style)
+	style, err = NormalizeSummaryStyle("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSummaryStyle, style)
+	_, err = NormalizeSummaryStyle("haiku")
+	assert.Error(t, err)
+}
+
+func TestSummarizeChunksSendsLanguagePrompt(t *testing.T) {
+	var systemPrompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		for _, message := range request.Messages {
+			if message.Role == "system" {
+				systemPrompts = append(systemPrompts, message.Content)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Summary"}}], "usage": {"prompt_tokens": 120, "completion_tokens": 30}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	chunks := [][]TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}, {{Text: "world", Start: 400, Duration: 5}}}
+	result, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{Language: "en"}, "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:00] Summary", result.Chunks[0].Text)
+	assert.Equal(t, TokenUsage{PromptTokens: 240, CompletionTokens: 60}, result.Usage, "usage is summed over all chunks")
+	if assert.Len(t, systemPrompts, 2) {
+		assert.Contains(t, systemPrompts[0], "All content in English")
+	}
+}
+
+func TestSummarizeTranscriptFallsBackOnModelAccessError(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		models = append(models, request.Model)
+		if request.Model != Model {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "The model does not exist or you do not have access to it.", "code": "model_not_found"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_API_MODEL", "gpt-unavailable")
+	t.Cleanup(func() { rejectedModels.Delete("gpt-unavailable") })
+
+	// Without the option the access error is returned as is
+	t.Setenv("MODEL_FALLBACK_ON_ACCESS_ERROR", "false")
+	_, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "[00:00] hello", "sk-test", "user")
+	var statusErr *APIStatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+
+	t.Setenv("MODEL_FALLBACK_ON_ACCESS_ERROR", "true")
+	models = nil
+	chunks := [][]TranscriptItem{{{Text: "one", Start: 0, Duration: 5}}, {{Text: "two", Start: 400, Duration: 5}}}
+	result, err := SummarizeChunksDetailed(context.Background(), chunks, "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, Model, result.Model, "the model actually used is reported")
+	assert.Equal(t, []string{"gpt-unavailable", Model, Model}, models, "later chunks skip the rejected model")
+}
+
+func TestIsModelAccessError(t *testing.T) {
+	assert.True(t, isModelAccessError(&APIStatusError{StatusCode: http.StatusForbidden, Body: `{"error": {"message": "Project does not have access to model gpt-4o"}}`}))
+	assert.False(t, isModelAccessError(&APIStatusError{StatusCode: http.StatusUnauthorized, Body: `{"error": {"message": "Incorrect API key provided"}}`}))
+	assert.False(t, isModelAccessError(&APIStatusError{StatusCode: http.StatusNotFound, Body: "not found"}))
+	assert.False(t, isModelAccessError(errors.New("model timeout")))
+}
+
+// newChunkEchoServer summarizes each chunk as "Summary: <transcript>", answering later chunks faster so
+// parallel results arrive out of order. Transcripts containing "broken" fail with a 500.
+func newChunkEchoServer(t *testing.T) *httptest.Server {
+	t.Setenv("OPENAI_RETRY_BASE_DELAY_MS", "1") // Broken chunks are retried
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		transcript := request.Messages[len(request.Messages)-1].Content
+		if strings.Contains(transcript, "broken") {
+			http.Error(w, "upstream error", http.StatusInternalServerError)
+			return
+		}
+		if strings.Contains(transcript, "first") {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		content, _ := json.Marshal("Summary: " + transcript)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSummarizeChunksConcurrentlyKeepsChunkOrder(t *testing.T) {
+	server := newChunkEchoServer(t)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_CHUNK_CONCURRENCY", "3")
+
+	chunks := [][]TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "second", Start: 400, Duration: 5}},
+		{{Text: "third", Start: 800, Duration: 5}},
+	}
+	result, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{}, "sk-test", "user")
+	assert.NoError(t, err)
+	if assert.Len(t, result.Chunks, 3) {
+		assert.Contains(t, result.Chunks[0].Text, "first")
+		assert.Contains(t, result.Chunks[1].Text, "second")
+		assert.Contains(t, result.Chunks[2].Text, "third")
+		assert.Equal(t, 800.0, result.Chunks[2].StartSec)
+	}
+	assert.Less(t, strings.Index(result.Summary, "first"), strings.Index(result.Summary, "third"))
+}
+
+func TestSummarizeChunksConcurrentlyReportsFailedChunk(t *testing.T) {
+	server := newChunkEchoServer(t)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_CHUNK_CONCURRENCY", "2")
+
+	chunks := [][]TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "broken", Start: 400, Duration: 5}},
+		{{Text: "third", Start: 800, Duration: 5}},
+	}
+	_, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{}, "sk-test", "user")
+	assert.ErrorContains(t, err, "chunk 2")
+
+	var partialErr *PartialSummaryError
+	if assert.True(t, errors.As(err, &partialErr)) {
+		assert.Len(t, partialErr.Partial.Chunks, 1)
+		assert.Contains(t, partialErr.Partial.Chunks[0].Text, "first")
+	}
+}
+
+func TestSummarizeChunksReportsProgressInChunkOrder(t *testing.T) {
+	server := newChunkEchoServer(t)
+	t.Setenv("OPENAI_API_URL", server.URL)
+
+	chunks := [][]TranscriptItem{
+		{{Text: "first", Start: 0, Duration: 5}},
+		{{Text: "second", Start: 400, Duration: 5}},
+		{{Text: "third", Start: 800, Duration: 5}},
+	}
+	for _, concurrency := range []string{"1", "3"} {
+		t.Setenv("OPENAI_CHUNK_CONCURRENCY", concurrency)
+
+		var progress []ChunkProgress
+		opts := SummarizeOptions{OnChunk: func(p ChunkProgress) { progress = append(progress, p) }}
+		result, err := SummarizeChunksFrom(context.Background(), chunks, nil, opts, "sk-test", "user")
+		assert.NoError(t, err)
+		if assert.Len(t, progress, 3, concurrency) {
+			for i, p := range progress {
+				assert.Equal(t, i+1, p.Chunk)
+				assert.Equal(t, 3, p.Total)
+			}
+			assert.Contains(t, progress[0].Text, "first", "the slow first chunk is still reported first")
+			assert.NotContains(t, progress[1].Summary, "third")
+			assert.Equal(t, result.Summary, progress[2].Summary)
+		}
+	}
+}
+
+func TestCategorizeSummary(t *testing.T) {
+	answer := "Cooking."
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Contains(t, request.Messages[0].Content, "tech, cooking")
+		content, _ := json.Marshal(answer)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}], "usage": {"prompt_tokens": 50, "completion_tokens": 2}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("SUMMARY_CATEGORIES", "Tech, cooking,other")
+
+	category, usage, err := CategorizeSummary(context.Background(), "Pasta", "[00:00] Boiling water", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "cooking", category)
+	assert.Equal(t, 52, usage.TotalTokens())
+
+	answer = "Astrology"
+	category, _, err = CategorizeSummary(context.Background(), "Stars", "[00:00] Horoscopes", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", category, "unknown answers fall back to other")
+
+	t.Setenv("SUMMARY_CATEGORIES", "tech,cooking")
+	_, _, err = CategorizeSummary(context.Background(), "Stars", "[00:00] Horoscopes", "sk-test", "user")
+	assert.Error(t, err)
+}
+
+// newFlakyOpenAIServer answers with the given status codes in turn, then with a summary
+func newFlakyOpenAIServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if int(n) <= len(statuses) {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			http.Error(w, "try again", statuses[n-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_RETRY_BASE_DELAY_MS", "1")
+	return server, &requests
+}
+
+func TestSummarizeTranscriptRetriesTransientErrors(t *testing.T) {
+	_, requests := newFlakyOpenAIServer(t, nil, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+	summary, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "text", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Contains(t, summary, "Summary")
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestSummarizeTranscriptGivesUpAfterMaxRetries(t *testing.T) {
+	_, requests := newFlakyOpenAIServer(t, nil, 500, 500, 500)
+	t.Setenv("OPENAI_MAX_RETRIES", "1")
+
+	_, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "text", "sk-test", "user")
+	var statusErr *APIStatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, 500, statusErr.StatusCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestSummarizeTranscriptFailsFastOnClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized} {
+		_, requests := newFlakyOpenAIServer(t, nil, status)
+
+		_, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "text", "sk-test", "user")
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests), status)
+	}
+}
+
+func TestSummarizeTranscriptHonorsRetryAfter(t *testing.T) {
+	_, requests := newFlakyOpenAIServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+
+	start := time.Now()
+	_, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "text", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, float64(time.Minute), float64(parseRetryAfter(date)), float64(2*time.Second))
+}
+
+func TestSummarizeTranscriptStreamsCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.True(t, request.Stream)
+		if assert.NotNil(t, request.StreamOptions) {
+			assert.True(t, request.StreamOptions.IncludeUsage)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, part := range []string{
+			": keep-alive\n\n",
+			`data: {"choices": [{"index": 0, "delta": {"role": "assistant", "content": "[00:00] Hel"}}]}` + "\n\n",
+			`data: {"choices": [{"index": 0, "delta": {"con`, // A frame split across writes
+			`tent": "lo"}}]}` + "\n\n",
+			"data: {\"choices\": [{\"index\": 0,\ndata: \"delta\": {\"content\": \" world\"}, \"finish_reason\": \"stop\"}]}\n\n", // Data spanning two lines
+			`data: {"choices": [], "usage": {"prompt_tokens": 7, "completion_tokens": 3}}` + "\n\n",
+			"data: [DONE]\n\n",
+		} {
+			w.Write([]byte(part))
+			flusher.Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_STREAM", "true")
+
+	request := &GPTRequest{}
+	summary, timestamps, err := SummarizeTranscript(context.Background(), request, "text", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:00] Hello world", summary)
+	assert.Len(t, timestamps, 1)
+	assert.Equal(t, TokenUsage{PromptTokens: 7, CompletionTokens: 3}, request.usage)
+}
+
+func TestReadChatCompletionStream(t *testing.T) {
+	_, err := readChatCompletionStream(strings.NewReader(`data: {"choices": [{"index": 0, "delta": {"content": "cut"}}]}` + "\n\n"))
+	assert.ErrorContains(t, err, "[DONE]", "a stream cut off before [DONE] is incomplete")
+
+	_, err = readChatCompletionStream(strings.NewReader("data: {\"choices\": [{\n\ndata: [DONE]\n\n"))
+	assert.ErrorContains(t, err, "invalid stream event")
+
+	_, err = readChatCompletionStream(strings.NewReader("data: [DONE]"))
+	assert.ErrorContains(t, err, "no response generated")
+
+	response, err := readChatCompletionStream(strings.NewReader("data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"ok\"}}]}\r\n\r\ndata: [DONE]"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", response.Choices[0].Message.Content)
+}
+
+func TestSummarizeTranscriptStreamFallsBackToJSONResponse(t *testing.T) {
+	newFlakyOpenAIServer(t, nil) // Answers with plain JSON
+	t.Setenv("OPENAI_STREAM", "true")
+
+	summary, _, err := SummarizeTranscript(context.Background(), &GPTRequest{}, "text", "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Contains(t, summary, "Summary")
+}
+
+func TestSummarizeChunksUsesRequestedModelAndTemperature(t *testing.T) {
+	var models []string
+	var temperatures []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GPTRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		models = append(models, request.Model)
+		temperatures = append(temperatures, request.Temperature)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Summary"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_API_MODEL", "configured-model")
+
+	chunks := [][]TranscriptItem{{{Text: "hello", Start: 0, Duration: 5}}}
+	_, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{}, "sk-test", "user")
+	assert.NoError(t, err)
+
+	temperature := 1.3
+	result, err := SummarizeChunksFrom(context.Background(), chunks, nil, SummarizeOptions{Model: "gpt-4o", Temperature: &temperature}, "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "gpt-4o", result.Model)
+
+	assert.Equal(t, []string{"configured-model", "gpt-4o"}, models)
+	assert.Equal(t, []float64{DefaultTemperature, 1.3}, temperatures)
+}
+
+func TestExtractTimestamps(t *testing.T) {
+	tests := []struct {
+		summary string
+		time    int
+	}{
+		{"[05:30] Minutes and seconds.", 330},
+		{"[1:02:03] Hours, minutes and seconds.", 3723},
+		{"[00:00:00] Start.", 0},
+		{"[59:59] Last minute.", 3599},
+	}
+	for _, tt := range tests {
+		timestamps := ExtractTimestamps(tt.summary)
+		if assert.Len(t, timestamps, 1, tt.summary) {
+			assert.Equal(t, tt.time, timestamps[0].Time, tt.summary)
+		}
+	}
+
+	timestamps := ExtractTimestamps("[00:10] First point. More text\n[01:00:00] Second point")
+	assert.Equal(t, []TimestampInfo{{Time: 10, Text: "First point."}, {Time: 3600, Text: "Second point"}}, timestamps)
+	assert.Empty(t, ExtractTimestamps("No markers, [1:2] or [123:45]"))
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch r.Header.Get("Authorization") {
+		case "Bearer sk-good":
+			w.Write([]byte(`{"data": []}`))
+		case "Bearer sk-flaky":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_API_URL", server.URL+"/v1/chat/completions")
+	assert.Equal(t, server.URL+"/v1/models", openAIModelsURL())
+
+	ctx := context.Background()
+	assert.NoError(t, ValidateAPIKey(ctx, "sk-good"))
+	assert.ErrorIs(t, ValidateAPIKey(ctx, "sk-bad"), ErrInvalidAPIKey)
+	assert.ErrorIs(t, ValidateAPIKey(ctx, "sk-bad"), ErrInvalidAPIKey)
+	assert.NoError(t, ValidateAPIKey(ctx, "sk-good"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "verdicts should be cached")
+
+	// A provider outage doesn't mark the key invalid
+	err := ValidateAPIKey(ctx, "sk-flaky")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidAPIKey)
+
+	// Providers without a known models endpoint can't be checked
+	t.Setenv("OPENAI_API_URL", server.URL)
+	assert.NoError(t, ValidateAPIKey(ctx, "sk-unchecked"))
+}
+
+func TestSummarizeChunksSplitsChunksExceedingContext(t *testing.T) {
+	const contextTokens = 8000
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var request GPTRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		promptTokens := 0
+		for _, message := range request.Messages {
+			promptTokens += EstimateTokens(message.Content)
+		}
+		if promptTokens+request.MaxTokens > contextTokens {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "context_length_exceeded"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[00:00] Part"}}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_API_URL", server.URL)
+	t.Setenv("OPENAI_API_MODEL", "small-model")
+	t.Setenv("OPENAI_API_MAX_TOKENS", "500")
+	t.Setenv("OPENAI_MODEL_CONTEXT_TOKENS", "other:100, small-model:8000")
+	assert.Equal(t, contextTokens, ModelContextTokens("small-model"))
+
+	// One chunk of about 30,000 tokens
+	var chunk []TranscriptItem
+	for i := 0; i < 2000; i++ {
+		chunk = append(chunk, TranscriptItem{Text: strings.Repeat("dense words ", 3), Start: float64(i), Duration: 1})
+	}
+	result, err := SummarizeChunksDetailed(context.Background(), [][]TranscriptItem{chunk}, "sk-test", "user")
+	assert.NoError(t, err)
+	assert.Greater(t, int(atomic.LoadInt32(&requests)), 4)
+	assert.Len(t, result.Chunks, int(atomic.LoadInt32(&requests)))
+	assert.Equal(t, 0.0, result.Chunks[0].StartSec)
+	assert.Equal(t, 2000.0, result.Chunks[len(result.Chunks)-1].EndSec)
+}
+
+func TestSplitChunkCutsOversizedItems(t *testing.T) {
+	item := TranscriptItem{Text: strings.Repeat("가나다 ", 2000), Start: 10, Duration: 5}
+	parts := splitChunk([]TranscriptItem{item}, minChunkTokens)
+	assert.Greater(t, len(parts), 1)
+
+	var text strings.Builder
+	for _, part := range parts {
+		assert.LessOrEqual(t, EstimateTokens(GetFormattedTranscript(part)), minChunkTokens)
+		for _, piece := range part {
+			assert.Equal(t, 10.0, piece.Start)
+			text.WriteString(piece.Text + " ")
+		}
+	}
+	assert.Equal(t, strings.TrimSpace(item.Text), strings.TrimSpace(text.String()))
+}
+
+func TestSummarizeTranscriptAPIFlavors(t *testing.T) {
+	tests := []struct {
+		flavor         string
+		stream         bool
+		response       string // Response body; a text/event-stream if it starts with "data:"
+		wantField      string // Field the token limit is sent in
+		wantStreamOpts bool
+		wantUsage      TokenUsage
+	}{
+		{
+			flavor:    "",
+			response:  `{"choices": [{"message": {"content": "[00:00] Summary"}}], "usage": {"prompt_tokens": 10, "completion_tokens": 5}}`,
+			wantField: "max_tokens",
+			wantUsage: TokenUsage{PromptTokens: 10, CompletionTokens: 5},
+		},
+		{
+			flavor:    APIFlavorOpenAIReasoning,
+			response:  `{"choices": [{"message": {"content": "[00:00] Summary"}}], "usage": {"prompt_tokens": 10, "completion_tokens": 50}}`,
+			wantField: "max_completion_tokens",
+			wantUsage: TokenUsage{PromptTokens: 10, CompletionTokens: 50},
+		},
+		{
+			// e.g. Ollama, which may leave out the usage
+			flavor:    APIFlavorCompatible,
+			response:  `{"id": "chatcmpl-1", "object": "chat.completion", "model": "llama3.1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "[00:00] Summary"}, "finish_reason": "stop"}]}`,
+			wantField: "max_tokens",
+		},
+		{
+			flavor:    APIFlavorCompatible,
+			stream:    true,
+			response:  "data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"[00:00] Summary\"}, \"finish_reason\": \"stop\"}]}\n\ndata: [DONE]\n\n",
+			wantField: "max_tokens",
+		},
+		{
+			flavor:         APIFlavorOpenAI,
+			stream:         true,
+			response:       "data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"[00:00] Summary\"}}]}\n\ndata: {\"choices\": [], \"usage\": {\"prompt_tokens\": 3, \"completion_tokens\": 2}}\n\ndata: [DONE]\n\n",
+			wantField:      "max_tokens",
+			wantStreamOpts: true,
+			wantUsage:      TokenUsage{PromptTokens: 3, CompletionTokens: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s stream=%v", tt.flavor, tt.stream), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, 700.0, body[tt.wantField])
+				for _, field := range []string{"max_tokens", "max_completion_tokens"} {
+					if field != tt.wantField {
+						assert.NotContains(t, body, field)
+					}
+				}
+				_, hasStreamOpts := body["stream_options"]
+				assert.Equal(t, tt.wantStreamOpts, hasStreamOpts)
+
+				if strings.HasPrefix(tt.response, "data:") {
+					w.Header().Set("Content-Type", "text/event-stream")
+				} else {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				w.Write([]byte(tt.response))
+			}))
+			t.Cleanup(server.Close)
+			t.Setenv("OPENAI_API_URL", server.URL)
+			t.Setenv("OPENAI_API_FLAVOR", tt.flavor)
+			t.Setenv("OPENAI_API_MAX_TOKENS", "700")
+			t.Setenv("OPENAI_STREAM", strconv.FormatBool(tt.stream))
+
+			request := &GPTRequest{}
+			summary, _, err := SummarizeTranscript(context.Background(), request, "text", "sk-local", "user")
+			assert.NoError(t, err)
+			assert.Equal(t, "[00:00] Summary", summary)
+			assert.Equal(t, tt.wantUsage, request.usage)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akirose/youtube-summarizer/i18n"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// playlistIDPattern matches YouTube playlist IDs such as "PLxxxx", "UUxxxx" or "OLAK5uy_xxxx"
+var playlistIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{2,64}$`)
+
+// GetPlaylistID extracts the playlist ID from the list parameter of a YouTube URL,
+// e.g. https://www.youtube.com/playlist?list=PL... or a watch URL with &list=
+func GetPlaylistID(playlistURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(playlistURL))
+	if err != nil {
+		return "", i18n.NewError(i18n.MsgPlaylistURLInvalid)
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if host != "youtube.com" && host != "m.youtube.com" && host != "music.youtube.com" && host != "youtu.be" {
+		return "", i18n.NewError(i18n.MsgPlaylistURLNotYouTube)
+	}
+
+	playlistID := parsed.Query().Get("list")
+	if playlistID == "" {
+		return "", i18n.NewError(i18n.MsgPlaylistURLNoList)
+	}
+	if !playlistIDPattern.MatchString(playlistID) {
+		return "", i18n.NewError(i18n.MsgPlaylistURLListID)
+	}
+	return playlistID, nil
+}
+
+// GetPlaylistVideoIDs lists the video IDs of a playlist in playlist order, without fetching
+// the videos themselves. At most limit IDs are read, so callers can detect an oversized
+// playlist by asking for one more than they accept.
+func GetPlaylistVideoIDs(ctx context.Context, playlistID string, limit int) ([]string, error) {
+	_, span := StartSpan(ctx, "yt-dlp playlist", attribute.String("playlist.id", playlistID))
+	if err := waitForYtDlp(ctx); err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+	videoIDs, err := getPlaylistVideoIDs(ctx, playlistID, limit)
+	EndSpan(span, err)
+	return videoIDs, err
+}
+
+func getPlaylistVideoIDs(ctx context.Context, playlistID string, limit int) ([]string, error) {
+	// Validate the playlist ID to prevent command injection
+	if !playlistIDPattern.MatchString(playlistID) {
+		return nil, errors.New("invalid playlist ID format")
+	}
+
+	args := append(ytDlpOptionArgs(),
+		"--flat-playlist",
+		"--dump-json",
+		"--playlist-end", strconv.Itoa(limit),
+		"https://www.youtube.com/playlist?list="+playlistID,
+	)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	if err := runYtDlp(ctx, args, &out, &stderr); err != nil {
+		return nil, fmt.Errorf("yt-dlp error: %w - %s", err, stderr.String())
+	}
+
+	// One JSON object per entry; deleted or private videos still carry a valid ID and fail later on their own
+	var videoIDs []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(&out)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse yt-dlp output: %v", err)
+		}
+		if !IsValidVideoID(entry.ID) || seen[entry.ID] {
+			continue
+		}
+		seen[entry.ID] = true
+		videoIDs = append(videoIDs, entry.ID)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read yt-dlp output: %v", err)
+	}
+	return videoIDs, nil
+}
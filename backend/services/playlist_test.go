@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPlaylistID(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf", "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"},
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ&list=PL123_abc-XYZ&index=2", "PL123_abc-XYZ"},
+		{"https://m.youtube.com/playlist?list=OLAK5uy_abc&si=share", "OLAK5uy_abc"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", ""},
+		{"https://example.com/playlist?list=PL123", ""},
+		{"https://www.youtube.com/playlist?list=PL1;rm", ""},
+	}
+	for _, tt := range tests {
+		playlistID, err := GetPlaylistID(tt.url)
+		if tt.expected == "" {
+			assert.Error(t, err, tt.url)
+			continue
+		}
+		assert.NoError(t, err, tt.url)
+		assert.Equal(t, tt.expected, playlistID)
+	}
+}
+
+func TestGetPlaylistVideoIDs(t *testing.T) {
+	var gotArgs []string
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		gotArgs = cmd.Args
+		_, err := cmd.Stdout.Write([]byte(`{"id": "aaaaaaaaaaa", "title": "First"}
+{"id": "bbbbbbbbbbb", "title": "Second"}
+{"id": "aaaaaaaaaaa", "title": "First again"}
+{"id": "", "title": "[Private video]"}
+`))
+		return err
+	}
+	t.Cleanup(func() { runCommand = original })
+
+	videoIDs, err := GetPlaylistVideoIDs(context.Background(), "PL123", 51)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aaaaaaaaaaa", "bbbbbbbbbbb"}, videoIDs)
+	assert.Contains(t, gotArgs, "--flat-playlist")
+	assert.Contains(t, gotArgs, "51")
+	assert.Contains(t, gotArgs, "https://www.youtube.com/playlist?list=PL123")
+
+	_, err = GetPlaylistVideoIDs(context.Background(), "PL1 --exec", 51)
+	assert.Error(t, err)
+}
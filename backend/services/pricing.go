@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TokenUsage counts the tokens the OpenAI API billed for a summary
+type TokenUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+}
+
+// Add adds other's tokens to u
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// TotalTokens returns the prompt and completion tokens combined
+func (u TokenUsage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// ModelPrice is the USD price per 1,000 tokens of a model
+type ModelPrice struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// ModelPricing parses OPENAI_MODEL_PRICING, a comma separated list of model:input/output entries
+// giving the USD price per 1,000 prompt and completion tokens (e.g. "gpt-4o-mini:0.00015/0.0006").
+// Invalid entries are skipped with a warning.
+func ModelPricing() map[string]ModelPrice {
+	pricing := make(map[string]ModelPrice)
+	for _, entry := range strings.Split(os.Getenv("OPENAI_MODEL_PRICING"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		price, model, err := parseModelPrice(entry)
+		if err != nil {
+			LogWarn("Ignoring OPENAI_MODEL_PRICING entry %q: %v", entry, err)
+			continue
+		}
+		pricing[model] = price
+	}
+	return pricing
+}
+
+// parseModelPrice parses a single model:input/output pricing entry.
+// The model is everything before the last colon, since fine-tuned model names contain colons.
+func parseModelPrice(entry string) (ModelPrice, string, error) {
+	sep := strings.LastIndex(entry, ":")
+	if sep <= 0 {
+		return ModelPrice{}, "", fmt.Errorf("expected model:input/output")
+	}
+	model := strings.TrimSpace(entry[:sep])
+	input, output, ok := strings.Cut(entry[sep+1:], "/")
+	if !ok {
+		return ModelPrice{}, "", fmt.Errorf("expected input/output prices")
+	}
+
+	var price ModelPrice
+	var err error
+	if price.InputPer1K, err = strconv.ParseFloat(strings.TrimSpace(input), 64); err != nil || price.InputPer1K < 0 {
+		return ModelPrice{}, "", fmt.Errorf("invalid input price %q", input)
+	}
+	if price.OutputPer1K, err = strconv.ParseFloat(strings.TrimSpace(output), 64); err != nil || price.OutputPer1K < 0 {
+		return ModelPrice{}, "", fmt.Errorf("invalid output price %q", output)
+	}
+	return price, model, nil
+}
+
+// EstimateCost returns the estimated USD cost of usage with the given model.
+// Models missing from OPENAI_MODEL_PRICING cost 0 and ok is false, so only their tokens are recorded.
+func EstimateCost(model string, usage TokenUsage) (cost float64, ok bool) {
+	price, ok := ModelPricing()[model]
+	if !ok {
+		return 0, false
+	}
+	return float64(usage.PromptTokens)/1000*price.InputPer1K + float64(usage.CompletionTokens)/1000*price.OutputPer1K, true
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelPricing(t *testing.T) {
+	t.Setenv("OPENAI_MODEL_PRICING", "gpt-4o-mini:0.00015/0.0006, ft:gpt-4o-mini:org:custom:0.0003/0.0012,broken:1,bad:x/1")
+
+	pricing := ModelPricing()
+	assert.Len(t, pricing, 2)
+	assert.Equal(t, ModelPrice{InputPer1K: 0.00015, OutputPer1K: 0.0006}, pricing["gpt-4o-mini"])
+	assert.Equal(t, ModelPrice{InputPer1K: 0.0003, OutputPer1K: 0.0012}, pricing["ft:gpt-4o-mini:org:custom"])
+}
+
+func TestEstimateCost(t *testing.T) {
+	t.Setenv("OPENAI_MODEL_PRICING", "gpt-4o-mini:0.5/2")
+
+	cost, ok := EstimateCost("gpt-4o-mini", TokenUsage{PromptTokens: 2000, CompletionTokens: 500})
+	assert.True(t, ok)
+	assert.InDelta(t, 2.0, cost, 1e-9)
+
+	cost, ok = EstimateCost("unknown-model", TokenUsage{PromptTokens: 2000, CompletionTokens: 500})
+	assert.False(t, ok, "unknown models are recorded with tokens only")
+	assert.Zero(t, cost)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TranscriptToSRT formats transcript items as a SubRip (.srt) file: a sequential cue number,
+// the HH:MM:SS,mmm --> HH:MM:SS,mmm timing computed from Start and Duration, and the text
+func TranscriptToSRT(items []TranscriptItem) string {
+	var b strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatSubtitleTimestamp(item.Start, ','), formatSubtitleTimestamp(item.Start+item.Duration, ','), subtitleText(item.Text))
+	}
+	return b.String()
+}
+
+// TranscriptToVTT formats transcript items as a WebVTT (.vtt) file with HH:MM:SS.mmm timings
+func TranscriptToVTT(items []TranscriptItem) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+			formatSubtitleTimestamp(item.Start, '.'), formatSubtitleTimestamp(item.Start+item.Duration, '.'), subtitleText(item.Text))
+	}
+	return b.String()
+}
+
+// formatSubtitleTimestamp formats seconds as HH:MM:SS followed by sep and milliseconds,
+// rounded to the nearest millisecond
+func formatSubtitleTimestamp(seconds float64, sep byte) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// subtitleText keeps a cue's text from ending the cue early: blank lines separate cues in both formats
+func subtitleText(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
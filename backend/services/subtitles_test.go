@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptToSRT(t *testing.T) {
+	items := []TranscriptItem{
+		{Text: "Hello", Start: 0, Duration: 0.999},
+		{Text: "world", Start: 0.999, Duration: 1.0015},
+		{Text: "line one\n\nline two", Start: 3599.5, Duration: 61.25},
+	}
+
+	assert.Equal(t, `1
+00:00:00,000 --> 00:00:00,999
+Hello
+
+2
+00:00:00,999 --> 00:00:02,001
+world
+
+3
+00:59:59,500 --> 01:01:00,750
+line one
+line two
+
+`, TranscriptToSRT(items))
+	assert.Empty(t, TranscriptToSRT(nil))
+}
+
+func TestTranscriptToVTT(t *testing.T) {
+	items := []TranscriptItem{
+		{Text: "Hello", Start: 1.5, Duration: 2},
+		{Text: "world", Start: 3.5, Duration: 0.0004},
+	}
+
+	assert.Equal(t, `WEBVTT
+
+00:00:01.500 --> 00:00:03.500
+Hello
+
+00:00:03.500 --> 00:00:03.500
+world
+
+`, TranscriptToVTT(items))
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Summary styles selectable per request. Each maps to its own system prompt.
+const (
+	StyleTimestamped = "timestamped" // Topics with [MM:SS] start times (SummarizationPrompt)
+	StyleTLDR        = "tldr"        // A short paragraph per chunk
+	StyleBullets     = "bullets"     // Key points without timestamps
+	StyleDetailed    = "detailed"    // Timestamped topics with more detail per topic
+)
+
+// DefaultSummaryStyle is the style summaries are written in unless another one is requested
+const DefaultSummaryStyle = StyleTimestamped
+
+// tldrPrompt asks for a short paragraph instead of a structured summary
+const tldrPrompt = `# YouTube Video TL;DR Writer
+
+## Role
+You summarize YouTube video transcripts into a short TL;DR paragraph in Korean, avoiding previously summarized content.
+
+## Rules
+1. Output a single paragraph of at most 4 sentences - no headings, lists, timestamps or introductions
+2. State the main message first, then the most important supporting points
+3. All content in Korean
+4. Never repeat previously summarized content
+5. Check conversation history before summarizing`
+
+// bulletsPrompt asks for a flat list of key points without timestamps
+const bulletsPrompt = `# YouTube Video Key Points
+
+## Role
+You extract the key points of YouTube video transcripts as a bullet list in Korean, avoiding previously summarized content.
+
+## Output Format
+- Key point 1
+- Key point 2
+
+## Rules
+1. Only output the bullet list (-) - no headings, timestamps, introductions or extra comments
+2. One point per bullet, at most 2 sentences each
+3. All content in Korean
+4. Include only essential information
+5. Never repeat previously summarized content
+6. Check conversation history before summarizing`
+
+// detailedPrompt keeps the timestamped format but asks for more depth per topic
+const detailedPrompt = SummarizationPrompt + `
+9. Be thorough: give 3-6 key points per topic, including concrete examples, numbers, names and conclusions mentioned in the video`
+
+// summaryStylePrompts maps each style to its system prompt
+var summaryStylePrompts = map[string]string{
+	StyleTimestamped: SummarizationPrompt,
+	StyleTLDR:        tldrPrompt,
+	StyleBullets:     bulletsPrompt,
+	StyleDetailed:    detailedPrompt,
+}
+
+// SummaryStyles returns the names of the supported summary styles, sorted
+func SummaryStyles() []string {
+	styles := make([]string, 0, len(summaryStylePrompts))
+	for style := range summaryStylePrompts {
+		styles = append(styles, style)
+	}
+	sort.Strings(styles)
+	return styles
+}
+
+// NormalizeSummaryStyle lowercases and validates a style name. An empty name means DefaultSummaryStyle.
+func NormalizeSummaryStyle(style string) (string, error) {
+	style = strings.ToLower(strings.TrimSpace(style))
+	if style == "" {
+		return DefaultSummaryStyle, nil
+	}
+	if _, ok := summaryStylePrompts[style]; !ok {
+		return "", fmt.Errorf("unknown summary style %q", style)
+	}
+	return style, nil
+}
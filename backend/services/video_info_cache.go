@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultVideoInfoCacheTTLSeconds keeps metadata short-lived since view counts and titles can change
+const defaultVideoInfoCacheTTLSeconds = 300
+
+// videoInfoEntry is a cached GetVideoInfo result, or an in-flight lookup other callers can wait on
+type videoInfoEntry struct {
+	done    chan struct{} // Closed once info/err are set
+	info    *VideoInfo
+	err     error
+	expires time.Time
+}
+
+var (
+	videoInfoCacheMutex sync.Mutex
+	videoInfoCache      = make(map[string]*videoInfoEntry)
+)
+
+// videoInfoCacheTTL returns the VideoInfo cache lifetime configured via VIDEOINFO_CACHE_TTL_SECONDS.
+// A value of 0 disables the cache.
+func videoInfoCacheTTL() time.Duration {
+	seconds := GetEnvInt("VIDEOINFO_CACHE_TTL_SECONDS", defaultVideoInfoCacheTTLSeconds)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetVideoInfoCached returns video metadata, reusing a result fetched within VIDEOINFO_CACHE_TTL_SECONDS.
+// Concurrent lookups for the same video share a single yt-dlp call. Failed lookups are not cached.
+// The shared call is not canceled with ctx, since other callers may be waiting for it; a caller whose
+// ctx is done stops waiting and gets ctx's error.
+func GetVideoInfoCached(ctx context.Context, videoID string) (*VideoInfo, error) {
+	ttl := videoInfoCacheTTL()
+	if ttl == 0 {
+		return GetVideoInfo(ctx, videoID)
+	}
+
+	videoInfoCacheMutex.Lock()
+	if entry, ok := videoInfoCache[videoID]; ok {
+		select {
+		case <-entry.done:
+			if entry.err == nil && time.Now().Before(entry.expires) {
+				videoInfoCacheMutex.Unlock()
+				return copyVideoInfo(entry.info), nil
+			}
+		default:
+			// Another caller is already running yt-dlp for this video; wait for its result
+			videoInfoCacheMutex.Unlock()
+			return waitForVideoInfo(ctx, entry)
+		}
+	}
+
+	entry := &videoInfoEntry{done: make(chan struct{})}
+	videoInfoCache[videoID] = entry
+	videoInfoCacheMutex.Unlock()
+
+	go lookupVideoInfo(context.WithoutCancel(ctx), videoID, entry, ttl)
+	return waitForVideoInfo(ctx, entry)
+}
+
+// lookupVideoInfo runs the shared yt-dlp call of entry. Detached from its callers, it is bounded by
+// twice YTDLP_TIMEOUT_SECONDS, which covers waiting for the yt-dlp rate limit as well as the run.
+func lookupVideoInfo(ctx context.Context, videoID string, entry *videoInfoEntry, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, 2*ytDlpTimeout())
+	defer cancel()
+
+	entry.info, entry.err = GetVideoInfo(ctx, videoID)
+	entry.expires = time.Now().Add(ttl)
+	close(entry.done)
+
+	if entry.err != nil {
+		videoInfoCacheMutex.Lock()
+		if videoInfoCache[videoID] == entry {
+			delete(videoInfoCache, videoID)
+		}
+		videoInfoCacheMutex.Unlock()
+		return
+	}
+	pruneVideoInfoCache()
+}
+
+// waitForVideoInfo returns the result of entry's lookup, or ctx's error if ctx is done first
+func waitForVideoInfo(ctx context.Context, entry *videoInfoEntry) (*VideoInfo, error) {
+	select {
+	case <-entry.done:
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return copyVideoInfo(entry.info), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pruneVideoInfoCache drops expired entries so the map doesn't grow with every video ever looked up
+func pruneVideoInfoCache() {
+	videoInfoCacheMutex.Lock()
+	defer videoInfoCacheMutex.Unlock()
+
+	now := time.Now()
+	for videoID, entry := range videoInfoCache {
+		select {
+		case <-entry.done:
+			if now.After(entry.expires) {
+				delete(videoInfoCache, videoID)
+			}
+		default:
+		}
+	}
+}
+
+// copyVideoInfo returns a copy so callers can't modify the cached value
+func copyVideoInfo(info *VideoInfo) *VideoInfo {
+	copied := *info
+	return &copied
+}
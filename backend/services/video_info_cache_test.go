@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRunCommand replaces runCommand with a fake yt-dlp that writes output to stdout and counts invocations
+func stubRunCommand(t *testing.T, output string) *int {
+	calls := 0
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		calls++
+		_, err := cmd.Stdout.Write([]byte(output))
+		return err
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &calls
+}
+
+func TestGetVideoInfoCachedReusesLookupWithinTTL(t *testing.T) {
+	t.Setenv("VIDEOINFO_CACHE_TTL_SECONDS", "60")
+	calls := stubRunCommand(t, `{"title": "Cached Video", "channel": "Channel", "duration": 42}`)
+
+	first, err := GetVideoInfoCached(context.Background(), "aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.Equal(t, "Cached Video", first.Title)
+	assert.Equal(t, 42, first.Duration)
+
+	second, err := GetVideoInfoCached(context.Background(), "aaaaaaaaaaa")
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, *calls, "second lookup within the TTL should not run yt-dlp again")
+
+	// Different videos are looked up separately
+	_, err = GetVideoInfoCached(context.Background(), "bbbbbbbbbbb")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestGetVideoInfoCachedDisabled(t *testing.T) {
+	t.Setenv("VIDEOINFO_CACHE_TTL_SECONDS", "0")
+	calls := stubRunCommand(t, `{"title": "Uncached Video"}`)
+
+	for i := 0; i < 2; i++ {
+		_, err := GetVideoInfoCached(context.Background(), "ccccccccccc")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestGetVideoInfoCachedSurvivesCancelledCaller(t *testing.T) {
+	t.Setenv("VIDEOINFO_CACHE_TTL_SECONDS", "60")
+	videoInfoCacheMutex.Lock()
+	videoInfoCache = make(map[string]*videoInfoEntry)
+	videoInfoCacheMutex.Unlock()
+	started, release := make(chan struct{}), make(chan struct{})
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		close(started)
+		<-release
+		_, err := cmd.Stdout.Write([]byte(`{"title": "Shared Video"}`))
+		return err
+	}
+	t.Cleanup(func() { runCommand = original })
+
+	// The first caller starts the lookup and is cancelled while it runs, e.g. because its job was abandoned
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := GetVideoInfoCached(ctx, "ddddddddddd")
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan *VideoInfo, 1)
+	go func() {
+		info, err := GetVideoInfoCached(context.Background(), "ddddddddddd")
+		assert.NoError(t, err)
+		second <- info
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled, "a cancelled caller stops waiting right away")
+	close(release)
+	if info := <-second; assert.NotNil(t, info) {
+		assert.Equal(t, "Shared Video", info.Title, "other callers still get the shared result")
+	}
+}
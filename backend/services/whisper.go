@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// OpenAITranscriptionURL is the default OpenAI audio transcription endpoint
+	OpenAITranscriptionURL = "https://api.openai.com/v1/audio/transcriptions"
+	// TranscriptionModel is the default model for audio transcription
+	TranscriptionModel = "whisper-1"
+	// maxTranscriptionUploadBytes is the largest file the transcription endpoint accepts
+	maxTranscriptionUploadBytes = 25 << 20
+)
+
+// ErrAudioTooLarge is returned when a video's audio exceeds the transcription endpoint's upload limit
+var ErrAudioTooLarge = errors.New("audio is too large to transcribe")
+
+// transcriptionResponse is the verbose_json response of the transcription endpoint
+type transcriptionResponse struct {
+	Text     string  `json:"text"`
+	Duration float64 `json:"duration"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+// TranscribeVideoAudio downloads a video's audio with yt-dlp and transcribes it with OpenAI's audio
+// transcription endpoint, for videos without captions (ENABLE_WHISPER_FALLBACK). The segments become
+// transcript items split into chunks like GetTranscript's. The key is resolved like for summaries.
+func TranscribeVideoAudio(ctx context.Context, videoID string, chunkSize float64, userAPIKey, userID string) ([][]TranscriptItem, error) {
+	ctx, span := StartSpan(ctx, "audio transcription", VideoIDAttr(videoID))
+	items, err := transcribeVideoAudio(ctx, videoID, userAPIKey, userID)
+	EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return chunkTranscriptItems(items, chunkSize, transcriptChunkOverlap(chunkSize)), nil
+}
+
+func transcribeVideoAudio(ctx context.Context, videoID, userAPIKey, userID string) ([]TranscriptItem, error) {
+	// Validate the video ID to prevent command injection
+	videoID, err := NormalizeVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := resolveOpenAIKey(userAPIKey, userID)
+	if apiKey == "" {
+		return nil, errors.New("no valid OpenAI API key available")
+	}
+
+	tempDir, err := os.MkdirTemp("", "yt-audio-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir) // The audio file can be tens of megabytes
+
+	audioPath, err := downloadAudio(ctx, CanonicalVideoURL(videoID), tempDir)
+	if err != nil {
+		return nil, err
+	}
+	return requestTranscription(ctx, audioPath, apiKey)
+}
+
+// downloadAudio extracts a video's audio as a low-bitrate MP3 into dir and returns the file path.
+// Speech stays intelligible at the lowest quality, which keeps long videos under the upload limit.
+func downloadAudio(ctx context.Context, videoURL, dir string) (string, error) {
+	if err := waitForYtDlp(ctx); err != nil {
+		return "", err
+	}
+	args := append(ytDlpOptionArgs(),
+		"-x",
+		"--audio-format", "mp3",
+		"--audio-quality", "9",
+		"--no-playlist",
+		"--paths", dir,
+		"-o", "%(id)s.%(ext)s",
+		videoURL,
+	)
+	var stderr bytes.Buffer
+	if err := runYtDlp(ctx, args, nil, &stderr); err != nil {
+		return "", fmt.Errorf("yt-dlp failed to download audio: %w - %s", err, stderr.String())
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.mp3"))
+	if err != nil || len(files) == 0 {
+		return "", errors.New("no audio file was downloaded")
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxTranscriptionUploadBytes {
+		return "", fmt.Errorf("%w (%d bytes, limit %d bytes)", ErrAudioTooLarge, info.Size(), maxTranscriptionUploadBytes)
+	}
+	return files[0], nil
+}
+
+// requestTranscription uploads an audio file to the transcription endpoint configured via
+// OPENAI_TRANSCRIPTION_URL and OPENAI_TRANSCRIPTION_MODEL and converts the segments to transcript items
+func requestTranscription(ctx context.Context, audioPath, apiKey string) ([]TranscriptItem, error) {
+	apiURL := os.Getenv("OPENAI_TRANSCRIPTION_URL")
+	if apiURL == "" {
+		apiURL = OpenAITranscriptionURL
+	}
+	model := os.Getenv("OPENAI_TRANSCRIPTION_MODEL")
+	if model == "" {
+		model = TranscriptionModel
+	}
+
+	audio, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer audio.Close()
+
+	// The file is streamed into the request body instead of being read into memory first
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeTranscriptionForm(form, audio, filepath.Base(audioPath), model))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := openAIClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, &APIStatusError{StatusCode: resp.StatusCode, Body: string(respBody), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	var transcription transcriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transcription); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription response: %v", err)
+	}
+
+	var items []TranscriptItem
+	for _, segment := range transcription.Segments {
+		if text := cleanTranscriptText(segment.Text); text != "" {
+			items = append(items, TranscriptItem{Text: text, Start: segment.Start, Duration: max(segment.End-segment.Start, 0)})
+		}
+	}
+	if len(items) == 0 {
+		// Models without segment support only return the text; it then covers the whole audio
+		if text := cleanTranscriptText(transcription.Text); text != "" {
+			items = append(items, TranscriptItem{Text: text, Start: 0, Duration: transcription.Duration})
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: the transcribed audio contains no speech", ErrNoTranscript)
+	}
+	return items, nil
+}
+
+// writeTranscriptionForm writes the multipart form of a transcription request
+func writeTranscriptionForm(form *multipart.Writer, audio io.Reader, fileName, model string) error {
+	fields := [][2]string{
+		{"model", model},
+		{"response_format", "verbose_json"},
+		{"timestamp_granularities[]", "segment"},
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return err
+	}
+	return form.Close()
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAudioDownload makes yt-dlp "download" an audio file of the given size into its --paths directory
+func stubAudioDownload(t *testing.T, size int) *string {
+	t.Helper()
+	var dir string
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		i := slices.Index(cmd.Args, "--paths")
+		dir = cmd.Args[i+1]
+		return os.WriteFile(filepath.Join(dir, "ddddddddddd.mp3"), make([]byte, size), 0644)
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &dir
+}
+
+func TestTranscribeVideoAudio(t *testing.T) {
+	audioDir := stubAudioDownload(t, 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-user", r.Header.Get("Authorization"))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "verbose_json", r.FormValue("response_format"))
+		file, header, err := r.FormFile("file")
+		if assert.NoError(t, err) {
+			data, _ := io.ReadAll(file)
+			assert.Len(t, data, 1024)
+			assert.Equal(t, "ddddddddddd.mp3", header.Filename)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text": "Hello there. Second part.", "duration": 70, "segments": [
+			{"start": 0, "end": 4.5, "text": " Hello there."},
+			{"start": 65, "end": 70, "text": " Second part."}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_TRANSCRIPTION_URL", server.URL)
+
+	chunks, err := TranscribeVideoAudio(context.Background(), "ddddddddddd", 60, "sk-user", "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]TranscriptItem{
+		{{Text: "Hello there.", Start: 0, Duration: 4.5}},
+		{{Text: "Second part.", Start: 65, Duration: 5}},
+	}, chunks)
+	assert.NoDirExists(t, *audioDir, "the downloaded audio is removed")
+}
+
+func TestTranscribeVideoAudioRejectsOversizedAudio(t *testing.T) {
+	stubAudioDownload(t, maxTranscriptionUploadBytes+1)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_TRANSCRIPTION_URL", server.URL)
+
+	_, err := TranscribeVideoAudio(context.Background(), "ddddddddddd", 60, "sk-user", "user1")
+	assert.ErrorIs(t, err, ErrAudioTooLarge)
+	assert.Zero(t, requests, "the audio is not uploaded")
+}
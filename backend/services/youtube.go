@@ -1,16 +1,27 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/akirose/youtube-summarizer/i18n"
 )
 
 // VideoInfo holds basic information about a YouTube video
@@ -18,8 +29,19 @@ type VideoInfo struct {
 	ID         string
 	Title      string
 	Channel    string
-	UploadDate string
+	ChannelID  string
+	UploadDate string // YYYYMMDD, as reported by yt-dlp
 	Duration   int
+	Thumbnail  string         // URL of the video's thumbnail image
+	Captions   []CaptionTrack // Available subtitle tracks, manual ones first
+	Chapters   []Chapter      // Creator-defined chapters, sorted by start time; empty if the video has none
+}
+
+// CaptionTrack describes a subtitle track available for a video
+type CaptionTrack struct {
+	Language string `json:"language"`       // Language code as used by yt-dlp's --sub-langs (e.g. "ko", "en-US")
+	Name     string `json:"name,omitempty"` // Human readable language name, if yt-dlp reports one
+	Auto     bool   `json:"auto"`           // Auto-generated (or auto-translated) captions rather than uploaded subtitles
 }
 
 // TranscriptItem represents a single transcript item with text and timestamp
@@ -29,58 +51,172 @@ type TranscriptItem struct {
 	Duration float64 `json:"duration"`
 }
 
-// GetVideoID extracts the video ID from a YouTube URL
+// runCommand executes an external command such as yt-dlp. Tests replace it to avoid spawning processes.
+var runCommand = func(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+// validVideoIDPattern matches the 11 character IDs YouTube assigns to videos
+var validVideoIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{11}$`)
+
+// IsValidVideoID reports whether videoID has the format of a YouTube video ID
+func IsValidVideoID(videoID string) bool {
+	return validVideoIDPattern.MatchString(videoID)
+}
+
+// NormalizeVideoID trims surrounding whitespace from a video ID and validates its format.
+// Every video ID used as a cache, dedup or user-summary key goes through it, so the same
+// video always maps to the same key. IDs are case-sensitive, so the case is left as is.
+func NormalizeVideoID(videoID string) (string, error) {
+	videoID = strings.TrimSpace(videoID)
+	if !IsValidVideoID(videoID) {
+		return "", errors.New("invalid video ID format")
+	}
+	return videoID, nil
+}
+
+// CanonicalVideoURL returns the standard watch URL for a video ID
+func CanonicalVideoURL(videoID string) string {
+	return fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
+}
+
+// InvalidVideoURLError is returned by GetVideoID for URLs that don't point to a YouTube video.
+// Reason explains what is wrong in a form that can be shown to users; Code and Args give it in the
+// i18n catalog, so it can be shown in the user's language.
+type InvalidVideoURLError struct {
+	URL    string
+	Reason string
+	Code   string
+	Args   []any
+}
+
+// newInvalidVideoURLError returns an *InvalidVideoURLError with the reason of an i18n code
+func newInvalidVideoURLError(videoURL, code string, args ...any) *InvalidVideoURLError {
+	return &InvalidVideoURLError{URL: videoURL, Reason: i18n.T(code, i18n.English, args...), Code: code, Args: args}
+}
+
+func (e *InvalidVideoURLError) Error() string {
+	return "invalid YouTube URL: " + e.Reason
+}
+
+// Localize returns the reason in lang
+func (e *InvalidVideoURLError) Localize(lang string) string {
+	return i18n.T(e.Code, lang, e.Args...)
+}
+
+// videoURLPatterns match the YouTube URL formats GetVideoID understands
+// (m.youtube.com, www.youtube.com and music.youtube.com are covered by the youtube.com patterns)
+var videoURLPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:youtube\.com\/watch\?v=|youtu.be\/)([^&\?\/#]+)`),
+	regexp.MustCompile(`youtube\.com\/watch\?(?:[^#]*&)?v=([^&#]+)`),
+	regexp.MustCompile(`youtube\.com\/embed\/([^\/\?#]+)`),
+	regexp.MustCompile(`youtube\.com\/v\/([^\/\?#]+)`),
+	regexp.MustCompile(`youtube\.com\/(?:shorts|live)\/([^\/\?&#]+)`),
+}
+
+// youtubeHosts are the hosts GetVideoID accepts video URLs from
+var youtubeHosts = map[string]bool{
+	"youtube.com":       true,
+	"www.youtube.com":   true,
+	"m.youtube.com":     true,
+	"music.youtube.com": true,
+	"youtu.be":          true,
+}
+
+// parseYouTubeURL parses a video URL and checks that it is an http(s) URL of a YouTube host, so that
+// the ID patterns only ever see YouTube paths and not e.g. a youtube.com path on another host.
+// URLs without a scheme are treated as https. It returns the URL as host, path and query.
+func parseYouTubeURL(videoURL string) (string, *InvalidVideoURLError) {
+	original := videoURL
+	if !strings.Contains(videoURL, "://") {
+		videoURL = "https://" + videoURL
+	}
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return "", newInvalidVideoURLError(original, i18n.MsgVideoURLMalformed)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", newInvalidVideoURLError(original, i18n.MsgVideoURLScheme, parsed.Scheme)
+	}
+	if parsed.User != nil {
+		return "", newInvalidVideoURLError(original, i18n.MsgVideoURLCredentials)
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if !youtubeHosts[host] {
+		return "", newInvalidVideoURLError(original, i18n.MsgVideoURLHost, host)
+	}
+
+	target := host + parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		target += "?" + parsed.RawQuery
+	}
+	return target, nil
+}
+
+// GetVideoID extracts the video ID from a YouTube URL. Extra query parameters such as the
+// ?si= share-tracking suffix are ignored. URLs of other hosts or with other schemes than http(s)
+// are rejected before the ID is extracted. Failures return an *InvalidVideoURLError.
 func GetVideoID(videoURL string) (string, error) {
-	// Regular expressions for different YouTube URL formats
-	patterns := []string{
-		`(?:youtube\.com\/watch\?v=|youtu.be\/)([^&\?\/]+)`,
-		`youtube\.com\/embed\/([^\/\?]+)`,
-		`youtube\.com\/v\/([^\/\?]+)`,
+	videoURL = strings.TrimSpace(videoURL)
+	if videoURL == "" {
+		return "", newInvalidVideoURLError(videoURL, i18n.MsgVideoURLEmpty)
+	}
+	target, urlErr := parseYouTubeURL(videoURL)
+	if urlErr != nil {
+		return "", urlErr
 	}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(videoURL)
+	for _, re := range videoURLPatterns {
+		matches := re.FindStringSubmatch(target)
 		if len(matches) > 1 {
-			return matches[1], nil
+			videoID, err := NormalizeVideoID(matches[1])
+			if err != nil {
+				return "", newInvalidVideoURLError(videoURL, i18n.MsgVideoURLVideoID, matches[1])
+			}
+			return videoID, nil
 		}
 	}
 
-	return "", errors.New("invalid YouTube URL")
+	return "", newInvalidVideoURLError(videoURL, i18n.MsgVideoURLNotVideo)
 }
 
 // GetVideoInfo fetches basic information about a YouTube video using yt-dlp
-func GetVideoInfo(videoID string) (*VideoInfo, error) {
+func GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	_, span := StartSpan(ctx, "yt-dlp video info", VideoIDAttr(videoID))
+	if err := waitForYtDlp(ctx); err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+	info, err := getVideoInfo(ctx, videoID)
+	EndSpan(span, err)
+	return info, err
+}
+
+func getVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
 	// Validate the video ID to prevent command injection
-	validIDPattern := regexp.MustCompile(`^[a-zA-Z0-9_-]{11}$`)
-	if !validIDPattern.MatchString(videoID) {
-		return nil, errors.New("invalid video ID format")
+	videoID, err := NormalizeVideoID(videoID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Construct YouTube URL from video ID
-	videoURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
+	videoURL := CanonicalVideoURL(videoID)
 
 	// Prepare yt-dlp command to get video info in JSON format
-	cmd := exec.Command(
-		"yt-dlp",
+	args := append(ytDlpOptionArgs(),
 		"--dump-json",
 		"--no-playlist",
 		"--skip-download",
 		videoURL,
 	)
-
-	// Capture stdout
+	// Capture stdout and stderr
 	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	// Capture stderr
 	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
 
 	// Run the command
-	err := cmd.Run()
+	err = runYtDlp(ctx, args, &out, &stderr)
 	if err != nil {
-		return nil, fmt.Errorf("yt-dlp error: %v - %s", err, stderr.String())
+		return nil, fmt.Errorf("yt-dlp error: %w - %s", err, stderr.String())
 	}
 
 	// Parse the JSON output
@@ -93,7 +229,9 @@ func GetVideoInfo(videoID string) (*VideoInfo, error) {
 	// Extract relevant information
 	title, _ := videoData["title"].(string)
 	channel, _ := videoData["channel"].(string)
+	channelID, _ := videoData["channel_id"].(string)
 	uploadDate, _ := videoData["upload_date"].(string)
+	thumbnail, _ := videoData["thumbnail"].(string)
 
 	// Parse duration (can be a string or a float)
 	var duration int
@@ -109,22 +247,77 @@ func GetVideoInfo(videoID string) (*VideoInfo, error) {
 		duration = 0
 	}
 
+	// The same information --list-subs prints as a table, in machine readable form
+	captions := parseCaptionTracks(videoData["subtitles"], false)
+	captions = append(captions, parseCaptionTracks(videoData["automatic_captions"], true)...)
+
 	return &VideoInfo{
 		ID:         videoID,
 		Title:      title,
 		Channel:    channel,
+		ChannelID:  channelID,
 		UploadDate: uploadDate,
 		Duration:   duration,
+		Thumbnail:  thumbnail,
+		Captions:   captions,
+		Chapters:   parseChapters(videoData["chapters"]),
 	}, nil
 }
 
+// parseCaptionTracks converts a yt-dlp "subtitles" or "automatic_captions" map
+// (language code -> list of formats) into caption tracks sorted by language code
+func parseCaptionTracks(data interface{}, auto bool) []CaptionTrack {
+	languages, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var tracks []CaptionTrack
+	for language, formats := range languages {
+		// yt-dlp lists live chat replays as a subtitle track; it isn't a caption
+		if language == "live_chat" {
+			continue
+		}
+
+		track := CaptionTrack{Language: language, Auto: auto}
+		if formatList, ok := formats.([]interface{}); ok {
+			for _, format := range formatList {
+				if formatMap, ok := format.(map[string]interface{}); ok {
+					if name, ok := formatMap["name"].(string); ok && name != "" {
+						track.Name = name
+						break
+					}
+				}
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].Language < tracks[j].Language
+	})
+	return tracks
+}
+
 // GetTranscript fetches the transcript for a YouTube video using yt-dlp
 // Add a new parameter chunkSize to specify the size of each chunk in seconds
-func GetTranscript(videoID string, chunkSize float64) ([][]TranscriptItem, error) {
+// Videos without captions return an error wrapping ErrNoTranscript.
+func GetTranscript(ctx context.Context, videoID string, chunkSize float64) ([][]TranscriptItem, error) {
+	_, span := StartSpan(ctx, "yt-dlp transcript", VideoIDAttr(videoID))
+	if err := waitForYtDlp(ctx); err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+	chunks, err := getTranscript(ctx, videoID, chunkSize)
+	EndSpan(span, err)
+	return chunks, err
+}
+
+func getTranscript(ctx context.Context, videoID string, chunkSize float64) ([][]TranscriptItem, error) {
 	// Validate the video ID to prevent command injection
-	validIDPattern := regexp.MustCompile(`^[a-zA-Z0-9_-]{11}$`)
-	if !validIDPattern.MatchString(videoID) {
-		return nil, errors.New("invalid video ID format")
+	videoID, err := NormalizeVideoID(videoID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create a temporary directory for subtitle files
@@ -135,147 +328,468 @@ func GetTranscript(videoID string, chunkSize float64) ([][]TranscriptItem, error
 	defer os.RemoveAll(tempDir) // Clean up temp directory when done
 
 	// Construct YouTube URL from video ID
-	videoURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
-
-	// Prepare yt-dlp command to get subtitles
-	cmd := exec.Command(
-		"yt-dlp",
-		"--write-sub",       // Try to get manual subtitles
-		"--write-auto-sub",  // Get auto-generated subtitles if no manual subs available
-		"--sub-langs", "ko", // Prioritize Korean subtitles
+	videoURL := CanonicalVideoURL(videoID)
+
+	langs := TranscriptLanguages()
+
+	if GetEnvBool("MERGE_SUBTITLE_TRACKS", false) {
+		items, err := getMergedSubtitleTracks(ctx, videoURL, tempDir, langs)
+		if err != nil {
+			return nil, err
+		}
+		return chunkTranscriptItems(items, chunkSize, transcriptChunkOverlap(chunkSize)), nil
+	}
+
+	if err := downloadSubtitles(ctx, videoURL, tempDir, true, true, langs); err != nil {
+		return nil, err
+	}
+
+	// Process subtitle files and split them into chunks
+	chunks, lang, err := processSubtitleFiles(tempDir, chunkSize, langs)
+	if err != nil && !errors.Is(err, ErrSubtitleTooLarge) {
+		// None of the requested languages exist; use the captions YouTube generated in the spoken language
+		fallbackDir := filepath.Join(tempDir, "fallback")
+		if mkErr := os.MkdirAll(fallbackDir, 0755); mkErr != nil {
+			return nil, err
+		}
+		if dlErr := downloadSubtitles(ctx, videoURL, fallbackDir, false, true, []string{originalAutoCaptionLangs}); dlErr != nil {
+			return nil, err
+		}
+		chunks, lang, err = processSubtitleFiles(fallbackDir, chunkSize, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lang != langs[0] {
+		LogWarn("Transcript for %s uses subtitle language %q (requested %s)", videoID, lang, strings.Join(langs, ","))
+	}
+	return chunks, nil
+}
+
+// defaultTranscriptLanguage is the subtitle language used when TRANSCRIPT_LANGS is not set
+const defaultTranscriptLanguage = "ko"
+
+// originalAutoCaptionLangs selects the auto-generated captions in the video's spoken language
+const originalAutoCaptionLangs = ".*-orig"
+
+// TranscriptLanguages returns the subtitle languages to try, most preferred first,
+// as configured via TRANSCRIPT_LANGS (e.g. "en,ko,ja")
+func TranscriptLanguages() []string {
+	var langs []string
+	for _, lang := range strings.Split(os.Getenv("TRANSCRIPT_LANGS"), ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	if len(langs) == 0 {
+		langs = []string{defaultTranscriptLanguage}
+	}
+	return langs
+}
+
+// downloadSubtitles runs yt-dlp to save the video's subtitles into dir.
+// manual and auto select manual subtitles and auto-generated captions respectively.
+// langs is passed to --sub-langs; yt-dlp downloads every listed language that exists.
+func downloadSubtitles(ctx context.Context, videoURL, dir string, manual, auto bool, langs []string) error {
+	args := ytDlpOptionArgs()
+	if manual {
+		args = append(args, "--write-sub") // Try to get manual subtitles
+	}
+	if auto {
+		args = append(args, "--write-auto-sub") // Get auto-generated subtitles if no manual subs available
+	}
+	args = append(args,
+		"--sub-langs", strings.Join(langs, ","), // Languages to try; the best match is picked after download
 		"--skip-download",     // Don't download the video
 		"--sub-format", "vtt", // Get WebVTT format
-		"--paths", tempDir, // Save subtitle files to our temp directory
-		"-o '%(id)s.%(ext)s'",
+		"--paths", dir, // Save subtitle files to the given directory
+		"-o", "%(id)s.%(ext)s", // Subtitle files are named <id>.<lang>.vtt
 		videoURL,
 	)
 
 	// Capture stderr
 	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
 
-	// Run the command
-	err = cmd.Run()
-	if err != nil {
-		return nil, fmt.Errorf("yt-dlp failed to download subtitles: %v - %s", err, stderr.String())
+	// Run yt-dlp to get subtitles
+	if err := runYtDlp(ctx, args, nil, &stderr); err != nil {
+		return fmt.Errorf("yt-dlp failed to download subtitles: %w - %s", err, stderr.String())
 	}
-
-	// Process subtitle files and split them into chunks
-	return processSubtitleFiles(tempDir, chunkSize)
+	return nil
 }
 
-// Extracts and processes subtitle files from a temporary directory
-func processSubtitleFiles(tempDir string, chunkSize float64) ([][]TranscriptItem, error) {
-	// Read files from the temp directory
-	files, err := os.ReadDir(tempDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read temp directory: %v", err)
+// getMergedSubtitleTracks downloads the manual and auto-generated tracks separately and merges them,
+// using the manual track wherever it has coverage and the auto track to fill the gaps.
+func getMergedSubtitleTracks(ctx context.Context, videoURL, tempDir string, langs []string) ([]TranscriptItem, error) {
+	manualDir := filepath.Join(tempDir, "manual")
+	autoDir := filepath.Join(tempDir, "auto")
+	for _, dir := range []string{manualDir, autoDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %v", err)
+		}
 	}
 
-	if len(files) == 0 {
-		return nil, errors.New("no subtitle files were downloaded")
+	if err := downloadSubtitles(ctx, videoURL, manualDir, true, false, langs); err != nil {
+		return nil, err
+	}
+	if err := downloadSubtitles(ctx, videoURL, autoDir, false, true, langs); err != nil {
+		return nil, err
 	}
 
-	// Process each subtitle file and collect transcript items
-	var allTranscriptItems []TranscriptItem
-	for _, file := range files {
-		// Only process .vtt files
-		if !strings.HasSuffix(file.Name(), ".vtt") {
+	// A missing track is fine as long as the other one has content
+	manualItems, _, err := readSubtitleItems(manualDir, langs)
+	if errors.Is(err, ErrSubtitleTooLarge) {
+		return nil, err
+	}
+	autoItems, _, err := readSubtitleItems(autoDir, langs)
+	if errors.Is(err, ErrSubtitleTooLarge) {
+		return nil, err
+	}
+
+	merged := mergeSubtitleTracks(manualItems, autoItems)
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("%w: no subtitle files were downloaded", ErrNoTranscript)
+	}
+	return merged, nil
+}
+
+// subtitleCoverageGap is the longest pause between manual subtitle cues that still counts as covered,
+// so ordinary pauses in speech are not filled with auto-generated captions.
+const subtitleCoverageGap = 5.0
+
+// mergeSubtitleTracks combines a (possibly partial) manual track with a full auto-generated track.
+// Manual items are always kept; auto items are only added where the manual track has no coverage.
+func mergeSubtitleTracks(manual, auto []TranscriptItem) []TranscriptItem {
+	if len(manual) == 0 {
+		return auto
+	}
+	if len(auto) == 0 {
+		return manual
+	}
+
+	SortTranscriptItemsByTime(manual)
+
+	// Build the time ranges covered by the manual track
+	type interval struct{ start, end float64 }
+	var covered []interval
+	for _, item := range manual {
+		end := item.Start + item.Duration
+		n := len(covered)
+		if n > 0 && item.Start-covered[n-1].end <= subtitleCoverageGap {
+			if end > covered[n-1].end {
+				covered[n-1].end = end
+			}
 			continue
 		}
+		covered = append(covered, interval{item.Start, end})
+	}
 
-		// Read the subtitle file
-		filePath := fmt.Sprintf("%s/%s", tempDir, file.Name())
-		subtitleData, err := os.ReadFile(filePath)
-		if err != nil {
-			continue // Skip files we can't read
+	merged := append([]TranscriptItem{}, manual...)
+	for _, item := range auto {
+		mid := item.Start + item.Duration/2
+		isCovered := false
+		for _, iv := range covered {
+			if mid >= iv.start && mid <= iv.end {
+				isCovered = true
+				break
+			}
+		}
+		if !isCovered {
+			merged = append(merged, item)
 		}
+	}
+
+	SortTranscriptItemsByTime(merged)
+	return merged
+}
 
-		// Process the VTT content
-		transcriptItems := parseVttContent(string(subtitleData))
-		allTranscriptItems = append(allTranscriptItems, transcriptItems...)
+// Extracts and processes the best matching subtitle file from a temporary directory.
+// It returns the transcript chunks and the language of the file they came from.
+func processSubtitleFiles(tempDir string, chunkSize float64, langs []string) ([][]TranscriptItem, string, error) {
+	allTranscriptItems, lang, err := readSubtitleItems(tempDir, langs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return chunkTranscriptItems(allTranscriptItems, chunkSize, transcriptChunkOverlap(chunkSize)), lang, nil
+}
+
+// readSubtitleItems parses the .vtt file in dir that best matches langs into transcript items
+// sorted by start time, and returns the language of that file.
+// Only one file is used, so captions are not duplicated when several languages were downloaded.
+func readSubtitleItems(dir string, langs []string) ([]TranscriptItem, string, error) {
+	fileName, lang, err := selectSubtitleFile(dir, langs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Stream the subtitle file so a pathological download can't exhaust memory
+	limit := maxSubtitleBytes()
+	f, err := os.Open(filepath.Join(dir, fileName))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open subtitle file: %v", err)
+	}
+	defer f.Close()
+
+	allTranscriptItems, err := parseVtt(&limitedReader{r: f, budget: &subtitleBudget{remaining: limit}})
+	if errors.Is(err, ErrSubtitleTooLarge) {
+		return nil, "", fmt.Errorf("%w (limit %d bytes, MAX_SUBTITLE_BYTES)", err, limit)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read subtitle file: %v", err)
 	}
 
 	// Check if we actually got any transcript items
 	if len(allTranscriptItems) == 0 {
-		return nil, errors.New("no usable transcript entries were found")
+		return nil, "", fmt.Errorf("%w: no usable transcript entries were found", ErrNoTranscript)
 	}
 
 	// Sort transcript items by start time
 	SortTranscriptItemsByTime(allTranscriptItems)
 
+	return allTranscriptItems, lang, nil
+}
+
+// subtitleFileLanguage returns the language code of a subtitle file named <id>.<lang>.vtt
+func subtitleFileLanguage(fileName string) string {
+	name := strings.TrimSuffix(fileName, ".vtt")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// selectSubtitleFile picks the .vtt file in dir whose language comes first in langs.
+// An exact language match wins over a regional or auto-generated variant (e.g. "en-US", "en-orig").
+// If no file matches, the first file is used, e.g. the original-language fallback track.
+func selectSubtitleFile(dir string, langs []string) (string, string, error) {
+	// Read files from the temp directory
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read temp directory: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		// Only process .vtt files
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".vtt") {
+			names = append(names, file.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", "", fmt.Errorf("%w: no subtitle files were downloaded", ErrNoTranscript)
+	}
+	sort.Strings(names)
+
+	for _, want := range langs {
+		want = strings.ToLower(want)
+		for _, name := range names {
+			if strings.ToLower(subtitleFileLanguage(name)) == want {
+				return name, subtitleFileLanguage(name), nil
+			}
+		}
+		for _, name := range names {
+			if strings.HasPrefix(strings.ToLower(subtitleFileLanguage(name)), want+"-") {
+				return name, subtitleFileLanguage(name), nil
+			}
+		}
+	}
+	return names[0], subtitleFileLanguage(names[0]), nil
+}
+
+// transcriptChunkOverlap returns how many seconds of a chunk are repeated at the start of the next one,
+// configured via TRANSCRIPT_CHUNK_OVERLAP_SECONDS (default: 0). The overlap keeps sentences at a chunk
+// boundary in view of both summaries; it must be shorter than the chunk, or chunking would not advance.
+func transcriptChunkOverlap(chunkSize float64) float64 {
+	overlap := float64(GetEnvInt("TRANSCRIPT_CHUNK_OVERLAP_SECONDS", 0))
+	if overlap <= 0 || chunkSize <= 0 {
+		return 0
+	}
+	if overlap >= chunkSize {
+		LogWarn("Ignoring TRANSCRIPT_CHUNK_OVERLAP_SECONDS=%v: it must be shorter than the %v second chunks.", overlap, chunkSize)
+		return 0
+	}
+	return overlap
+}
+
+// chunkTranscriptItems splits sorted transcript items into chunks of chunkSize seconds.
+// A chunkSize of 0 or less returns all items as a single chunk. With an overlap, each chunk
+// after the first starts with the items of the previous chunk's last overlap seconds.
+func chunkTranscriptItems(allTranscriptItems []TranscriptItem, chunkSize, overlap float64) [][]TranscriptItem {
 	if chunkSize <= 0 {
-		return [][]TranscriptItem{allTranscriptItems}, nil
+		return [][]TranscriptItem{allTranscriptItems}
 	}
 
 	// Split transcript items into chunks
 	var chunks [][]TranscriptItem
 	var currentChunk []TranscriptItem
 	var currentChunkStart float64
+	carried := 0 // Items at the start of currentChunk repeated from the previous chunk
 
 	for _, item := range allTranscriptItems {
-		if len(currentChunk) == 0 {
+		if len(currentChunk) == carried {
 			currentChunkStart = item.Start
 		}
 
 		if item.Start-currentChunkStart < chunkSize {
 			currentChunk = append(currentChunk, item)
-		} else {
-			chunks = append(chunks, currentChunk)
-			currentChunk = []TranscriptItem{item}
-			currentChunkStart = item.Start
+			continue
 		}
+		chunks = append(chunks, currentChunk)
+
+		// The chunk length is measured from the first new item, so carried items never delay the next boundary
+		var next []TranscriptItem
+		if overlap > 0 {
+			for _, previous := range currentChunk {
+				if previous.Start >= item.Start-overlap {
+					next = append(next, previous)
+				}
+			}
+		}
+		carried = len(next)
+		currentChunk = append(next, item)
+		currentChunkStart = item.Start
 	}
 
-	// Add the last chunk if it exists
-	if len(currentChunk) > 0 {
+	// Add the last chunk if it has anything besides the carried items
+	if len(currentChunk) > carried {
 		chunks = append(chunks, currentChunk)
 	}
 
-	return chunks, nil
+	return chunks
+}
+
+// MergeTranscriptChunks joins transcript chunks back into one list of items. Items a chunk
+// repeats from the end of the previous chunk (TRANSCRIPT_CHUNK_OVERLAP_SECONDS) appear once.
+func MergeTranscriptChunks(chunks [][]TranscriptItem) []TranscriptItem {
+	var items []TranscriptItem
+	for i, chunk := range chunks {
+		skip := 0
+		if i > 0 {
+			skip = overlappingItems(chunks[i-1], chunk)
+		}
+		items = append(items, chunk[skip:]...)
+	}
+	return items
+}
+
+// overlappingItems returns the length of the longest prefix of chunk that ends previous
+func overlappingItems(previous, chunk []TranscriptItem) int {
+	for n := min(len(previous), len(chunk)); n > 0; n-- {
+		if slices.Equal(previous[len(previous)-n:], chunk[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// defaultMaxSubtitleBytes bounds the subtitle data read for one transcript. Even multi-hour
+// auto captions stay well below this; anything larger is treated as pathological.
+const defaultMaxSubtitleBytes = 50 << 20
+
+// maxVttLineBytes is the longest VTT line accepted
+const maxVttLineBytes = 1 << 20
+
+// ErrNoTranscript is returned when a video has no captions in any of the tried languages
+var ErrNoTranscript = errors.New("no transcript available")
+
+// ErrSubtitleTooLarge is returned when the subtitle files exceed MAX_SUBTITLE_BYTES
+var ErrSubtitleTooLarge = errors.New("subtitle files are too large")
+
+// maxSubtitleBytes returns the subtitle size limit configured via MAX_SUBTITLE_BYTES
+func maxSubtitleBytes() int64 {
+	if n := GetEnvInt("MAX_SUBTITLE_BYTES", defaultMaxSubtitleBytes); n > 0 {
+		return int64(n)
+	}
+	return defaultMaxSubtitleBytes
+}
+
+// subtitleBudget counts the subtitle bytes read for a transcript against a limit shared by all files
+type subtitleBudget struct {
+	remaining int64
+}
+
+// limitedReader wraps r so reading fails with ErrSubtitleTooLarge once the budget is used up
+type limitedReader struct {
+	r      io.Reader
+	budget *subtitleBudget
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.budget.remaining <= 0 {
+		// Distinguish "exactly at the limit" from "over the limit" by probing for more data
+		var probe [1]byte
+		if n, err := l.r.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, ErrSubtitleTooLarge
+	}
+	if int64(len(p)) > l.budget.remaining {
+		p = p[:l.budget.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.budget.remaining -= int64(n)
+	return n, err
 }
 
 // parseVttContent converts VTT content to TranscriptItem array
 func parseVttContent(vttContent string) []TranscriptItem {
-	var transcriptItems []TranscriptItem
+	items, _ := parseVtt(strings.NewReader(vttContent))
+	return items
+}
 
-	// Check if it has at least a basic VTT structure
-	lines := strings.Split(vttContent, "\n")
-	if len(lines) < 4 || !strings.Contains(lines[0], "WEBVTT") {
-		return transcriptItems
-	}
+// parseVtt reads VTT content line by line and converts it to a TranscriptItem array,
+// so large files never have to be held in memory as a whole.
+func parseVtt(r io.Reader) ([]TranscriptItem, error) {
+	var transcriptItems []TranscriptItem
 
-	// Skip the header lines (usually first 4 lines including WEBVTT, empty line, etc.)
-	contentLines := lines[4:]
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxVttLineBytes)
 
 	// Process the content lines
 	var currentText strings.Builder
 	var startTime float64
 	var endTime float64
 
-	for i := 0; i < len(contentLines); i++ {
-		line := contentLines[i]
+	flush := func() {
+		if currentText.Len() > 0 {
+			text := cleanTranscriptText(currentText.String())
+			if text != "" {
+				transcriptItems = append(transcriptItems, TranscriptItem{
+					Text:     text,
+					Start:    startTime,
+					Duration: endTime - startTime,
+				})
+			}
+			currentText.Reset()
+		}
+	}
+
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		// Check if it has at least a basic VTT structure, then skip the header lines
+		// (usually first 4 lines including WEBVTT, empty line, etc.)
+		if lineNumber == 0 && !strings.Contains(line, "WEBVTT") {
+			return nil, nil
+		}
+		if lineNumber < 4 {
+			continue
+		}
 
 		// Process timestamp lines
 		if strings.Contains(line, "-->") {
 			// If we have collected text from previous timestamps, save it
-			if currentText.Len() > 0 {
-				text := cleanTranscriptText(currentText.String())
-				if text != "" {
-					transcriptItems = append(transcriptItems, TranscriptItem{
-						Text:     text,
-						Start:    startTime,
-						Duration: endTime - startTime,
-					})
-				}
-				currentText.Reset()
-			}
+			flush()
 
 			// Parse new timestamps
 			timestamps := strings.Split(line, "-->")
 			if len(timestamps) == 2 {
 				startTime = parseVttTimestamp(strings.TrimSpace(timestamps[0]))
-				endTime = parseVttTimestamp(strings.TrimSpace(timestamps[1]))
+				// Auto-captions put cue settings such as "align:start position:0%" after the end time
+				if end := strings.Fields(timestamps[1]); len(end) > 0 {
+					endTime = parseVttTimestamp(end[0])
+				}
 			}
 			continue
 		}
@@ -299,20 +813,17 @@ func parseVttContent(vttContent string) []TranscriptItem {
 			currentText.WriteString(cleanedLine)
 		}
 	}
-
-	// Don't forget to add the last collected text if any
-	if currentText.Len() > 0 {
-		text := cleanTranscriptText(currentText.String())
-		if text != "" {
-			transcriptItems = append(transcriptItems, TranscriptItem{
-				Text:     text,
-				Start:    startTime,
-				Duration: endTime - startTime,
-			})
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: line longer than %d bytes", ErrSubtitleTooLarge, maxVttLineBytes)
 		}
+		return nil, err
 	}
 
-	return mergeConsecutiveTranscriptItems(transcriptItems)
+	// Don't forget to add the last collected text if any
+	flush()
+
+	return MergeConsecutiveTranscriptItems(transcriptItems), nil
 }
 
 // cleanVttLine removes timestamp tags and other artifacts from VTT lines
@@ -350,6 +861,31 @@ func parseVttTimestamp(timestamp string) float64 {
 	return float64(hours*3600+minutes*60+seconds) + float64(milliseconds)/1000
 }
 
+// TranscriptCoverage returns the percentage (0-100) of a video's duration covered by transcript items.
+// Overlapping items are only counted once. It returns 0 if the video duration is unknown.
+func TranscriptCoverage(items []TranscriptItem, videoDuration int) float64 {
+	if videoDuration <= 0 || len(items) == 0 {
+		return 0
+	}
+
+	sorted := append([]TranscriptItem{}, items...)
+	SortTranscriptItemsByTime(sorted)
+
+	total := float64(videoDuration)
+	covered := 0.0
+	coveredUntil := 0.0
+	for _, item := range sorted {
+		start := math.Max(item.Start, coveredUntil)
+		end := math.Min(item.Start+item.Duration, total)
+		if end > start {
+			covered += end - start
+			coveredUntil = end
+		}
+	}
+
+	return math.Round(covered/total*1000) / 10
+}
+
 // SortTranscriptItemsByTime sorts the transcript items by their start time
 // This function is exported to be used by other packages
 func SortTranscriptItemsByTime(items []TranscriptItem) {
@@ -358,6 +894,23 @@ func SortTranscriptItemsByTime(items []TranscriptItem) {
 	})
 }
 
+// TranscriptHash returns a content hash of a transcript's text, used to recognize re-uploads and mirrors
+// of a video under another ID. Timing is ignored and whitespace and case are normalized, so the same
+// captions hash equally even if they were split into cues slightly differently.
+func TranscriptHash(items []TranscriptItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, item := range items {
+		for _, word := range strings.Fields(strings.ToLower(item.Text)) {
+			hash.Write([]byte(word))
+			hash.Write([]byte{' '})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 // cleanTranscriptText removes common artifacts from subtitle text
 func cleanTranscriptText(text string) string {
 	// Skip if empty
@@ -380,68 +933,53 @@ func cleanTranscriptText(text string) string {
 	return strings.TrimSpace(text)
 }
 
-func mergeConsecutiveTranscriptItems(items []TranscriptItem) []TranscriptItem {
+// minRollingOverlapWords is the fewest words a cue has to repeat from the end of the previous cue to be
+// treated as rolling text, unless it repeats the whole previous cue. It keeps a common word such as "the"
+// that merely ends one cue and starts the next from being dropped.
+const minRollingOverlapWords = 2
+
+// MergeConsecutiveTranscriptItems removes the text that consecutive items repeat from each other.
+// YouTube auto-captions roll: every cue repeats the previous line before adding new words, and a
+// short cue between them repeats the line once more. An item that starts with the end of the previous
+// item keeps only its new words; an item that adds nothing only extends the previous item's duration,
+// so the timings still cover the whole video.
+func MergeConsecutiveTranscriptItems(items []TranscriptItem) []TranscriptItem {
 	var result []TranscriptItem
+	var previousWords []string // Words of the previous item as it appeared in the captions, before merging
 
-	for _, e := range items {
-		n := len(result)
-		if n > 0 {
-			prev := result[n-1]
-
-			// 1) 동일 타임스탬프 처리
-			if int(e.Start) == int(prev.Start) {
-				// (1-1) 완전 중복
-				if e.Text == prev.Text {
-					continue
-					// (1-2) 뒷텍스트가 앞텍스트를 접두어로 포함
-				} else if strings.HasPrefix(e.Text, prev.Text) {
-					remainder := strings.TrimPrefix(e.Text, prev.Text)
-
-					// Remove leading period or comma if present
-					// remainder = strings.TrimPrefix(remainder, ".")
-					// remainder = strings.TrimPrefix(remainder, ",")
-					// remainder = strings.TrimSpace(remainder)
-
-					// 빈 문자열이 아니면 그 나머지를 남김
-					if remainder != "" {
-						result[n-1] = TranscriptItem{remainder, prev.Start, prev.Duration}
-					} else {
-						// 접두어 제거 뒤 빈 문자열이면
-						// 단순 교체해 둠(중복 처리와 같음)
-						result[n-1] = e
-					}
-				} else {
-					// 그 외엔 뒤 항목으로 교체
-					result[n-1] = e
-				}
-				continue
-			}
-
-			// 2) 앞 타임스탬프가 더 앞이고 내용 포함된 경우
-			if int(prev.Start) < int(e.Start) && strings.Contains(e.Text, prev.Text) {
-				remainder := strings.Replace(e.Text, prev.Text, "", 1)
-
-				// Remove leading period or comma if present
-				// remainder = strings.TrimPrefix(remainder, ".")
-				// remainder = strings.TrimPrefix(remainder, ",")
-				// remainder = strings.TrimSpace(remainder)
-
-				if remainder != "" {
-					result = append(result, TranscriptItem{remainder, e.Start, e.Duration})
-				}
-				// remainder가 빈 문자열이면 완전 중복처럼 간주하고 skip
-				continue
-			}
-
-			// 3) 타임스탬프 다르지만 텍스트가 완전 동일한 경우
-			if int(prev.Start) != int(e.Start) && e.Text == prev.Text {
-				continue
-			}
+	for _, item := range items {
+		words := strings.Fields(item.Text)
+		if len(words) == 0 {
+			continue
 		}
+		overlap := rollingOverlap(previousWords, words)
+		previousWords = words
 
-		// 위 모든 경우에 해당하지 않으면 있는 그대로 추가
-		result = append(result, e)
+		if n := len(result); n > 0 && overlap == len(words) {
+			// Nothing new; the previous item now lasts until this one ends
+			last := &result[n-1]
+			last.Duration = max(last.Duration, item.Start+item.Duration-last.Start)
+			continue
+		}
+		if overlap > 0 {
+			item.Text = strings.Join(words[overlap:], " ")
+		}
+		result = append(result, item)
 	}
 
 	return result
 }
+
+// rollingOverlap returns how many leading words of current repeat the end of previous.
+// All of current's words overlap when it is the previous item's trailing line shown once more.
+func rollingOverlap(previous, current []string) int {
+	for n := min(len(previous), len(current)); n > 0; n-- {
+		if n < minRollingOverlapWords && n < len(previous) {
+			break
+		}
+		if slices.Equal(previous[len(previous)-n:], current[:n]) {
+			return n
+		}
+	}
+	return 0
+}
@@ -1,7 +1,13 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,9 +63,405 @@ AI 를 사용하지 않을 때 보다
 
 	// Call the function
 	chunkSize := 10.0
-	chunks, err := processSubtitleFiles(tempDir, chunkSize)
+	chunks, _, err := processSubtitleFiles(tempDir, chunkSize, []string{"ko"})
 
 	// Assertions
 	assert.NoError(t, err)
 	assert.Len(t, chunks, 2)
 }
+
+// chunkStarts returns the start time of each chunk's items
+func chunkStarts(chunks [][]TranscriptItem) [][]float64 {
+	starts := make([][]float64, len(chunks))
+	for i, chunk := range chunks {
+		for _, item := range chunk {
+			starts[i] = append(starts[i], item.Start)
+		}
+	}
+	return starts
+}
+
+func TestProcessSubtitleFilesChunkBoundaries(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticVtt(t, filepath.Join(dir, "video.en.vtt"), 25) // One cue per second, 0s to 24s
+
+	chunks, _, err := processSubtitleFiles(dir, 10, []string{"en"})
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 3)
+	starts := chunkStarts(chunks)
+	assert.Equal(t, []float64{0, 9}, []float64{starts[0][0], starts[0][len(starts[0])-1]})
+	assert.Equal(t, []float64{10, 19}, []float64{starts[1][0], starts[1][len(starts[1])-1]})
+	assert.Equal(t, []float64{20, 24}, []float64{starts[2][0], starts[2][len(starts[2])-1]})
+	assert.Len(t, MergeTranscriptChunks(chunks), 25)
+}
+
+func TestProcessSubtitleFilesCarriesOverlapIntoNextChunk(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticVtt(t, filepath.Join(dir, "video.en.vtt"), 25)
+	t.Setenv("TRANSCRIPT_CHUNK_OVERLAP_SECONDS", "3")
+
+	chunks, _, err := processSubtitleFiles(dir, 10, []string{"en"})
+	assert.NoError(t, err)
+	starts := chunkStarts(chunks)
+	// The boundaries stay at 10s and 20s; each chunk repeats the previous chunk's last 3 seconds
+	assert.Len(t, starts, 3)
+	assert.Equal(t, []float64{7, 8, 9, 10}, starts[1][:4])
+	assert.Equal(t, 19.0, starts[1][len(starts[1])-1])
+	assert.Equal(t, []float64{17, 18, 19, 20}, starts[2][:4])
+	assert.Equal(t, 24.0, starts[2][len(starts[2])-1])
+
+	// Merging the chunks drops the repeated items again
+	merged := MergeTranscriptChunks(chunks)
+	assert.Len(t, merged, 25)
+	for i, item := range merged {
+		assert.Equal(t, float64(i), item.Start)
+	}
+}
+
+func TestChunkOverlapMustBeShorterThanChunk(t *testing.T) {
+	t.Setenv("TRANSCRIPT_CHUNK_OVERLAP_SECONDS", "10")
+	assert.Equal(t, 0.0, transcriptChunkOverlap(10))
+	assert.Equal(t, 10.0, transcriptChunkOverlap(30))
+	assert.Equal(t, 0.0, transcriptChunkOverlap(0))
+}
+
+// rollingCaptionsVtt is shaped like YouTube auto-captions: each cue repeats the previous line with
+// word timing tags on the new words, and a 10ms cue in between shows the finished line again
+const rollingCaptionsVtt = `WEBVTT
+Kind: captions
+Language: en
+
+00:00:00.000 --> 00:00:02.879 align:start position:0%
+ 
+hello<00:00:00.480><c> everyone</c><00:00:00.960><c> welcome</c>
+
+00:00:02.879 --> 00:00:02.889 align:start position:0%
+hello everyone welcome
+ 
+
+00:00:02.889 --> 00:00:05.120 align:start position:0%
+hello everyone welcome
+to<00:00:03.200><c> the</c><00:00:03.600><c> channel</c>
+
+00:00:05.120 --> 00:00:05.130 align:start position:0%
+to the channel
+ 
+
+00:00:05.130 --> 00:00:08.000 align:start position:0%
+to the channel
+today<00:00:05.500><c> we</c><00:00:05.900><c> are</c><00:00:06.300><c> cooking</c><00:00:06.700><c> pasta</c>
+`
+
+func TestParseVttMergesRollingCaptions(t *testing.T) {
+	items := parseVttContent(rollingCaptionsVtt)
+
+	assert.Equal(t, []string{"hello everyone welcome", "to the channel", "today we are cooking pasta"}, transcriptTexts(items))
+	// Each item starts when its words first appear and lasts until the next one starts
+	assert.InDelta(t, 0.0, items[0].Start, 0.001)
+	assert.InDelta(t, 2.889, items[0].Duration, 0.001)
+	assert.InDelta(t, 2.889, items[1].Start, 0.001)
+	assert.InDelta(t, 5.130, items[1].Start+items[1].Duration, 0.001)
+	assert.InDelta(t, 5.130, items[2].Start, 0.001)
+	assert.InDelta(t, 8.0, items[2].Start+items[2].Duration, 0.001)
+
+	// The captions repeat every word two or three times; the summarizer only gets each word once
+	rawWords := 0
+	for _, line := range strings.Split(rollingCaptionsVtt, "\n")[4:] { // After the header
+		if !strings.Contains(line, "-->") {
+			rawWords += len(strings.Fields(cleanVttLine(line)))
+		}
+	}
+	mergedWords := 0
+	for _, item := range items {
+		mergedWords += len(strings.Fields(item.Text))
+	}
+	assert.Equal(t, 23, rawWords)
+	assert.Equal(t, 11, mergedWords)
+}
+
+func TestMergeConsecutiveTranscriptItemsKeepsDistinctLines(t *testing.T) {
+	items := MergeConsecutiveTranscriptItems([]TranscriptItem{
+		{Text: "do you agree with that", Start: 0, Duration: 2},
+		{Text: "yes", Start: 2, Duration: 1}, // Part of nothing before it
+		{Text: "that is one of the", Start: 3, Duration: 2},
+		{Text: "the best ideas", Start: 5, Duration: 2}, // One shared word is a coincidence, not rolling text
+		{Text: "the best ideas", Start: 7, Duration: 1}, // Exact repeat
+	})
+
+	assert.Equal(t, []string{"do you agree with that", "yes", "that is one of the", "the best ideas"}, transcriptTexts(items))
+	assert.Equal(t, 3.0, items[3].Duration)
+}
+
+// transcriptTexts returns the text of each item
+func transcriptTexts(items []TranscriptItem) []string {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Text
+	}
+	return texts
+}
+
+func TestProcessSubtitleFilesWithoutSubtitlesReturnsErrNoTranscript(t *testing.T) {
+	_, _, err := processSubtitleFiles(t.TempDir(), 10, []string{"ko"})
+	assert.ErrorIs(t, err, ErrNoTranscript)
+
+	// A subtitle file without cues counts as no transcript too
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "video.ko.vtt"), []byte("WEBVTT\n\n"), 0644))
+	_, _, err = processSubtitleFiles(dir, 10, []string{"ko"})
+	assert.ErrorIs(t, err, ErrNoTranscript)
+}
+
+func TestGetVideoIDFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"watch", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"watch with params first", "https://www.youtube.com/watch?feature=share&v=dQw4w9WgXcQ"},
+		{"mobile", "https://m.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"short link", "https://youtu.be/dQw4w9WgXcQ?si=abc"},
+		{"shorts", "https://www.youtube.com/shorts/dQw4w9WgXcQ"},
+		{"live", "https://www.youtube.com/live/dQw4w9WgXcQ?feature=share"},
+		{"embed", "https://www.youtube.com/embed/dQw4w9WgXcQ"},
+		{"watch with share suffix", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&si=AbCdEfGh123"},
+		{"watch with extra params", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123&index=2&t=42s"},
+		{"mobile shorts with share suffix", "https://m.youtube.com/shorts/dQw4w9WgXcQ?si=AbCdEfGh123"},
+		{"live with share suffix", "https://youtube.com/live/dQw4w9WgXcQ?si=AbCdEfGh123"},
+		{"short link with start time", "https://youtu.be/dQw4w9WgXcQ?t=30&si=abc"},
+		{"short link with fragment", "https://youtu.be/dQw4w9WgXcQ#t=30"},
+		{"no scheme", "youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"music", "https://music.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"music with params", "https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RDAMVM&feature=share"},
+		{"shorts with trailing slash", "https://www.youtube.com/shorts/dQw4w9WgXcQ/"},
+		{"mobile shorts", "https://m.youtube.com/shorts/dQw4w9WgXcQ"},
+		{"live with trailing slash", "https://www.youtube.com/live/dQw4w9WgXcQ/"},
+		{"mobile live", "https://m.youtube.com/live/dQw4w9WgXcQ?feature=share"},
+		{"upper-case host", "HTTPS://WWW.YOUTUBE.COM/watch?v=dQw4w9WgXcQ"},
+		{"host with port", "https://www.youtube.com:443/watch?v=dQw4w9WgXcQ"},
+		{"http", "http://youtu.be/dQw4w9WgXcQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videoID, err := GetVideoID(tt.url)
+			assert.NoError(t, err)
+			assert.Equal(t, "dQw4w9WgXcQ", videoID)
+			assert.Equal(t, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", CanonicalVideoURL(videoID))
+		})
+	}
+}
+
+func TestMergeSubtitleTracks(t *testing.T) {
+	// Manual track only covers the first 30 seconds
+	var manual []TranscriptItem
+	for start := 0.0; start < 30; start += 5 {
+		manual = append(manual, TranscriptItem{Text: "manual", Start: start, Duration: 5})
+	}
+
+	// Auto track covers the whole minute
+	var auto []TranscriptItem
+	for start := 0.0; start < 60; start += 5 {
+		auto = append(auto, TranscriptItem{Text: "auto", Start: start, Duration: 5})
+	}
+
+	merged := mergeSubtitleTracks(manual, auto)
+
+	assert.Len(t, merged, 12)
+	for _, item := range merged {
+		if item.Start < 30 {
+			assert.Equal(t, "manual", item.Text, "manual track should be used at %.0fs", item.Start)
+		} else {
+			assert.Equal(t, "auto", item.Text, "auto track should fill the gap at %.0fs", item.Start)
+		}
+	}
+
+	assert.Equal(t, auto, mergeSubtitleTracks(nil, auto))
+	assert.Equal(t, manual, mergeSubtitleTracks(manual, nil))
+}
+
+func TestTranscriptCoverage(t *testing.T) {
+	items := []TranscriptItem{
+		{Text: "a", Start: 0, Duration: 10},
+		{Text: "b", Start: 5, Duration: 10}, // overlaps the first item
+		{Text: "c", Start: 50, Duration: 20},
+		{Text: "d", Start: 95, Duration: 10}, // runs past the end of the video
+	}
+
+	assert.Equal(t, 40.0, TranscriptCoverage(items, 100))
+	assert.Equal(t, 0.0, TranscriptCoverage(items, 0), "unknown duration")
+	assert.Equal(t, 0.0, TranscriptCoverage(nil, 100))
+}
+
+func TestNormalizeVideoID(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", "dQw4w9WgXcQ", "dQw4w9WgXcQ", false},
+		{"whitespace padded", "  dQw4w9WgXcQ\n", "dQw4w9WgXcQ", false},
+		{"case is preserved", "DQW4W9WGXCQ", "DQW4W9WGXCQ", false},
+		{"too short", "dQw4w9WgXc", "", true},
+		{"too long", "dQw4w9WgXcQQ", "", true},
+		{"invalid characters", "dQw4w9WgX.Q", "", true},
+		{"path traversal", "../../etc/x", "", true},
+		{"empty", "   ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeVideoID(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetVideoIDNormalizesConsistently(t *testing.T) {
+	// Whitespace around the pasted URL maps to the same key
+	videoID, err := GetVideoID("  https://youtu.be/dQw4w9WgXcQ \n")
+	assert.NoError(t, err)
+	assert.Equal(t, "dQw4w9WgXcQ", videoID)
+
+	// Malformed IDs are rejected instead of producing a key that can never match
+	for _, url := range []string{
+		"https://www.youtube.com/watch?v=short",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQextra",
+		"https://www.youtube.com/shorts/bad$id!!!!!",
+	} {
+		_, err := GetVideoID(url)
+		assert.Error(t, err, url)
+	}
+}
+
+func TestGetVideoIDReturnsInvalidVideoURLError(t *testing.T) {
+	tests := []struct {
+		url    string
+		reason string
+	}{
+		{"", "URL is empty"},
+		{"https://vimeo.com/123456", "not a YouTube video URL"},
+		{"https://www.youtube.com/channel/UC123", "not a YouTube video URL"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQextra", `video ID "dQw4w9WgXcQextra" must be 11`},
+		{"https://youtu.be/short?si=abc", `video ID "short" must be 11`},
+		// YouTube paths on other hosts
+		{"https://evil.example.com/youtube.com/watch?v=dQw4w9WgXcQ", `host "evil.example.com" is not youtube.com or youtu.be`},
+		{"https://evil.example.com/?next=youtu.be/dQw4w9WgXcQ", `host "evil.example.com" is not`},
+		{"https://youtube.com.evil.example.com/watch?v=dQw4w9WgXcQ", `host "youtube.com.evil.example.com" is not`},
+		{"https://www.youtube.com@evil.example.com/watch?v=dQw4w9WgXcQ", "credentials"},
+		{"evil.example.com/watch?v=dQw4w9WgXcQ", `host "evil.example.com" is not`},
+		{"javascript://www.youtube.com/watch?v=dQw4w9WgXcQ", `scheme "javascript" is not http or https`},
+		{"ftp://youtu.be/dQw4w9WgXcQ", `scheme "ftp"`},
+	}
+	for _, tt := range tests {
+		_, err := GetVideoID(tt.url)
+		var urlErr *InvalidVideoURLError
+		if assert.ErrorAs(t, err, &urlErr, tt.url) {
+			assert.Contains(t, urlErr.Reason, tt.reason)
+			assert.Equal(t, "invalid YouTube URL: "+urlErr.Reason, err.Error())
+		}
+	}
+}
+
+// writeSyntheticVtt writes a VTT file with the given number of cues
+func writeSyntheticVtt(t *testing.T, path string, cues int) int64 {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("WEBVTT\nKind: captions\nLanguage: en\n\n")
+	for i := 0; i < cues; i++ {
+		fmt.Fprintf(&b, "00:%02d:%02d.000 --> 00:%02d:%02d.500\ncue number %d\n\n", i/60%60, i%60, i/60%60, i%60, i)
+	}
+	assert.NoError(t, os.WriteFile(path, []byte(b.String()), 0644))
+	return int64(b.Len())
+}
+
+func TestReadSubtitleItemsEnforcesMaxSubtitleBytes(t *testing.T) {
+	dir := t.TempDir()
+	size := writeSyntheticVtt(t, filepath.Join(dir, "video.en.vtt"), 2000)
+
+	t.Setenv("MAX_SUBTITLE_BYTES", fmt.Sprint(size-1))
+	_, _, err := readSubtitleItems(dir, []string{"en"})
+	assert.True(t, errors.Is(err, ErrSubtitleTooLarge), "got %v", err)
+
+	t.Setenv("MAX_SUBTITLE_BYTES", fmt.Sprint(size))
+	items, _, err := readSubtitleItems(dir, []string{"en"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, items)
+}
+
+func TestReadSubtitleItemsPicksPreferredLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticVtt(t, filepath.Join(dir, "video.en-US.vtt"), 3)
+	writeSyntheticVtt(t, filepath.Join(dir, "video.ja.vtt"), 5)
+	writeSyntheticVtt(t, filepath.Join(dir, "video.ko.vtt"), 7)
+
+	tests := []struct {
+		langs    []string
+		wantLang string
+		wantLen  int
+	}{
+		{[]string{"ko", "en"}, "ko", 7},
+		{[]string{"en", "ko"}, "en-US", 3}, // Regional variant of the first preference
+		{[]string{"fr", "ja"}, "ja", 5},
+		{[]string{"fr"}, "en-US", 3}, // Nothing matches: first file in name order
+	}
+	for _, tt := range tests {
+		items, lang, err := readSubtitleItems(dir, tt.langs)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantLang, lang, "langs %v", tt.langs)
+		assert.Len(t, items, tt.wantLen, "captions of other languages must not be merged in")
+	}
+}
+
+func TestTranscriptLanguages(t *testing.T) {
+	t.Setenv("TRANSCRIPT_LANGS", "")
+	assert.Equal(t, []string{"ko"}, TranscriptLanguages())
+
+	t.Setenv("TRANSCRIPT_LANGS", " en, ko ,,ja")
+	assert.Equal(t, []string{"en", "ko", "ja"}, TranscriptLanguages())
+}
+
+func TestParseVttRejectsOverlongLines(t *testing.T) {
+	content := "WEBVTT\nKind: captions\nLanguage: en\n\n00:00:00.000 --> 00:00:01.000\n" + strings.Repeat("a", maxVttLineBytes+1) + "\n"
+	_, err := parseVtt(strings.NewReader(content))
+	assert.True(t, errors.Is(err, ErrSubtitleTooLarge), "got %v", err)
+}
+
+func TestGetTranscriptFallsBackToOriginalLanguageCaptions(t *testing.T) {
+	t.Setenv("TRANSCRIPT_LANGS", "en,ko")
+	t.Setenv("MERGE_SUBTITLE_TRACKS", "false")
+
+	var requested []string
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		var dir, langs string
+		for i, arg := range cmd.Args {
+			switch arg {
+			case "--paths":
+				dir = cmd.Args[i+1]
+			case "--sub-langs":
+				langs = cmd.Args[i+1]
+			}
+		}
+		requested = append(requested, langs)
+		if langs == originalAutoCaptionLangs {
+			// The video only has German auto captions
+			writeSyntheticVtt(t, filepath.Join(dir, "aaaaaaaaaaa.de-orig.vtt"), 4)
+		}
+		return nil
+	}
+	t.Cleanup(func() { runCommand = original })
+
+	chunks, err := GetTranscript(context.Background(), "aaaaaaaaaaa", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en,ko", originalAutoCaptionLangs}, requested)
+	if assert.Len(t, chunks, 1) {
+		assert.Len(t, chunks[0], 4)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// YtDlpExtractorArgs returns the value configured via YTDLP_EXTRACTOR_ARGS
+// (e.g. "youtube:player_client=android"), or an empty string if none is set
+func YtDlpExtractorArgs() string {
+	return strings.TrimSpace(os.Getenv("YTDLP_EXTRACTOR_ARGS"))
+}
+
+// YtDlpProxy returns the proxy URL configured via YTDLP_PROXY, or an empty string if none is set
+func YtDlpProxy() string {
+	return strings.TrimSpace(os.Getenv("YTDLP_PROXY"))
+}
+
+// YtDlpCookiesFile returns the Netscape-format cookies file configured via YTDLP_COOKIES_FILE,
+// or an empty string if none is set
+func YtDlpCookiesFile() string {
+	return strings.TrimSpace(os.Getenv("YTDLP_COOKIES_FILE"))
+}
+
+// ValidateYtDlpOptions checks the yt-dlp options at startup, so a mistyped cookies path fails
+// loudly instead of making every age-restricted video fail later
+func ValidateYtDlpOptions() error {
+	cookiesFile := YtDlpCookiesFile()
+	if cookiesFile == "" {
+		return nil
+	}
+	info, err := os.Stat(cookiesFile)
+	if err != nil {
+		return fmt.Errorf("YTDLP_COOKIES_FILE: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("YTDLP_COOKIES_FILE: %s is not a regular file", cookiesFile)
+	}
+	return nil
+}
+
+// ytDlpOptionArgs returns the operator-configured options passed to every yt-dlp invocation
+func ytDlpOptionArgs() []string {
+	var args []string
+	if extractorArgs := YtDlpExtractorArgs(); extractorArgs != "" {
+		// Lets operators switch the player client when YouTube changes break the default one
+		args = append(args, "--extractor-args", extractorArgs)
+	}
+	// Age-restricted and region-locked videos need a logged-in session or another exit country
+	if proxy := YtDlpProxy(); proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	if cookiesFile := YtDlpCookiesFile(); cookiesFile != "" {
+		args = append(args, "--cookies", cookiesFile)
+	}
+	return args
+}
+
+// defaultYtDlpTimeout bounds a yt-dlp run when YTDLP_TIMEOUT_SECONDS is not set
+const defaultYtDlpTimeout = 120 * time.Second
+
+// ytDlpWaitDelay is how long output pipes may stay open after yt-dlp was killed, in case it left child processes behind
+const ytDlpWaitDelay = 5 * time.Second
+
+// ErrYtDlpTimeout is returned when yt-dlp was killed for running longer than YTDLP_TIMEOUT_SECONDS
+var ErrYtDlpTimeout = errors.New("yt-dlp timed out")
+
+// ytDlpTimeout returns the time limit of a yt-dlp run, configured via YTDLP_TIMEOUT_SECONDS
+func ytDlpTimeout() time.Duration {
+	if seconds := GetEnvInt("YTDLP_TIMEOUT_SECONDS", 0); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultYtDlpTimeout
+}
+
+// ErrBotCheck is returned when YouTube refused a yt-dlp request with a bot check ("Sign in to confirm
+// you're not a bot") or rate limiting. It is usually transient.
+var ErrBotCheck = errors.New("YouTube requires a bot check")
+
+// botCheckPatterns are lowercase yt-dlp stderr fragments that mean YouTube is blocking the server for now
+var botCheckPatterns = []string{
+	"confirm you're not a bot",
+	"http error 429",
+	"too many requests",
+}
+
+// IsBotCheckOutput reports whether yt-dlp's stderr output says YouTube answered with a bot check or rate limit
+func IsBotCheckOutput(stderr string) bool {
+	// yt-dlp prints YouTube's message with a typographic apostrophe
+	stderr = strings.ToLower(strings.ReplaceAll(stderr, "\u2019", "'"))
+	for _, pattern := range botCheckPatterns {
+		if strings.Contains(stderr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// runYtDlp runs yt-dlp with args and kills it when ctx is canceled or YTDLP_TIMEOUT_SECONDS passes,
+// so a hung download can't block a worker forever. A run that exceeded the time limit returns an
+// error wrapping ErrYtDlpTimeout; a canceled run returns ctx's error. A run YouTube refused with a
+// bot check returns an error wrapping ErrBotCheck.
+func runYtDlp(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	timeout := ytDlpTimeout()
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var errOutput bytes.Buffer
+	cmd := exec.CommandContext(runCtx, "yt-dlp", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = &errOutput
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderr, &errOutput)
+	}
+	cmd.WaitDelay = ytDlpWaitDelay
+
+	err := runCommand(cmd)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrYtDlpTimeout, timeout)
+	}
+	if IsBotCheckOutput(errOutput.String()) {
+		return fmt.Errorf("%w: %v", ErrBotCheck, err)
+	}
+	return err
+}
+
+// tokenBucket is a rate limiter that hands out up to burst tokens at once and refills at rate tokens
+// per second. Waiters reserve a token up front, so they are served in the order they arrive.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64 // May go negative while waiters hold reservations
+	last   time.Time
+}
+
+func newTokenBucket(perMinute, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(perMinute) / 60,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens-- // Reserve a token
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++ // Give the reservation back
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+var (
+	ytDlpLimiterMutex sync.Mutex
+	ytDlpLimiter      *tokenBucket
+	ytDlpLimiterRate  [2]int // YTDLP_RATE_PER_MINUTE and YTDLP_RATE_BURST ytDlpLimiter was created with
+)
+
+// waitForYtDlp blocks until a yt-dlp invocation fits the global YTDLP_RATE_PER_MINUTE rate, allowing
+// bursts of YTDLP_RATE_BURST calls. This is separate from any concurrency cap: YouTube throttles on
+// request rate. Unlimited when YTDLP_RATE_PER_MINUTE is unset. Gives up when ctx is done.
+func waitForYtDlp(ctx context.Context) error {
+	perMinute := GetEnvInt("YTDLP_RATE_PER_MINUTE", 0)
+	if perMinute <= 0 {
+		return nil
+	}
+	burst := GetEnvInt("YTDLP_RATE_BURST", 1)
+	if burst <= 0 {
+		burst = 1
+	}
+
+	ytDlpLimiterMutex.Lock()
+	if ytDlpLimiter == nil || ytDlpLimiterRate != [2]int{perMinute, burst} {
+		ytDlpLimiter = newTokenBucket(perMinute, burst)
+		ytDlpLimiterRate = [2]int{perMinute, burst}
+	}
+	limiter := ytDlpLimiter
+	ytDlpLimiterMutex.Unlock()
+
+	if err := limiter.wait(ctx); err != nil {
+		return fmt.Errorf("gave up waiting for the yt-dlp rate limit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVideoInfoPassesExtractorArgs(t *testing.T) {
+	var gotArgs []string
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) error {
+		gotArgs = cmd.Args
+		_, err := cmd.Stdout.Write([]byte(`{"title": "Video"}`))
+		return err
+	}
+	t.Cleanup(func() { runCommand = original })
+
+	t.Setenv("YTDLP_EXTRACTOR_ARGS", "youtube:player_client=android")
+	_, err := GetVideoInfo(context.Background(), "ddddddddddd")
+	assert.NoError(t, err)
+	assert.Contains(t, gotArgs, "--extractor-args")
+	assert.Contains(t, gotArgs, "youtube:player_client=android")
+
+	t.Setenv("YTDLP_EXTRACTOR_ARGS", "")
+	_, err = GetVideoInfo(context.Background(), "ddddddddddd")
+	assert.NoError(t, err)
+	assert.NotContains(t, gotArgs, "--extractor-args")
+}
+
+func TestGetVideoInfoListsCaptionTracks(t *testing.T) {
+	stubRunCommand(t, `{
+		"title": "Video",
+		"subtitles": {
+			"ko": [{"ext": "vtt", "name": "Korean"}],
+			"en": [{"ext": "vtt", "name": "English"}],
+			"live_chat": [{"ext": "json"}]
+		},
+		"automatic_captions": {
+			"ja": [{"ext": "vtt", "name": "Japanese"}]
+		}
+	}`)
+
+	info, err := GetVideoInfo(context.Background(), "eeeeeeeeeee")
+	assert.NoError(t, err)
+	assert.Equal(t, []CaptionTrack{
+		{Language: "en", Name: "English", Auto: false},
+		{Language: "ko", Name: "Korean", Auto: false},
+		{Language: "ja", Name: "Japanese", Auto: true},
+	}, info.Captions)
+}
+
+func TestYtDlpRateLimitIsRespected(t *testing.T) {
+	calls := stubRunCommand(t, `{"title": "Video"}`)
+	t.Setenv("VIDEOINFO_CACHE_TTL_SECONDS", "0")
+	t.Setenv("YTDLP_RATE_PER_MINUTE", "600") // One call every 100ms
+	t.Setenv("YTDLP_RATE_BURST", "1")
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := GetVideoInfo(context.Background(), "ddddddddddd")
+		assert.NoError(t, err)
+	}
+	elapsed := time.Since(start)
+	assert.Equal(t, 4, *calls)
+	assert.GreaterOrEqual(t, elapsed, 280*time.Millisecond, "the first call uses the burst token, the other three wait 100ms each")
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestYtDlpRateLimitWaitEndsWithContext(t *testing.T) {
+	calls := stubRunCommand(t, `{"title": "Video"}`)
+	t.Setenv("YTDLP_RATE_PER_MINUTE", "1")
+	t.Setenv("YTDLP_RATE_BURST", "1")
+
+	_, err := GetVideoInfo(context.Background(), "ddddddddddd")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = GetVideoInfo(ctx, "ddddddddddd")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, *calls, "yt-dlp must not run after giving up on the rate limit")
+}
+
+// sleepingYtDlp puts a yt-dlp on PATH that hangs like one stuck behind a captcha wall
+func sleepingYtDlp(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "yt-dlp"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGetVideoInfoKillsHungYtDlp(t *testing.T) {
+	sleepingYtDlp(t)
+	t.Setenv("YTDLP_TIMEOUT_SECONDS", "1")
+
+	start := time.Now()
+	_, err := GetVideoInfo(context.Background(), "ddddddddddd")
+	assert.True(t, errors.Is(err, ErrYtDlpTimeout), "got %v", err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestGetTranscriptStopsWhenContextIsCanceled(t *testing.T) {
+	sleepingYtDlp(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := GetTranscript(ctx, "ddddddddddd", 0)
+	assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+	assert.False(t, errors.Is(err, ErrYtDlpTimeout))
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestYtDlpOptionArgsProxyAndCookies(t *testing.T) {
+	t.Setenv("YTDLP_EXTRACTOR_ARGS", "")
+	t.Setenv("YTDLP_PROXY", "")
+	t.Setenv("YTDLP_COOKIES_FILE", "")
+	assert.Empty(t, ytDlpOptionArgs())
+	assert.NoError(t, ValidateYtDlpOptions())
+
+	cookiesFile := filepath.Join(t.TempDir(), "cookies.txt")
+	t.Setenv("YTDLP_PROXY", " socks5://127.0.0.1:1080 ")
+	t.Setenv("YTDLP_COOKIES_FILE", cookiesFile)
+	assert.Equal(t, []string{"--proxy", "socks5://127.0.0.1:1080", "--cookies", cookiesFile}, ytDlpOptionArgs())
+	assert.Error(t, ValidateYtDlpOptions(), "the cookies file doesn't exist")
+	assert.NoError(t, os.WriteFile(cookiesFile, []byte("# Netscape HTTP Cookie File\n"), 0600))
+	assert.NoError(t, ValidateYtDlpOptions())
+
+	t.Setenv("YTDLP_COOKIES_FILE", t.TempDir())
+	assert.Error(t, ValidateYtDlpOptions(), "a directory is not a cookies file")
+}
+
+// botCheckStderr is what yt-dlp prints when YouTube refuses the server with a bot check
+const botCheckStderr = "ERROR: [youtube] dQw4w9WgXcQ: Sign in to confirm you’re not a bot. Use --cookies-from-browser or --cookies for the authentication. See  https://github.com/yt-dlp/yt-dlp/wiki/FAQ#how-do-i-pass-cookies-to-yt-dlp  for how to manually pass cookies. Also see  https://github.com/yt-dlp/yt-dlp/wiki/Extractors#exporting-youtube-cookies  for tips on effectively exporting YouTube cookies\n"
+
+func TestIsBotCheckOutput(t *testing.T) {
+	assert.True(t, IsBotCheckOutput(botCheckStderr))
+	assert.True(t, IsBotCheckOutput("ERROR: [youtube] dQw4w9WgXcQ: Sign in to confirm you're not a bot."))
+	assert.True(t, IsBotCheckOutput("ERROR: Unable to download webpage: HTTP Error 429: Too Many Requests"))
+	assert.False(t, IsBotCheckOutput("ERROR: [youtube] dQw4w9WgXcQ: Video unavailable"))
+	assert.False(t, IsBotCheckOutput(""))
+}
+
+func TestGetVideoInfoReturnsErrBotCheck(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >&2 <<'EOF'\n" + botCheckStderr + "EOF\nexit 1\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "yt-dlp"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	_, err := GetVideoInfo(context.Background(), "ddddddddddd")
+	assert.True(t, errors.Is(err, ErrBotCheck), "got %v", err)
+}
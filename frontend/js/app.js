@@ -425,7 +425,8 @@ function handleLogout() {
     // 로그아웃 API 호출 - POST 메서드 사용
     fetch('/auth/logout', { 
         method: 'POST',
-        credentials: 'include' // 중요: 쿠키 포함
+        credentials: 'include', // 중요: 쿠키 포함
+        headers: { 'X-Requested-With': 'XMLHttpRequest' } // 서버의 CSRF 확인용
     })
     .then(response => {
         if (!response.ok) {
@@ -912,6 +913,7 @@ function onPlayerStateChange(event) {
 // Show loading state
 function showLoading() {
     summaryElement.innerHTML = '';
+    setLoadingMessage('Generating summary...');
     loadingElement.classList.remove('hidden');
     // 요약 요청 시작 시 탭 컨테이너 숨김
     const tabsContainer = document.querySelector('.tabs-container');
@@ -920,6 +922,14 @@ function showLoading() {
     }
 }
 
+// Update the message below the loading spinner
+function setLoadingMessage(message) {
+    const messageElement = loadingElement.querySelector('p');
+    if (messageElement) {
+        messageElement.textContent = message;
+    }
+}
+
 // Hide loading state
 function hideLoading() {
     loadingElement.classList.add('hidden');
@@ -978,6 +988,16 @@ function fetchSummary(url) {
                     console.log('SSE connection established for summary updates.');
                 };
 
+                // 청크가 요약될 때마다 진행률 표시
+                summaryEventSource.addEventListener('summary_progress', (event) => {
+                    try {
+                        const progressData = JSON.parse(event.data);
+                        setLoadingMessage(`Generating summary... (${progressData.chunk}/${progressData.totalChunks})`);
+                    } catch (e) {
+                        console.error('Error parsing summary_progress data:', e);
+                    }
+                });
+
                 summaryEventSource.addEventListener('summary_complete', (event) => {
                     console.log('SSE summary_complete event received:', event.data);
                     try {
@@ -1001,7 +1021,8 @@ function fetchSummary(url) {
                     try {
                         const errorData = JSON.parse(event.data);
                         hideLoading();
-                        displayError({ message: errorData.error || 'An error occurred during summarization.' });
+                        // Known failures such as no_transcript carry a readable message next to the error code
+                        displayError({ message: errorData.message || errorData.error || 'An error occurred during summarization.' });
                     } catch (e) {
                         console.error('Error parsing summary_error data:', e);
                         hideLoading();
@@ -1031,7 +1052,9 @@ function fetchSummary(url) {
             } else { // Handle other errors from the initial POST request
                 const errorData = await response.json().catch(() => ({})); // Try to parse JSON, default to empty if fails
                 let errorMessage = `Server responded with status: ${response.status}`;
-                if (errorData.error) {
+                if (errorData.message) {
+                    errorMessage = errorData.message;
+                } else if (errorData.error) {
                     errorMessage = errorData.error;
                 } else if (response.statusText) {
                     errorMessage = response.statusText;
@@ -1338,8 +1361,8 @@ document.addEventListener('DOMContentLoaded', () => {
                 throw new Error("Failed to fetch recent summaries");
             }
             
-            const summaries = await response.json();
-            populateDropdown(summaries);
+            const data = await response.json();
+            populateDropdown(data.summaries);
         } catch (error) {
             console.error("Error fetching recent summaries:", error);
         }